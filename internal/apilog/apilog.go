@@ -0,0 +1,175 @@
+// Package apilog records the AWS requests awstui makes, through SDK request
+// handlers, for the API call inspector and the debug log.
+package apilog
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// DefaultLimit is how many calls a Recorder keeps by default.
+const DefaultLimit = 1000
+
+// Call is one completed AWS request, including its retries.
+type Call struct {
+	Time      time.Time
+	Service   string
+	Operation string
+	Region    string
+	Params    string
+	Latency   time.Duration
+	Retries   int
+	RequestID string
+	ErrorCode string
+	Error     string
+}
+
+// Recorder keeps the most recent calls made by the clients it is installed
+// on. Every call is also written to the default slog logger at debug level.
+type Recorder struct {
+	limit int
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecorder returns a recorder keeping the last limit calls.
+func NewRecorder(limit int) *Recorder {
+	return &Recorder{limit: limit}
+}
+
+// Install records every request made with h. Install it on a session before
+// creating service clients from it, since clients copy the session handlers.
+func (r *Recorder) Install(h *request.Handlers) {
+	h.Complete.PushBackNamed(request.NamedHandler{
+		Name: "awstui.apilog.Recorder",
+		Fn:   r.complete,
+	})
+}
+
+func (r *Recorder) complete(req *request.Request) {
+	c := Call{
+		Time:      req.Time,
+		Service:   req.ClientInfo.SigningName,
+		Operation: req.Operation.Name,
+		Region:    aws.StringValue(req.Config.Region),
+		Params:    Summarize(req.Params),
+		Latency:   time.Since(req.Time),
+		Retries:   req.RetryCount,
+		RequestID: req.RequestID,
+	}
+	if c.Service == "" {
+		c.Service = req.ClientInfo.ServiceName
+	}
+	if req.Error != nil {
+		c.Error = req.Error.Error()
+		if aerr, ok := req.Error.(awserr.Error); ok {
+			c.ErrorCode = aerr.Code()
+		}
+	}
+	r.Record(c)
+}
+
+// Record adds c to the recorder and the debug log.
+func (r *Recorder) Record(c Call) {
+	attrs := []any{
+		"service", c.Service,
+		"operation", c.Operation,
+		"region", c.Region,
+		"params", c.Params,
+		"latency", c.Latency.String(),
+		"retries", c.Retries,
+		"request_id", c.RequestID,
+	}
+	if c.Error != "" {
+		attrs = append(attrs, "error_code", c.ErrorCode, "error", c.Error)
+	}
+	slog.Debug("aws request", attrs...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, c)
+	if over := len(r.calls) - r.limit; over > 0 {
+		r.calls = append(r.calls[:0], r.calls[over:]...)
+	}
+}
+
+// Calls returns the recorded calls, oldest first.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// maxValue caps the length of each value in a summary, which keeps large
+// inputs such as execution payloads out of the inspector and the log.
+const maxValue = 40
+
+// Summarize renders the set fields of an SDK input on one line, e.g.
+// "Cluster=prod MaxResults=100 Services=[api worker]". Nested structures
+// are shown as {…} and long lists by their length.
+func Summarize(params any) string {
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	var fields []string
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if s, ok := summarizeValue(v.Field(i)); ok {
+			fields = append(fields, f.Name+"="+s)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// summarizeValue formats v, reporting false if it is not set.
+func summarizeValue(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return "", false
+		}
+		return summarizeValue(v.Elem())
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return "", false
+		}
+		if v.Kind() == reflect.Map || v.Len() > 3 {
+			return fmt.Sprintf("[%d]", v.Len()), true
+		}
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, _ := summarizeValue(v.Index(i))
+			items = append(items, s)
+		}
+		return "[" + strings.Join(items, " ") + "]", true
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.UTC().Format(time.RFC3339), true
+		}
+		return "{…}", true
+	}
+	s := fmt.Sprint(v.Interface())
+	if r := []rune(s); len(r) > maxValue {
+		s = string(r[:maxValue]) + "…"
+	}
+	return s, true
+}
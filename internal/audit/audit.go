@@ -0,0 +1,118 @@
+// Package audit keeps a local, append-only record of every action awstui
+// takes that changes AWS resources. Entries are stored one JSON object per
+// line.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Result values of an Entry.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Entry is one recorded action.
+type Entry struct {
+	Time     time.Time         `json:"time"`
+	Caller   string            `json:"caller,omitempty"`
+	Account  string            `json:"account,omitempty"`
+	Profile  string            `json:"profile"`
+	Region   string            `json:"region"`
+	Action   string            `json:"action"`
+	Resource string            `json:"resource"`
+	Params   map[string]string `json:"params,omitempty"`
+	Result   string            `json:"result"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Log is an audit log file. A Log without a path keeps its entries in
+// memory, which the demo mode uses to leave the real log alone.
+type Log struct {
+	path string
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Open returns the log stored at path, or an in-memory log if path is empty.
+// The file is created on the first Append.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns where the log is stored, or "" for an in-memory log.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append records the entries at the end of the log, in one write.
+func (l *Log) Append(entries ...Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.path == "" {
+		l.entries = append(l.entries, entries...)
+		return nil
+	}
+	var b []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b = append(append(b, line...), '\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Entries returns every entry in the order they were recorded. A log that
+// has not been written yet is empty.
+func (l *Log) Entries() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.path == "" {
+		return append([]Entry(nil), l.entries...), nil
+	}
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return entries, fmt.Errorf("%s:%d: %w", l.path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
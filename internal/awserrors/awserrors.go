@@ -0,0 +1,167 @@
+// Package awserrors classifies the errors returned by AWS and retries
+// throttled requests.
+package awserrors
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// Kind is the class of an error.
+type Kind int
+
+const (
+	// Other is any error that is not classified.
+	Other Kind = iota
+	// Throttled means AWS rejected the request because of its rate.
+	Throttled
+	// AccessDenied means the identity is not allowed to make the request.
+	AccessDenied
+	// ExpiredCredentials means the credentials have expired, or the
+	// session they come from has, and the user has to log in again.
+	ExpiredCredentials
+	// NotFound means the resource does not exist (any more).
+	NotFound
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Throttled:
+		return "Throttled"
+	case AccessDenied:
+		return "Access denied"
+	case ExpiredCredentials:
+		return "Credentials expired"
+	case NotFound:
+		return "Not found"
+	}
+	return "Error"
+}
+
+var accessDeniedCodes = map[string]bool{
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+	"UnauthorizedOperation": true,
+	"UnauthorizedException": true,
+	"AuthorizationError":    true,
+}
+
+var expiredCredentialsCodes = map[string]bool{
+	"ExpiredToken":                          true,
+	"ExpiredTokenException":                 true,
+	"RequestExpired":                        true,
+	ssocreds.ErrCodeSSOProviderInvalidToken: true,
+}
+
+// Classify returns the kind of the first AWS error in err's chain. Joined
+// errors are classified by their first member; use Split to classify each.
+func Classify(err error) Kind {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return Other
+	}
+	code := aerr.Code()
+	switch {
+	case request.IsErrorThrottle(aerr):
+		return Throttled
+	case expiredCredentialsCodes[code]:
+		return ExpiredCredentials
+	case accessDeniedCodes[code]:
+		return AccessDenied
+	case strings.Contains(code, "NotFound"), strings.Contains(code, "DoesNotExist"):
+		return NotFound
+	}
+	// The credential providers wrap the error that stopped them.
+	if orig := aerr.OrigErr(); orig != nil {
+		return Classify(orig)
+	}
+	return Other
+}
+
+// Split returns the errors joined in err, flattened, or err itself.
+func Split(err error) []error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, Split(e)...)
+	}
+	return errs
+}
+
+// Has reports whether any of the errors joined in err is of kind k.
+func Has(err error, k Kind) bool {
+	for _, e := range Split(err) {
+		if Classify(e) == k {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// MaxThrottleRetries is how often a throttled request is retried.
+	MaxThrottleRetries = 8
+	// minThrottleDelay and maxThrottleDelay bound the backoff between
+	// retries of a throttled request.
+	minThrottleDelay = 500 * time.Millisecond
+	maxThrottleDelay = 20 * time.Second
+)
+
+// Retryer retries throttled requests up to MaxThrottleRetries times with
+// jittered exponential backoff, and every other request as the SDK's default
+// retryer does. Listing every region at once easily runs into the API rate
+// limits, which the default three retries do not ride out.
+//
+// Set it together with aws.Config.EnforceShouldRetryCheck, so that errors the
+// SDK already marked as retryable still get the default number of retries.
+type Retryer struct {
+	client.DefaultRetryer
+}
+
+// NewRetryer returns a Retryer.
+func NewRetryer() Retryer {
+	return Retryer{client.DefaultRetryer{NumMaxRetries: client.DefaultRetryerMaxNumRetries}}
+}
+
+// MaxRetries returns the most retries any request gets.
+func (r Retryer) MaxRetries() int {
+	return max(MaxThrottleRetries, r.NumMaxRetries)
+}
+
+// ShouldRetry reports whether the failed request r should be retried.
+func (r Retryer) ShouldRetry(req *request.Request) bool {
+	if req.IsErrorThrottle() {
+		return true
+	}
+	return req.RetryCount < r.NumMaxRetries && r.DefaultRetryer.ShouldRetry(req)
+}
+
+// RetryRules returns how long to wait before retrying req. Throttled
+// requests back off exponentially with full jitter.
+func (r Retryer) RetryRules(req *request.Request) time.Duration {
+	if !req.IsErrorThrottle() {
+		return r.DefaultRetryer.RetryRules(req)
+	}
+	ceiling := min(maxThrottleDelay, minThrottleDelay<<min(req.RetryCount, 16))
+	delay := minThrottleDelay/2 + rand.N(ceiling)
+	slog.Debug("aws request throttled",
+		"service", req.ClientInfo.ServiceName,
+		"operation", req.Operation.Name,
+		"retry", req.RetryCount+1,
+		"delay", delay.String())
+	return delay
+}
@@ -0,0 +1,307 @@
+// Package cli runs awstui's views without the TUI, for use in scripts. Every
+// subcommand drives the same commands as the TUI and prints the result as a
+// table, JSON or YAML.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrUsage is returned, wrapped, when the command line is malformed.
+var ErrUsage = errors.New("usage")
+
+// Usage describes every subcommand.
+const Usage = `Usage: awstui [flags] <service> <command> [args] [-o table|json|yaml] [-region <region|all>]
+
+  ec2 ls
+  ecs clusters
+  ecs services <cluster>
+  ecr repos
+  ecr images <repository>
+  sfn ls
+  sfn executions <state machine>
+  sfn start <state machine> [-input file.json]
+  batch queues
+  batch jobs <job queue>
+  batch logs <job id>
+
+Without a service, awstui starts the interactive interface.
+`
+
+// command is a subcommand. args holds the positional arguments after the
+// service and command names.
+type command struct {
+	args  int
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var services = map[string]map[string]command{
+	"ec2": {
+		"ls": {0, "ec2 ls", ec2List},
+	},
+	"ecs": {
+		"clusters": {0, "ecs clusters", ecsClusters},
+		"services": {1, "ecs services <cluster>", ecsServices},
+	},
+	"ecr": {
+		"repos":  {0, "ecr repos", ecrRepositories},
+		"images": {1, "ecr images <repository>", ecrImages},
+	},
+	"sfn": {
+		"ls":         {0, "sfn ls", sfnList},
+		"executions": {1, "sfn executions <state machine>", sfnExecutions},
+		"start":      {1, "sfn start <state machine> [-input file.json]", sfnStart},
+	},
+	"batch": {
+		"queues": {0, "batch queues", batchQueues},
+		"jobs":   {1, "batch jobs <job queue>", batchJobs},
+		"logs":   {1, "batch logs <job id>", batchLogs},
+	},
+}
+
+// env is what a subcommand runs with.
+type env struct {
+	ctx    context.Context
+	pool   *clients.Pool
+	conf   *config.Config
+	log    *audit.Log
+	out    io.Writer
+	output string
+	region string
+	input  string
+	caller *messages.CallerIdentityMsg
+}
+
+// Run executes the subcommand in args, writing its result to out, until ctx
+// is cancelled. Mutating subcommands are subject to the policies in conf and
+// recorded in log.
+func Run(ctx context.Context, pool *clients.Pool, conf *config.Config, log *audit.Log, args []string, out io.Writer) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%w: expected a service and a command\n\n%s", ErrUsage, Usage)
+	}
+	cmd, ok := services[args[0]][args[1]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q\n\n%s", ErrUsage, strings.Join(args[:2], " "), Usage)
+	}
+
+	e := &env{ctx: ctx, pool: pool, conf: conf, log: log, out: out}
+	fs := flag.NewFlagSet(cmd.usage, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&e.output, "o", "table", "output format: table, json or yaml")
+	fs.StringVar(&e.region, "region", "", `region to query, or "all"`)
+	fs.StringVar(&e.input, "input", "", "file holding the execution input (sfn start)")
+	positional, err := parseInterspersed(fs, args[2:])
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrUsage, cmd.usage, err)
+	}
+	if len(positional) != cmd.args {
+		return fmt.Errorf("%w: awstui %s", ErrUsage, cmd.usage)
+	}
+	switch e.output {
+	case "table", "json", "yaml":
+	default:
+		return fmt.Errorf("%w: unknown output format %q", ErrUsage, e.output)
+	}
+	return cmd.run(e, positional)
+}
+
+// parseInterspersed parses flags given before, between or after the
+// positional arguments, which the flag package alone stops at.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// regions returns the clients for the regions selected with -region.
+func (e *env) regions() ([]*clients.Clients, error) {
+	switch e.region {
+	case "":
+		return e.pool.Regions(nil), nil
+	case "all":
+		var regions []string
+		err := drain(commands.FetchRegionsCmd(e.ctx, e.pool.Region("").EC2), func(msg tea.Msg) {
+			if msg, ok := msg.(messages.RegionsFetchedMsg); ok {
+				regions = msg
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return e.pool.Regions(regions), nil
+	}
+	return e.pool.Regions([]string{e.region}), nil
+}
+
+// client returns the clients of the single region selected with -region.
+func (e *env) client() (*clients.Clients, error) {
+	if e.region == "all" {
+		return nil, fmt.Errorf(`%w: -region all is only supported when listing top-level resources`, ErrUsage)
+	}
+	return e.pool.Region(e.region), nil
+}
+
+// identity returns the caller identity of the profile, fetching it the first
+// time.
+func (e *env) identity() (messages.CallerIdentityMsg, error) {
+	if e.caller != nil {
+		return *e.caller, nil
+	}
+	err := drain(commands.FetchCallerIdentityCmd(e.ctx, e.pool.Region("").STS, e.pool.Profile), func(msg tea.Msg) {
+		if msg, ok := msg.(messages.CallerIdentityMsg); ok {
+			e.caller = &msg
+		}
+	})
+	if err != nil || e.caller == nil {
+		return messages.CallerIdentityMsg{}, err
+	}
+	return *e.caller, nil
+}
+
+// check returns why action may not run under the policy of the profile and
+// account. Typed confirmation cannot be given without the interface, so it
+// refuses the action as well.
+func (e *env) check(action string) error {
+	account := ""
+	if e.conf.HasAccountPolicies() {
+		id, err := e.identity()
+		if err != nil {
+			return err
+		}
+		account = id.Account
+	}
+	policy, reason := e.conf.Policy(clients.DisplayProfile(e.pool.Profile), account)
+	switch policy {
+	case config.PolicyReadOnly:
+		return fmt.Errorf("cannot %s: %s", action, reason)
+	case config.PolicyTypedConfirmation:
+		return fmt.Errorf("cannot %s: %s, which is only possible in the interface", action, reason)
+	}
+	return nil
+}
+
+// audited records cmd in the audit log when it runs, as the TUI does.
+func (e *env) audited(c *clients.Clients, action, resource string, params map[string]string, cmd tea.Cmd) (tea.Cmd, error) {
+	id, err := e.identity()
+	if err != nil {
+		return nil, err
+	}
+	return commands.Audited(e.log, audit.Entry{
+		Caller:   id.Arn,
+		Account:  id.Account,
+		Profile:  clients.DisplayProfile(e.pool.Profile),
+		Region:   c.Region,
+		Action:   action,
+		Resource: resource,
+		Params:   params,
+	}, cmd), nil
+}
+
+// drain runs cmd and every command its messages lead to, batches and the
+// following pages of a listing alike, and passes each message to handle.
+// Page chains run concurrently, as in the TUI; handle is never called
+// concurrently. The errors of every chain are returned together.
+func drain(cmd tea.Cmd, handle func(tea.Msg)) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+		run  func(tea.Cmd)
+	)
+	run = func(cmd tea.Cmd) {
+		defer wg.Done()
+		if cmd == nil {
+			return
+		}
+		msg := cmd()
+		mu.Lock()
+		defer mu.Unlock()
+		switch msg := msg.(type) {
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				wg.Add(1)
+				go run(cmd)
+			}
+		case messages.ErrMsg:
+			errs = append(errs, msg)
+		case messages.Paged:
+			page := msg.Paging()
+			if page.Err != nil {
+				errs = append(errs, page.Err)
+				return
+			}
+			handle(msg)
+			wg.Add(1)
+			go run(page.Next)
+		default:
+			handle(msg)
+		}
+	}
+	wg.Add(1)
+	run(cmd)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// write prints rows in the selected output format. Tables show header and
+// the cells of each row; JSON and YAML encode the rows themselves.
+func write[T any](e *env, rows []T, header []string, cells func(T) []string) error {
+	switch e.output {
+	case "json":
+		if rows == nil {
+			rows = []T{}
+		}
+		enc := json.NewEncoder(e.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "yaml":
+		b, err := yaml.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		_, err = e.out.Write(b)
+		return err
+	}
+	tw := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(cells(row), "\t"))
+	}
+	return tw.Flush()
+}
+
+// sortBy orders rows by the keys returned for them, in turn.
+func sortBy[T any](rows []T, keys func(T) []string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return slices.Compare(keys(rows[i]), keys(rows[j])) < 0
+	})
+}
@@ -0,0 +1,486 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type ec2Row struct {
+	Name      string `json:"name" yaml:"name"`
+	ID        string `json:"id" yaml:"id"`
+	State     string `json:"state" yaml:"state"`
+	Type      string `json:"type" yaml:"type"`
+	Region    string `json:"region" yaml:"region"`
+	PrivateIP string `json:"privateIp,omitempty" yaml:"private_ip,omitempty"`
+	PublicIP  string `json:"publicIp,omitempty" yaml:"public_ip,omitempty"`
+}
+
+func ec2List(e *env, _ []string) error {
+	cs, err := e.regions()
+	if err != nil {
+		return err
+	}
+	var rows []ec2Row
+	err = drain(commands.FetchInstancesCmd(e.ctx, cs), func(m tea.Msg) {
+		msg, ok := m.(messages.InstancesFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, i := range msg.Instances {
+			rows = append(rows, ec2Row{
+				Name:      utils.GetInstanceName(i),
+				ID:        aws.StringValue(i.InstanceId),
+				State:     aws.StringValue(i.State.Name),
+				Type:      aws.StringValue(i.InstanceType),
+				Region:    msg.Region,
+				PrivateIP: aws.StringValue(i.PrivateIpAddress),
+				PublicIP:  aws.StringValue(i.PublicIpAddress),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sortBy(rows, func(r ec2Row) []string { return []string{r.Region, r.Name, r.ID} })
+	return write(e, rows,
+		[]string{"NAME", "ID", "STATE", "TYPE", "REGION", "PRIVATE IP", "PUBLIC IP"},
+		func(r ec2Row) []string {
+			return []string{r.Name, r.ID, r.State, r.Type, r.Region, r.PrivateIP, r.PublicIP}
+		})
+}
+
+type ecsClusterRow struct {
+	Name           string `json:"name" yaml:"name"`
+	Status         string `json:"status" yaml:"status"`
+	ActiveServices int64  `json:"activeServices" yaml:"active_services"`
+	RunningTasks   int64  `json:"runningTasks" yaml:"running_tasks"`
+	Region         string `json:"region" yaml:"region"`
+	ARN            string `json:"arn" yaml:"arn"`
+}
+
+func ecsClusters(e *env, _ []string) error {
+	cs, err := e.regions()
+	if err != nil {
+		return err
+	}
+	var rows []ecsClusterRow
+	err = drain(commands.FetchECSClustersCmd(e.ctx, cs), func(m tea.Msg) {
+		msg, ok := m.(messages.EcsClustersFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, c := range msg.Clusters {
+			rows = append(rows, ecsClusterRow{
+				Name:           aws.StringValue(c.ClusterName),
+				Status:         aws.StringValue(c.Status),
+				ActiveServices: aws.Int64Value(c.ActiveServicesCount),
+				RunningTasks:   aws.Int64Value(c.RunningTasksCount),
+				Region:         msg.Region,
+				ARN:            aws.StringValue(c.ClusterArn),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sortBy(rows, func(r ecsClusterRow) []string { return []string{r.Region, r.Name} })
+	return write(e, rows,
+		[]string{"NAME", "STATUS", "SERVICES", "TASKS", "REGION"},
+		func(r ecsClusterRow) []string {
+			return []string{r.Name, r.Status, fmt.Sprint(r.ActiveServices), fmt.Sprint(r.RunningTasks), r.Region}
+		})
+}
+
+type ecsServiceRow struct {
+	Name           string `json:"name" yaml:"name"`
+	Status         string `json:"status" yaml:"status"`
+	Desired        int64  `json:"desired" yaml:"desired"`
+	Running        int64  `json:"running" yaml:"running"`
+	Pending        int64  `json:"pending" yaml:"pending"`
+	TaskDefinition string `json:"taskDefinition" yaml:"task_definition"`
+	ARN            string `json:"arn" yaml:"arn"`
+}
+
+func ecsServices(e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	var rows []ecsServiceRow
+	err = drain(commands.FetchECSServicesCmd(e.ctx, c.ECS, args[0]), func(m tea.Msg) {
+		msg, ok := m.(messages.EcsServicesFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, s := range msg.Services {
+			rows = append(rows, ecsServiceRow{
+				Name:           aws.StringValue(s.ServiceName),
+				Status:         aws.StringValue(s.Status),
+				Desired:        aws.Int64Value(s.DesiredCount),
+				Running:        aws.Int64Value(s.RunningCount),
+				Pending:        aws.Int64Value(s.PendingCount),
+				TaskDefinition: lastSegment(aws.StringValue(s.TaskDefinition)),
+				ARN:            aws.StringValue(s.ServiceArn),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sortBy(rows, func(r ecsServiceRow) []string { return []string{r.Name} })
+	return write(e, rows,
+		[]string{"NAME", "STATUS", "DESIRED", "RUNNING", "PENDING", "TASK DEFINITION"},
+		func(r ecsServiceRow) []string {
+			return []string{r.Name, r.Status, fmt.Sprint(r.Desired), fmt.Sprint(r.Running), fmt.Sprint(r.Pending), r.TaskDefinition}
+		})
+}
+
+type ecrRepositoryRow struct {
+	Name   string `json:"name" yaml:"name"`
+	Region string `json:"region" yaml:"region"`
+	URI    string `json:"uri" yaml:"uri"`
+}
+
+func ecrRepositories(e *env, _ []string) error {
+	cs, err := e.regions()
+	if err != nil {
+		return err
+	}
+	var rows []ecrRepositoryRow
+	err = drain(commands.FetchECRRepositoriesCmd(e.ctx, cs), func(m tea.Msg) {
+		msg, ok := m.(messages.EcrRepositoriesFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, r := range msg.Repositories {
+			rows = append(rows, ecrRepositoryRow{
+				Name:   aws.StringValue(r.RepositoryName),
+				Region: msg.Region,
+				URI:    aws.StringValue(r.RepositoryUri),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sortBy(rows, func(r ecrRepositoryRow) []string { return []string{r.Region, r.Name} })
+	return write(e, rows,
+		[]string{"NAME", "REGION", "URI"},
+		func(r ecrRepositoryRow) []string { return []string{r.Name, r.Region, r.URI} })
+}
+
+type ecrImageRow struct {
+	Tags   []string  `json:"tags" yaml:"tags"`
+	Digest string    `json:"digest" yaml:"digest"`
+	Pushed time.Time `json:"pushed" yaml:"pushed"`
+	Size   int64     `json:"sizeBytes" yaml:"size_bytes"`
+}
+
+func ecrImages(e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	var rows []ecrImageRow
+	err = drain(commands.FetchECRImagesCmd(e.ctx, c.ECR, aws.String(args[0])), func(m tea.Msg) {
+		msg, ok := m.(messages.EcrImagesFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, i := range msg.Images {
+			rows = append(rows, ecrImageRow{
+				Tags:   aws.StringValueSlice(i.ImageTags),
+				Digest: aws.StringValue(i.ImageDigest),
+				Pushed: aws.TimeValue(i.ImagePushedAt),
+				Size:   aws.Int64Value(i.ImageSizeInBytes),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sortBy(rows, func(r ecrImageRow) []string { return []string{r.Pushed.Format(time.RFC3339), r.Digest} })
+	return write(e, rows,
+		[]string{"TAGS", "DIGEST", "PUSHED", "SIZE"},
+		func(r ecrImageRow) []string {
+			return []string{strings.Join(r.Tags, ","), r.Digest, r.Pushed.Format(time.RFC822), fmt.Sprint(r.Size)}
+		})
+}
+
+type sfnStateMachineRow struct {
+	Name   string `json:"name" yaml:"name"`
+	Type   string `json:"type" yaml:"type"`
+	Region string `json:"region" yaml:"region"`
+	ARN    string `json:"arn" yaml:"arn"`
+}
+
+func sfnList(e *env, _ []string) error {
+	cs, err := e.regions()
+	if err != nil {
+		return err
+	}
+	rows, err := stateMachines(e, cs)
+	if err != nil {
+		return err
+	}
+	return write(e, rows,
+		[]string{"NAME", "TYPE", "REGION", "ARN"},
+		func(r sfnStateMachineRow) []string { return []string{r.Name, r.Type, r.Region, r.ARN} })
+}
+
+func stateMachines(e *env, cs []*clients.Clients) ([]sfnStateMachineRow, error) {
+	var rows []sfnStateMachineRow
+	err := drain(commands.FetchSFNStateMachinesCmd(e.ctx, cs), func(m tea.Msg) {
+		msg, ok := m.(messages.SfnStateMachinesFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, sm := range msg.StateMachines {
+			rows = append(rows, sfnStateMachineRow{
+				Name:   aws.StringValue(sm.Name),
+				Type:   aws.StringValue(sm.Type),
+				Region: msg.Region,
+				ARN:    aws.StringValue(sm.StateMachineArn),
+			})
+		}
+	})
+	sortBy(rows, func(r sfnStateMachineRow) []string { return []string{r.Region, r.Name} })
+	return rows, err
+}
+
+// stateMachineArn resolves a state machine given by name or ARN.
+func stateMachineArn(e *env, c *clients.Clients, name string) (string, error) {
+	if strings.HasPrefix(name, "arn:") {
+		return name, nil
+	}
+	rows, err := stateMachines(e, []*clients.Clients{c})
+	if err != nil {
+		return "", err
+	}
+	for _, r := range rows {
+		if r.Name == name {
+			return r.ARN, nil
+		}
+	}
+	return "", fmt.Errorf("state machine %q not found in %s", name, c.Region)
+}
+
+type sfnExecutionRow struct {
+	Name    string     `json:"name" yaml:"name"`
+	Status  string     `json:"status" yaml:"status"`
+	Started time.Time  `json:"started" yaml:"started"`
+	Stopped *time.Time `json:"stopped,omitempty" yaml:"stopped,omitempty"`
+	ARN     string     `json:"arn" yaml:"arn"`
+}
+
+func sfnExecutions(e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	arn, err := stateMachineArn(e, c, args[0])
+	if err != nil {
+		return err
+	}
+	var rows []sfnExecutionRow
+	err = drain(commands.FetchSFNExecutionsCmd(e.ctx, c.SFN, aws.String(arn)), func(m tea.Msg) {
+		msg, ok := m.(messages.SfnExecutionsFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, ex := range msg.Executions {
+			rows = append(rows, sfnExecutionRow{
+				Name:    aws.StringValue(ex.Name),
+				Status:  aws.StringValue(ex.Status),
+				Started: aws.TimeValue(ex.StartDate),
+				Stopped: ex.StopDate,
+				ARN:     aws.StringValue(ex.ExecutionArn),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return write(e, rows,
+		[]string{"NAME", "STATUS", "STARTED", "STOPPED"},
+		func(r sfnExecutionRow) []string {
+			stopped := ""
+			if r.Stopped != nil {
+				stopped = r.Stopped.Format(time.RFC822)
+			}
+			return []string{r.Name, r.Status, r.Started.Format(time.RFC822), stopped}
+		})
+}
+
+type sfnStartedRow struct {
+	ExecutionARN string `json:"executionArn" yaml:"execution_arn"`
+}
+
+func sfnStart(e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	if err := e.check("start executions"); err != nil {
+		return err
+	}
+	arn, err := stateMachineArn(e, c, args[0])
+	if err != nil {
+		return err
+	}
+	input := "{}"
+	if e.input != "" {
+		b, err := readInput(e.input)
+		if err != nil {
+			return fmt.Errorf("failed to read execution input: %w", err)
+		}
+		input = string(b)
+	}
+	start, err := e.audited(c, "states:StartExecution", arn, map[string]string{"input": input},
+		commands.StartSFNExecutionCmd(e.ctx, c.SFN, aws.String(arn), aws.String(input)))
+	if err != nil {
+		return err
+	}
+	var rows []sfnStartedRow
+	err = drain(start, func(msg tea.Msg) {
+		if msg, ok := msg.(messages.SfnExecutionStartedMsg); ok {
+			rows = append(rows, sfnStartedRow{ExecutionARN: string(msg)})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return write(e, rows,
+		[]string{"EXECUTION ARN"},
+		func(r sfnStartedRow) []string { return []string{r.ExecutionARN} })
+}
+
+// readInput reads a file, or standard input for "-".
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+type batchJobQueueRow struct {
+	Name     string `json:"name" yaml:"name"`
+	State    string `json:"state" yaml:"state"`
+	Status   string `json:"status" yaml:"status"`
+	Priority int64  `json:"priority" yaml:"priority"`
+	Region   string `json:"region" yaml:"region"`
+	ARN      string `json:"arn" yaml:"arn"`
+}
+
+func batchQueues(e *env, _ []string) error {
+	cs, err := e.regions()
+	if err != nil {
+		return err
+	}
+	var rows []batchJobQueueRow
+	err = drain(commands.FetchBatchJobQueuesCmd(e.ctx, cs), func(m tea.Msg) {
+		msg, ok := m.(messages.BatchJobQueuesFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, q := range msg.JobQueues {
+			rows = append(rows, batchJobQueueRow{
+				Name:     aws.StringValue(q.JobQueueName),
+				State:    aws.StringValue(q.State),
+				Status:   aws.StringValue(q.Status),
+				Priority: aws.Int64Value(q.Priority),
+				Region:   msg.Region,
+				ARN:      aws.StringValue(q.JobQueueArn),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sortBy(rows, func(r batchJobQueueRow) []string { return []string{r.Region, r.Name} })
+	return write(e, rows,
+		[]string{"NAME", "STATE", "STATUS", "PRIORITY", "REGION"},
+		func(r batchJobQueueRow) []string {
+			return []string{r.Name, r.State, r.Status, fmt.Sprint(r.Priority), r.Region}
+		})
+}
+
+type batchJobRow struct {
+	Name    string    `json:"name" yaml:"name"`
+	ID      string    `json:"id" yaml:"id"`
+	Status  string    `json:"status" yaml:"status"`
+	Created time.Time `json:"created" yaml:"created"`
+}
+
+func batchJobs(e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	var rows []batchJobRow
+	err = drain(commands.FetchBatchJobsCmd(e.ctx, c.Batch, aws.String(args[0])), func(m tea.Msg) {
+		msg, ok := m.(messages.BatchJobsFetchedMsg)
+		if !ok {
+			return
+		}
+		for _, j := range msg.Jobs {
+			rows = append(rows, batchJobRow{
+				Name:    aws.StringValue(j.JobName),
+				ID:      aws.StringValue(j.JobId),
+				Status:  aws.StringValue(j.Status),
+				Created: time.UnixMilli(aws.Int64Value(j.CreatedAt)).UTC(),
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	// Newest first, as in the TUI.
+	slices.SortStableFunc(rows, func(a, b batchJobRow) int { return b.Created.Compare(a.Created) })
+	return write(e, rows,
+		[]string{"NAME", "ID", "STATUS", "CREATED"},
+		func(r batchJobRow) []string { return []string{r.Name, r.ID, r.Status, r.Created.Format(time.RFC822)} })
+}
+
+// batchLogs prints the CloudWatch logs of a job as plain text, whatever the
+// output format.
+func batchLogs(e *env, args []string) error {
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	var stream *string
+	err = drain(commands.FetchBatchJobDetailsCmd(e.ctx, c.Batch, aws.String(args[0])), func(msg tea.Msg) {
+		if msg, ok := msg.(messages.BatchJobDetailsMsg); ok && msg.Container != nil {
+			stream = msg.Container.LogStreamName
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if aws.StringValue(stream) == "" {
+		return fmt.Errorf("batch job %s has no log stream", args[0])
+	}
+	return drain(commands.FetchBatchJobLogsCmd(e.ctx, c.Logs, stream), func(msg tea.Msg) {
+		if msg, ok := msg.(messages.BatchJobLogsFetchedMsg); ok {
+			fmt.Fprint(e.out, string(msg))
+		}
+	})
+}
+
+// lastSegment returns what follows the last slash of an ARN.
+func lastSegment(arn string) string {
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
@@ -0,0 +1,236 @@
+package clients
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/awserrors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/batch/batchiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// Clients bundles the AWS service clients used by awstui for a single
+// profile and region. The services are held as their SDK interfaces so the
+// whole application can be pointed at fakes.
+type Clients struct {
+	Profile string
+	Region  string
+	EC2     ec2iface.EC2API
+	ECS     ecsiface.ECSAPI
+	ECR     ecriface.ECRAPI
+	Logs    cloudwatchlogsiface.CloudWatchLogsAPI
+	SFN     sfniface.SFNAPI
+	Batch   batchiface.BatchAPI
+	STS     stsiface.STSAPI
+}
+
+// Factory builds the clients for a region.
+type Factory func(region string) *Clients
+
+// Pool lazily builds one Clients bundle per region for a profile.
+type Pool struct {
+	Profile       string
+	DefaultRegion string
+
+	build   Factory
+	open    func(profile string) (*Pool, error)
+	mu      sync.Mutex
+	regions map[string]*Clients
+}
+
+// Services lists the keys accepted in Options.Endpoints.
+var Services = []string{"ec2", "ecs", "ecr", "logs", "sfn", "batch", "sts"}
+
+// Options controls how a Pool connects to AWS.
+type Options struct {
+	// Profile is the shared config profile. Empty uses the SDK's default
+	// resolution (AWS_PROFILE, then "default").
+	Profile string
+	// Region overrides the profile's default region.
+	Region string
+	// Endpoint replaces the endpoint of every service, e.g. LocalStack's
+	// http://localhost:4566. Endpoints overrides it per service.
+	Endpoint  string
+	Endpoints map[string]string
+	// InsecureSkipVerify disables TLS certificate verification.
+	InsecureSkipVerify bool
+	// CABundle is the path of a PEM bundle trusted in addition to the
+	// system roots.
+	CABundle string
+	// Recorder, if set, records every request made by the clients.
+	Recorder *apilog.Recorder
+}
+
+// endpoint returns the endpoint override for service, if any.
+func (o Options) endpoint(service string) string {
+	if e, ok := o.Endpoints[service]; ok {
+		return e
+	}
+	return o.Endpoint
+}
+
+func (o Options) validate() error {
+	for service := range o.Endpoints {
+		if !slices.Contains(Services, service) {
+			return fmt.Errorf("unknown service %q in endpoints, expected one of %s", service, strings.Join(Services, ", "))
+		}
+	}
+	return nil
+}
+
+// NewPool creates a session from opts and builds service clients from it on
+// demand.
+func NewPool(opts Options) (*Pool, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	sessOpts := session.Options{
+		Profile:           opts.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+		Config: aws.Config{
+			Retryer:                 awserrors.NewRetryer(),
+			EnforceShouldRetryCheck: aws.Bool(true),
+		},
+	}
+	if opts.Region != "" {
+		sessOpts.Config.Region = aws.String(opts.Region)
+	}
+	if opts.InsecureSkipVerify {
+		sessOpts.Config.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+	}
+	if opts.CABundle != "" {
+		bundle, err := os.Open(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open CA bundle: %w", err)
+		}
+		defer bundle.Close()
+		sessOpts.CustomCABundle = bundle
+	}
+
+	sess, err := session.NewSessionWithOptions(sessOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session for profile %s: %w", DisplayProfile(opts.Profile), err)
+	}
+	if opts.Recorder != nil {
+		opts.Recorder.Install(&sess.Handlers)
+	}
+
+	build := func(region string) *Clients {
+		cfg := func(service string) *aws.Config {
+			c := aws.NewConfig().WithRegion(region)
+			if e := opts.endpoint(service); e != "" {
+				c = c.WithEndpoint(e)
+			}
+			return c
+		}
+		return &Clients{
+			Profile: opts.Profile,
+			Region:  region,
+			EC2:     ec2.New(sess, cfg("ec2")),
+			ECS:     ecs.New(sess, cfg("ecs")),
+			ECR:     ecr.New(sess, cfg("ecr")),
+			Logs:    cloudwatchlogs.New(sess, cfg("logs")),
+			SFN:     sfn.New(sess, cfg("sfn")),
+			Batch:   batch.New(sess, cfg("batch")),
+			STS:     sts.New(sess, cfg("sts")),
+		}
+	}
+	return &Pool{
+		Profile:       opts.Profile,
+		DefaultRegion: aws.StringValue(sess.Config.Region),
+		build:         build,
+		open: func(profile string) (*Pool, error) {
+			opts.Profile = profile
+			return NewPool(opts)
+		},
+		regions: map[string]*Clients{},
+	}, nil
+}
+
+// NewFactoryPool creates a pool whose clients come from build instead of a
+// real AWS session. Switching profiles keeps using the same factory.
+func NewFactoryPool(profile, defaultRegion string, build Factory) *Pool {
+	return &Pool{
+		Profile:       profile,
+		DefaultRegion: defaultRegion,
+		build:         build,
+		open: func(profile string) (*Pool, error) {
+			return NewFactoryPool(profile, defaultRegion, build), nil
+		},
+		regions: map[string]*Clients{},
+	}
+}
+
+// WithProfile returns a new pool of the same kind for another profile.
+func (p *Pool) WithProfile(profile string) (*Pool, error) {
+	return p.open(profile)
+}
+
+// Region returns the clients for region, creating them on first use. An
+// empty region returns the clients for the profile's default region.
+func (p *Pool) Region(region string) *Clients {
+	if region == "" {
+		region = p.DefaultRegion
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.regions[region]; ok {
+		return c
+	}
+
+	c := p.build(region)
+	p.regions[region] = c
+	return c
+}
+
+// Regions returns the clients for every given region. No regions means the
+// profile's default region.
+func (p *Pool) Regions(regions []string) []*Clients {
+	if len(regions) == 0 {
+		return []*Clients{p.Region("")}
+	}
+	ret := make([]*Clients, len(regions))
+	for i, r := range regions {
+		ret[i] = p.Region(r)
+	}
+	return ret
+}
+
+// DisplayProfile returns the name of the profile the SDK will actually use.
+func DisplayProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv("AWS_PROFILE"); env != "" {
+		return env
+	}
+	return session.DefaultSharedConfigProfile
+}
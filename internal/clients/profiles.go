@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/defaults"
+)
+
+// Profile is a named profile found in the shared AWS config files.
+type Profile struct {
+	Name    string
+	Sources []string
+}
+
+// ListProfiles parses ~/.aws/config and ~/.aws/credentials (honouring
+// AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE) and returns every profile
+// sorted by name.
+func ListProfiles() []Profile {
+	found := map[string]*Profile{}
+	add := func(name, source string) {
+		p, ok := found[name]
+		if !ok {
+			p = &Profile{Name: name}
+			found[name] = p
+		}
+		p.Sources = append(p.Sources, source)
+	}
+
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = defaults.SharedConfigFilename()
+	}
+	for _, section := range readSections(configFile) {
+		// The config file prefixes every profile except "default" with
+		// "profile ". Other sections (sso-session, services) are skipped.
+		if section == "default" {
+			add(section, "config")
+		} else if name, ok := strings.CutPrefix(section, "profile "); ok {
+			add(strings.TrimSpace(name), "config")
+		}
+	}
+
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = defaults.SharedCredentialsFilename()
+	}
+	for _, section := range readSections(credentialsFile) {
+		add(section, "credentials")
+	}
+
+	profiles := make([]Profile, 0, len(found))
+	for _, p := range found {
+		profiles = append(profiles, *p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// readSections returns the section names of an ini file, ignoring any errors.
+func readSections(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var sections []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, strings.TrimSpace(line[1:len(line)-1]))
+		}
+	}
+	return sections
+}
+
+// UsesSSO reports whether profile gets its credentials from IAM Identity
+// Center, in which case `aws sso login` renews them.
+func UsesSSO(profile string) bool {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = defaults.SharedConfigFilename()
+	}
+	section := "profile " + profile
+	if profile == "default" {
+		section = profile
+	}
+	keys := readKeys(configFile, section)
+	return keys["sso_start_url"] != "" || keys["sso_session"] != ""
+}
+
+// readKeys returns the keys set in one section of an ini file, ignoring any
+// errors.
+func readKeys(path, section string) map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	keys := map[string]string{}
+	in := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			in = strings.TrimSpace(line[1:len(line)-1]) == section
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && in {
+			keys[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"slices"
+
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchAPICallsCmd returns the AWS requests recorded so far, newest first.
+func FetchAPICallsCmd(calls *apilog.Recorder) tea.Cmd {
+	return func() tea.Msg {
+		recorded := calls.Calls()
+		slices.Reverse(recorded)
+		return messages.APICallsFetchedMsg(recorded)
+	}
+}
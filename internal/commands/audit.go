@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Audited runs cmd, a mutating command, and records e in log together with
+// its outcome. If the entry cannot be written the result of cmd is still
+// delivered, followed by the error. Commands reporting progress are recorded
+// once their result arrives.
+func Audited(log *audit.Log, e audit.Entry, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		e.Time = time.Now().UTC()
+		return audited(log, e, nil, cmd)()
+	}
+}
+
+// AuditedBulk runs cmd, a bulk action, and records e in log once for every
+// resource the action reports on, with the resource named by resource and
+// the outcome for that resource.
+func AuditedBulk(log *audit.Log, e audit.Entry, resource func(messages.BulkResult) string, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		e.Time = time.Now().UTC()
+		return audited(log, e, resource, cmd)()
+	}
+}
+
+func audited(log *audit.Log, e audit.Entry, resource func(messages.BulkResult) string, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := cmd()
+		if p, ok := msg.(messages.ProgressMsg); ok {
+			p.Next = audited(log, e, resource, p.Next)
+			return p
+		}
+		var entries []audit.Entry
+		if bulk, ok := msg.(messages.BulkActionMsg); ok && resource != nil {
+			for _, r := range bulk.Results {
+				entries = append(entries, outcome(e, r.Region, resource(r), r.Err))
+			}
+		} else {
+			err, _ := msg.(messages.ErrMsg)
+			entries = append(entries, outcome(e, e.Region, e.Resource, err))
+		}
+		if err := log.Append(entries...); err != nil {
+			return tea.BatchMsg{
+				func() tea.Msg { return msg },
+				func() tea.Msg {
+					return messages.ErrMsg(fmt.Errorf("failed to record %s in the audit log: %w", e.Action, err))
+				},
+			}
+		}
+		return msg
+	}
+}
+
+// outcome is e for resource in region, with the result err reports.
+func outcome(e audit.Entry, region, resource string, err error) audit.Entry {
+	e.Region = region
+	e.Resource = resource
+	e.Result = audit.ResultOK
+	if err != nil {
+		e.Result = audit.ResultError
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// FetchAuditLogCmd reads the audit log, newest entry first.
+func FetchAuditLogCmd(log *audit.Log) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := log.Entries()
+		if err != nil {
+			return messages.ErrMsg(fmt.Errorf("failed to read audit log: %w", err))
+		}
+		slices.Reverse(entries)
+		return messages.AuditLogFetchedMsg(entries)
+	}
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxImageIDs is how many images BatchDeleteImage accepts per call.
+const maxImageIDs = 100
+
+// eachItem applies do to every id in turn, reporting progress after each, and
+// delivers the outcome for every id as a BulkActionMsg. For APIs that act on
+// one resource per call. Once ctx is done the remaining ids fail with its
+// error.
+func eachItem(ctx context.Context, region, action, noun string, ids []string, do func(id string) error) tea.Cmd {
+	results := make([]messages.BulkResult, 0, len(ids))
+	var step func(i int) tea.Cmd
+	step = func(i int) tea.Cmd {
+		return func() tea.Msg {
+			if i == len(ids) {
+				return messages.BulkActionMsg{Action: action, Results: results}
+			}
+			err := ctx.Err()
+			if err == nil {
+				err = do(ids[i])
+			}
+			results = append(results, messages.BulkResult{Region: region, ID: ids[i], Err: err})
+			if i+1 == len(ids) {
+				return messages.BulkActionMsg{Action: action, Results: results}
+			}
+			return messages.ProgressMsg{
+				Text: fmt.Sprintf("%d of %d %s %s", i+1, len(ids), noun, action),
+				Next: step(i + 1),
+			}
+		}
+	}
+	return step(0)
+}
+
+// StopInstancesCmd stops the given instances, keyed by region, with one
+// StopInstances call per region. A failed call fails every instance of its
+// region. Like StopInstanceCmd it does not wait for the instances to stop.
+func StopInstancesCmd(ctx context.Context, cs []*clients.Clients, ids map[string][]string) tea.Cmd {
+	return func() tea.Msg {
+		var results []messages.BulkResult
+		for _, c := range cs {
+			_, err := c.EC2.StopInstancesWithContext(ctx, &ec2.StopInstancesInput{
+				InstanceIds: aws.StringSlice(ids[c.Region]),
+			})
+			for _, id := range ids[c.Region] {
+				results = append(results, messages.BulkResult{Region: c.Region, ID: id, Err: err})
+			}
+		}
+		return messages.BulkActionMsg{Action: ec2.InstanceStateNameStopped, Results: results}
+	}
+}
+
+// StartInstancesCmd starts the given instances, keyed by region, with one
+// StartInstances call per region. A failed call fails every instance of its
+// region. Like StartInstanceCmd it does not wait for the instances to start.
+func StartInstancesCmd(ctx context.Context, cs []*clients.Clients, ids map[string][]string) tea.Cmd {
+	return func() tea.Msg {
+		var results []messages.BulkResult
+		for _, c := range cs {
+			_, err := c.EC2.StartInstancesWithContext(ctx, &ec2.StartInstancesInput{
+				InstanceIds: aws.StringSlice(ids[c.Region]),
+			})
+			for _, id := range ids[c.Region] {
+				results = append(results, messages.BulkResult{Region: c.Region, ID: id, Err: err})
+			}
+		}
+		return messages.BulkActionMsg{Action: ec2.InstanceStateNameRunning, Results: results}
+	}
+}
+
+// ForceDeployECSServicesCmd forces a new deployment of each of the given
+// services of a cluster, one after the other.
+func ForceDeployECSServicesCmd(ctx context.Context, c *clients.Clients, clusterArn string, serviceArns []string) tea.Cmd {
+	return eachItem(ctx, c.Region, "force-deployed", "services", serviceArns, func(serviceArn string) error {
+		_, err := c.ECS.UpdateServiceWithContext(ctx, &ecs.UpdateServiceInput{
+			Cluster:            aws.String(clusterArn),
+			Service:            aws.String(serviceArn),
+			ForceNewDeployment: aws.Bool(true),
+		})
+		return err
+	})
+}
+
+// StopBatchJobsCmd terminates each of the given Batch jobs, one after the
+// other.
+func StopBatchJobsCmd(ctx context.Context, c *clients.Clients, jobIDs []string, reason string) tea.Cmd {
+	return eachItem(ctx, c.Region, "stopped", "jobs", jobIDs, func(jobID string) error {
+		_, err := c.Batch.TerminateJobWithContext(ctx, &batch.TerminateJobInput{
+			JobId:  aws.String(jobID),
+			Reason: aws.String(reason),
+		})
+		return err
+	})
+}
+
+// DeleteEcrImagesCmd deletes the images with the given digests from a
+// repository, with as few BatchDeleteImage calls as possible. Images the call
+// could not delete are reported with the reason ECR gives.
+func DeleteEcrImagesCmd(ctx context.Context, c *clients.Clients, repositoryName string, digests []string) tea.Cmd {
+	return func() tea.Msg {
+		var results []messages.BulkResult
+		for _, part := range chunk(digests, maxImageIDs) {
+			ids := make([]*ecr.ImageIdentifier, len(part))
+			for i, digest := range part {
+				ids[i] = &ecr.ImageIdentifier{ImageDigest: aws.String(digest)}
+			}
+			out, err := c.ECR.BatchDeleteImageWithContext(ctx, &ecr.BatchDeleteImageInput{
+				RepositoryName: aws.String(repositoryName),
+				ImageIds:       ids,
+			})
+			failures := map[string]error{}
+			if err == nil {
+				for _, f := range out.Failures {
+					if f.ImageId == nil {
+						continue
+					}
+					failures[aws.StringValue(f.ImageId.ImageDigest)] = fmt.Errorf("%s: %s", aws.StringValue(f.FailureCode), aws.StringValue(f.FailureReason))
+				}
+			}
+			for _, digest := range part {
+				result := messages.BulkResult{Region: c.Region, ID: digest, Err: err}
+				if result.Err == nil {
+					result.Err = failures[digest]
+				}
+				results = append(results, result)
+			}
+		}
+		return messages.BulkActionMsg{Action: "deleted", Results: results}
+	}
+}
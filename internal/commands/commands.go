@@ -1,129 +1,289 @@
 package commands
 
 import (
+	"bufio"
+	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os/exec"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/utils"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/batch/batchiface"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
 	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// FetchECRRepositoriesCmd fetches ECR repositories from AWS.
-func FetchECRRepositoriesCmd(svc *ecr.ECR) tea.Cmd {
+// eachRegion starts one page chain per region, each beginning with the page
+// built by first.
+func eachRegion(cs []*clients.Clients, first func(*clients.Clients) tea.Cmd) tea.Cmd {
+	cmds := make([]tea.Cmd, len(cs))
+	for i, c := range cs {
+		cmds[i] = first(c)
+	}
+	return tea.Batch(cmds...)
+}
+
+// nextPage returns the command that fetches the page after token, or nil when
+// the listing is complete.
+func nextPage(token *string, fetch func(token *string) tea.Cmd) tea.Cmd {
+	if aws.StringValue(token) == "" {
+		return nil
+	}
+	return fetch(token)
+}
+
+// regionErr wraps the error of a page fetched from a region.
+func regionErr(c *clients.Clients, action string, err error) error {
+	return fmt.Errorf("failed to %s in %s: %w", action, c.Region, err)
+}
+
+// chunk splits items into slices of at most size elements, for Describe
+// calls that limit how many resources may be requested at once.
+func chunk[T any](items []T, size int) [][]T {
+	var chunks [][]T
+	for size < len(items) {
+		items, chunks = items[size:], append(chunks, items[:size])
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
+
+// FetchRegionsCmd fetches the regions enabled for the account.
+func FetchRegionsCmd(ctx context.Context, svc ec2iface.EC2API) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.DescribeRepositories(&ecr.DescribeRepositoriesInput{})
+		result, err := svc.DescribeRegionsWithContext(ctx, &ec2.DescribeRegionsInput{})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to describe ECR repositories: %w", err))
+			return messages.ErrMsg(fmt.Errorf("failed to describe regions: %w", err))
 		}
-		return messages.EcrRepositoriesFetchedMsg(result.Repositories)
+		var regions []string
+		for _, r := range result.Regions {
+			regions = append(regions, aws.StringValue(r.RegionName))
+		}
+		sort.Strings(regions)
+		return messages.RegionsFetchedMsg(regions)
 	}
 }
 
-// FetchECRImagesCmd fetches ECR images from a specific repository.
-func FetchECRImagesCmd(svc *ecr.ECR, repositoryName *string) tea.Cmd {
+// FetchCallerIdentityCmd fetches the account and principal behind the
+// clients of profile.
+func FetchCallerIdentityCmd(ctx context.Context, svc stsiface.STSAPI, profile string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.DescribeImages(&ecr.DescribeImagesInput{
-			RepositoryName: repositoryName,
-		})
+		result, err := svc.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to describe ECR images: %w", err))
+			return messages.ErrMsg(fmt.Errorf("failed to get caller identity: %w", err))
+		}
+		return messages.CallerIdentityMsg{
+			Profile: profile,
+			Account: aws.StringValue(result.Account),
+			Arn:     aws.StringValue(result.Arn),
 		}
-		return messages.EcrImagesFetchedMsg(result.ImageDetails)
 	}
 }
 
-// PullEcrImageCmd pulls a docker image from ECR.
-func PullEcrImageCmd(svc *ecr.ECR, repositoryUri string, imageTag string) tea.Cmd {
+// FetchECRRepositoriesCmd fetches ECR repositories from every given region,
+// one page at a time.
+func FetchECRRepositoriesCmd(ctx context.Context, cs []*clients.Clients) tea.Cmd {
+	return eachRegion(cs, func(c *clients.Clients) tea.Cmd { return fetchECRRepositoriesPage(ctx, c, nil) })
+}
+
+func fetchECRRepositoriesPage(ctx context.Context, c *clients.Clients, token *string) tea.Cmd {
 	return func() tea.Msg {
-		// Get login token
-		result, err := svc.GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{})
+		msg := messages.EcrRepositoriesFetchedMsg{Region: c.Region}
+		result, err := c.ECR.DescribeRepositoriesWithContext(ctx, &ecr.DescribeRepositoriesInput{
+			MaxResults: aws.Int64(1000),
+			NextToken:  token,
+		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to get ECR authorization token: %w", err))
+			msg.Err = regionErr(c, "describe ECR repositories", err)
+			return msg
 		}
-		// Decode token
-		token, err := base64.StdEncoding.DecodeString(aws.StringValue(result.AuthorizationData[0].AuthorizationToken))
+		msg.Repositories = result.Repositories
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchECRRepositoriesPage(ctx, c, token) })
+		return msg
+	}
+}
+
+// FetchECRImagesCmd fetches ECR images from a specific repository, one page
+// at a time.
+func FetchECRImagesCmd(ctx context.Context, svc ecriface.ECRAPI, repositoryName *string) tea.Cmd {
+	return fetchECRImagesPage(ctx, svc, repositoryName, nil)
+}
+
+func fetchECRImagesPage(ctx context.Context, svc ecriface.ECRAPI, repositoryName, token *string) tea.Cmd {
+	return func() tea.Msg {
+		var msg messages.EcrImagesFetchedMsg
+		result, err := svc.DescribeImagesWithContext(ctx, &ecr.DescribeImagesInput{
+			RepositoryName: repositoryName,
+			MaxResults:     aws.Int64(1000),
+			NextToken:      token,
+		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to decode ECR authorization token: %w", err))
-		}
-		// Login to ECR
-		loginCmd := exec.Command("docker", "login", "-u", "AWS", "-p", string(token)[4:], repositoryUri)
-		if err := loginCmd.Run(); err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to login to ECR: %w", err))
+			msg.Err = fmt.Errorf("failed to describe ECR images: %w", err)
+			return msg
 		}
-		// Pull image
-		imageName := fmt.Sprintf("%s:%s", repositoryUri, imageTag)
-		pullCmd := exec.Command("docker", "pull", imageName)
-		if err := pullCmd.Run(); err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to pull docker image: %w", err))
-		}
-		return messages.EcrImageActionMsg("pulled")
+		msg.Images = result.ImageDetails
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchECRImagesPage(ctx, svc, repositoryName, token) })
+		return msg
+	}
+}
+
+// ecrLogin logs docker in to the registry of repositoryUri.
+func ecrLogin(ctx context.Context, svc ecriface.ECRAPI, repositoryUri string) error {
+	// Get login token
+	result, err := svc.GetAuthorizationTokenWithContext(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return fmt.Errorf("failed to get ECR authorization token: %w", err)
 	}
+	// Decode token
+	token, err := base64.StdEncoding.DecodeString(aws.StringValue(result.AuthorizationData[0].AuthorizationToken))
+	if err != nil {
+		return fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+	// Login to ECR
+	loginCmd := exec.CommandContext(ctx, "docker", "login", "-u", "AWS", "-p", string(token)[4:], repositoryUri)
+	if err := loginCmd.Run(); err != nil {
+		return fmt.Errorf("failed to login to ECR: %w", err)
+	}
+	return nil
 }
 
-// PushEcrImageCmd pushes a docker image to ECR.
-func PushEcrImageCmd(svc *ecr.ECR, repositoryUri string, imageTag string) tea.Cmd {
+// PullEcrImageCmd pulls a docker image from ECR, reporting docker's output
+// as progress.
+func PullEcrImageCmd(ctx context.Context, svc ecriface.ECRAPI, repositoryUri string, ref string) tea.Cmd {
 	return func() tea.Msg {
-		// Get login token
-		result, err := svc.GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{})
-		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to get ECR authorization token: %w", err))
+		if err := ecrLogin(ctx, svc, repositoryUri); err != nil {
+			return messages.ErrMsg(err)
 		}
-		// Decode token
-		token, err := base64.StdEncoding.DecodeString(aws.StringValue(result.AuthorizationData[0].AuthorizationToken))
-		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to decode ECR authorization token: %w", err))
+		imageName := utils.ImageName(repositoryUri, ref)
+		return stream(exec.CommandContext(ctx, "docker", "pull", imageName), func(err error) tea.Msg {
+			if err != nil {
+				return messages.ErrMsg(fmt.Errorf("failed to pull docker image: %w", err))
+			}
+			return messages.EcrImageActionMsg("pulled")
+		})
+	}
+}
+
+// PushEcrImageCmd pushes a docker image to ECR, reporting docker's output as
+// progress.
+func PushEcrImageCmd(ctx context.Context, svc ecriface.ECRAPI, repositoryUri string, ref string) tea.Cmd {
+	return func() tea.Msg {
+		if err := ecrLogin(ctx, svc, repositoryUri); err != nil {
+			return messages.ErrMsg(err)
 		}
-		// Login to ECR
-		loginCmd := exec.Command("docker", "login", "-u", "AWS", "-p", string(token)[4:], repositoryUri)
-		if err := loginCmd.Run(); err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to login to ECR: %w", err))
+		imageName := utils.ImageName(repositoryUri, ref)
+		return stream(exec.CommandContext(ctx, "docker", "push", imageName), func(err error) tea.Msg {
+			if err != nil {
+				return messages.ErrMsg(fmt.Errorf("failed to push docker image: %w", err))
+			}
+			return messages.EcrImageActionMsg("pushed")
+		})
+	}
+}
+
+// stream starts c and delivers the last line it printed as progress until
+// it exits, when done turns its error into the result. The error includes
+// the last line, which usually says what went wrong.
+func stream(c *exec.Cmd, done func(error) tea.Msg) tea.Msg {
+	r, w := io.Pipe()
+	c.Stdout, c.Stderr = w, w
+	if err := c.Start(); err != nil {
+		return done(err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- c.Wait()
+		w.Close()
+	}()
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	var last string
+	var next tea.Cmd
+	next = func() tea.Msg {
+		line, ok := <-lines
+		for ok {
+			if line = strings.TrimSpace(line); line != "" {
+				last = line
+			}
+			// Skip to the latest line, docker prints them in bursts.
+			select {
+			case line, ok = <-lines:
+				continue
+			default:
+			}
+			return messages.ProgressMsg{Text: last, Next: next}
 		}
-		// Push image
-		imageName := fmt.Sprintf("%s:%s", repositoryUri, imageTag)
-		pushCmd := exec.Command("docker", "push", imageName)
-		if err := pushCmd.Run(); err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to push docker image: %w", err))
+		err := <-exited
+		if err != nil && last != "" {
+			err = fmt.Errorf("%w: %s", err, last)
 		}
-		return messages.EcrImageActionMsg("pushed")
+		return done(err)
 	}
+	return next()
 }
 
-// FetchInstancesCmd fetches EC2 instances from AWS.
-func FetchInstancesCmd(svc *ec2.EC2) tea.Cmd {
+// FetchInstancesCmd fetches EC2 instances from every given region, one page
+// at a time.
+func FetchInstancesCmd(ctx context.Context, cs []*clients.Clients) tea.Cmd {
+	return eachRegion(cs, func(c *clients.Clients) tea.Cmd { return fetchInstancesPage(ctx, c, nil) })
+}
+
+func fetchInstancesPage(ctx context.Context, c *clients.Clients, token *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.DescribeInstances(&ec2.DescribeInstancesInput{})
+		msg := messages.InstancesFetchedMsg{Region: c.Region}
+		result, err := c.EC2.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+			MaxResults: aws.Int64(1000),
+			NextToken:  token,
+		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to describe instances: %w", err))
+			msg.Err = regionErr(c, "describe instances", err)
+			return msg
 		}
-		var instances []*ec2.Instance
 		for _, reservation := range result.Reservations {
 			for _, instance := range reservation.Instances {
 				if *instance.State.Name != ec2.InstanceStateNameTerminated {
-					instances = append(instances, instance)
+					msg.Instances = append(msg.Instances, instance)
 				}
 			}
 		}
-		return messages.InstancesFetchedMsg(instances)
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchInstancesPage(ctx, c, token) })
+		return msg
 	}
 }
 
 // FetchInstanceDetailsCmd fetches details for a specific EC2 instance.
-func FetchInstanceDetailsCmd(svc *ec2.EC2, instanceID *string) tea.Cmd {
+func FetchInstanceDetailsCmd(ctx context.Context, svc ec2iface.EC2API, instanceID *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.DescribeInstances(&ec2.DescribeInstancesInput{
+		result, err := svc.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
 			InstanceIds: []*string{instanceID},
 		})
 		if err != nil {
@@ -137,87 +297,107 @@ func FetchInstanceDetailsCmd(svc *ec2.EC2, instanceID *string) tea.Cmd {
 	}
 }
 
-// StopInstanceCmd stops a specific EC2 instance.
-func StopInstanceCmd(svc *ec2.EC2, instanceID *string) tea.Cmd {
+// StopInstanceCmd stops a specific EC2 instance. It reports the state the
+// instance is moving to without waiting for it; see WaitForInstanceCmd.
+func StopInstanceCmd(ctx context.Context, svc ec2iface.EC2API, instanceID *string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := svc.StopInstances(&ec2.StopInstancesInput{
+		_, err := svc.StopInstancesWithContext(ctx, &ec2.StopInstancesInput{
 			InstanceIds: []*string{instanceID},
 		})
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to stop instance %s: %w", *instanceID, err))
 		}
-		time.Sleep(2 * time.Second)
-		return messages.InstanceActionMsg("stopped")
+		return messages.InstanceActionMsg(ec2.InstanceStateNameStopped)
 	}
 }
 
-// StartInstanceCmd starts a specific EC2 instance.
-func StartInstanceCmd(svc *ec2.EC2, instanceID *string) tea.Cmd {
+// StartInstanceCmd starts a specific EC2 instance. It reports the state the
+// instance is moving to without waiting for it; see WaitForInstanceCmd.
+func StartInstanceCmd(ctx context.Context, svc ec2iface.EC2API, instanceID *string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := svc.StartInstances(&ec2.StartInstancesInput{
+		_, err := svc.StartInstancesWithContext(ctx, &ec2.StartInstancesInput{
 			InstanceIds: []*string{instanceID},
 		})
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to start instance %s: %w", *instanceID, err))
 		}
-		time.Sleep(2 * time.Second)
-		return messages.InstanceActionMsg("started")
+		return messages.InstanceActionMsg(ec2.InstanceStateNameRunning)
 	}
 }
 
-// FetchECSClustersCmd fetches ECS clusters from AWS.
-func FetchECSClustersCmd(svc *ecs.ECS) tea.Cmd {
-	return func() tea.Msg {
-		listResult, err := svc.ListClusters(&ecs.ListClustersInput{})
-		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to list ECS clusters: %w", err))
-		}
-
-		if len(listResult.ClusterArns) == 0 {
-			return messages.EcsClustersFetchedMsg([]*ecs.Cluster{})
-		}
+// FetchECSClustersCmd fetches ECS clusters from every given region, one page
+// at a time.
+func FetchECSClustersCmd(ctx context.Context, cs []*clients.Clients) tea.Cmd {
+	return eachRegion(cs, func(c *clients.Clients) tea.Cmd { return fetchECSClustersPage(ctx, c, nil) })
+}
 
-		describeResult, err := svc.DescribeClusters(&ecs.DescribeClustersInput{
-			Clusters: listResult.ClusterArns,
+func fetchECSClustersPage(ctx context.Context, c *clients.Clients, token *string) tea.Cmd {
+	return func() tea.Msg {
+		msg := messages.EcsClustersFetchedMsg{Region: c.Region}
+		listResult, err := c.ECS.ListClustersWithContext(ctx, &ecs.ListClustersInput{
+			MaxResults: aws.Int64(100),
+			NextToken:  token,
 		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to describe ECS clusters: %w", err))
+			msg.Err = regionErr(c, "list ECS clusters", err)
+			return msg
 		}
 
-		return messages.EcsClustersFetchedMsg(describeResult.Clusters)
+		for _, arns := range chunk(listResult.ClusterArns, 100) {
+			describeResult, err := c.ECS.DescribeClustersWithContext(ctx, &ecs.DescribeClustersInput{
+				Clusters: arns,
+			})
+			if err != nil {
+				msg.Err = regionErr(c, "describe ECS clusters", err)
+				return msg
+			}
+			msg.Clusters = append(msg.Clusters, describeResult.Clusters...)
+		}
+		msg.Next = nextPage(listResult.NextToken, func(token *string) tea.Cmd { return fetchECSClustersPage(ctx, c, token) })
+		return msg
 	}
 }
 
-// FetchECSServicesCmd fetches ECS services for a given cluster ARN.
-func FetchECSServicesCmd(svc *ecs.ECS, clusterArn string) tea.Cmd {
+// FetchECSServicesCmd fetches ECS services for a given cluster ARN, one page
+// at a time.
+func FetchECSServicesCmd(ctx context.Context, svc ecsiface.ECSAPI, clusterArn string) tea.Cmd {
+	return fetchECSServicesPage(ctx, svc, clusterArn, nil)
+}
+
+func fetchECSServicesPage(ctx context.Context, svc ecsiface.ECSAPI, clusterArn string, token *string) tea.Cmd {
 	return func() tea.Msg {
-		listResult, err := svc.ListServices(&ecs.ListServicesInput{
-			Cluster: aws.String(clusterArn),
+		var msg messages.EcsServicesFetchedMsg
+		listResult, err := svc.ListServicesWithContext(ctx, &ecs.ListServicesInput{
+			Cluster:    aws.String(clusterArn),
+			MaxResults: aws.Int64(100),
+			NextToken:  token,
 		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to list ECS services for cluster %s: %w", clusterArn, err))
-		}
-
-		if len(listResult.ServiceArns) == 0 {
-			return messages.EcsServicesFetchedMsg([]*ecs.Service{})
+			msg.Err = fmt.Errorf("failed to list ECS services for cluster %s: %w", clusterArn, err)
+			return msg
 		}
 
-		describeResult, err := svc.DescribeServices(&ecs.DescribeServicesInput{
-			Cluster:  aws.String(clusterArn),
-			Services: listResult.ServiceArns,
-		})
-		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to describe ECS services for cluster %s: %w", clusterArn, err))
+		// DescribeServices accepts at most 10 services per call.
+		for _, arns := range chunk(listResult.ServiceArns, 10) {
+			describeResult, err := svc.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+				Cluster:  aws.String(clusterArn),
+				Services: arns,
+			})
+			if err != nil {
+				msg.Err = fmt.Errorf("failed to describe ECS services for cluster %s: %w", clusterArn, err)
+				return msg
+			}
+			msg.Services = append(msg.Services, describeResult.Services...)
 		}
-
-		return messages.EcsServicesFetchedMsg(describeResult.Services)
+		msg.Next = nextPage(listResult.NextToken, func(token *string) tea.Cmd { return fetchECSServicesPage(ctx, svc, clusterArn, token) })
+		return msg
 	}
 }
 
 // FetchECSServiceDetailsCmd fetches details for a specific ECS service.
-func FetchECSServiceDetailsCmd(svc *ecs.ECS, clusterArn, serviceArn string) tea.Cmd {
+func FetchECSServiceDetailsCmd(ctx context.Context, svc ecsiface.ECSAPI, clusterArn, serviceArn string) tea.Cmd {
 	return func() tea.Msg {
-		describeResult, err := svc.DescribeServices(&ecs.DescribeServicesInput{
+		describeResult, err := svc.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
 			Cluster:  aws.String(clusterArn),
 			Services: []*string{aws.String(serviceArn)},
 		})
@@ -232,10 +412,42 @@ func FetchECSServiceDetailsCmd(svc *ecs.ECS, clusterArn, serviceArn string) tea.
 	}
 }
 
+// FetchECSTaskCmd fetches details for a task of a cluster, such as one
+// started by a Step Functions execution.
+func FetchECSTaskCmd(ctx context.Context, svc ecsiface.ECSAPI, cluster, taskArn string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := svc.DescribeTasksWithContext(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   []*string{aws.String(taskArn)},
+		})
+		if err != nil {
+			return messages.ErrMsg(fmt.Errorf("failed to describe ECS task %s: %w", taskArn, err))
+		}
+		if len(result.Tasks) > 0 {
+			return messages.EcsTaskDetailsMsg(result.Tasks[0])
+		}
+		return messages.ErrMsg(fmt.Errorf("ECS task %s not found", taskArn))
+	}
+}
+
+// FetchECSTaskDefinitionCmd fetches a task definition by ARN, or by family
+// and revision.
+func FetchECSTaskDefinitionCmd(ctx context.Context, svc ecsiface.ECSAPI, taskDefinition string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := svc.DescribeTaskDefinitionWithContext(ctx, &ecs.DescribeTaskDefinitionInput{
+			TaskDefinition: aws.String(taskDefinition),
+		})
+		if err != nil {
+			return messages.ErrMsg(fmt.Errorf("failed to describe task definition %s: %w", taskDefinition, err))
+		}
+		return messages.EcsTaskDefinitionMsg(result.TaskDefinition)
+	}
+}
+
 // StopECSServiceCmd updates the desired count of an ECS service to 0 to stop it.
-func StopECSServiceCmd(svc *ecs.ECS, clusterArn, serviceArn string) tea.Cmd {
+func StopECSServiceCmd(ctx context.Context, svc ecsiface.ECSAPI, clusterArn, serviceArn string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := svc.UpdateService(&ecs.UpdateServiceInput{
+		_, err := svc.UpdateServiceWithContext(ctx, &ecs.UpdateServiceInput{
 			Cluster:      aws.String(clusterArn),
 			Service:      aws.String(serviceArn),
 			DesiredCount: aws.Int64(0),
@@ -243,15 +455,14 @@ func StopECSServiceCmd(svc *ecs.ECS, clusterArn, serviceArn string) tea.Cmd {
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to stop ECS service %s: %w", serviceArn, err))
 		}
-		time.Sleep(2 * time.Second)
 		return messages.EcsServiceActionMsg("stopped")
 	}
 }
 
 // ForceDeployECSServiceCmd forces a new deployment of an ECS service.
-func ForceDeployECSServiceCmd(svc *ecs.ECS, clusterArn, serviceArn string) tea.Cmd {
+func ForceDeployECSServiceCmd(ctx context.Context, svc ecsiface.ECSAPI, clusterArn, serviceArn string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := svc.UpdateService(&ecs.UpdateServiceInput{
+		_, err := svc.UpdateServiceWithContext(ctx, &ecs.UpdateServiceInput{
 			Cluster:            aws.String(clusterArn),
 			Service:            aws.String(serviceArn),
 			ForceNewDeployment: aws.Bool(true),
@@ -259,13 +470,12 @@ func ForceDeployECSServiceCmd(svc *ecs.ECS, clusterArn, serviceArn string) tea.C
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to force deploy ECS service %s: %w", serviceArn, err))
 		}
-		time.Sleep(2 * time.Second)
 		return messages.EcsServiceActionMsg("force-deployed")
 	}
 }
 
 // FetchECSServiceLogsCmd fetches logs for a specific ECS service from CloudWatch Logs.
-func FetchECSServiceLogsCmd(ecsSvc *ecs.ECS, cloudwatchlogsSvc *cloudwatchlogs.CloudWatchLogs, service *ecs.Service) tea.Cmd {
+func FetchECSServiceLogsCmd(ctx context.Context, ecsSvc ecsiface.ECSAPI, cloudwatchlogsSvc cloudwatchlogsiface.CloudWatchLogsAPI, service *ecs.Service) tea.Cmd {
 	return func() tea.Msg {
 		var allLogs strings.Builder
 
@@ -274,13 +484,13 @@ func FetchECSServiceLogsCmd(ecsSvc *ecs.ECS, cloudwatchlogsSvc *cloudwatchlogs.C
 			return messages.ErrMsg(fmt.Errorf("service %s has no associated task definition", aws.StringValue(service.ServiceName)))
 		}
 
-		describeTaskDefResult, err := ecsSvc.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		describeTaskDefResult, err := ecsSvc.DescribeTaskDefinitionWithContext(ctx, &ecs.DescribeTaskDefinitionInput{
 			TaskDefinition: aws.String(taskDefinitionArn),
 		})
-		serviceName := strings.ReplaceAll(aws.StringValue(describeTaskDefResult.TaskDefinition.Family), "application-", "")
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to describe task definition %s for service %s: %w", taskDefinitionArn, aws.StringValue(service.ServiceName), err))
 		}
+		serviceName := strings.ReplaceAll(aws.StringValue(describeTaskDefResult.TaskDefinition.Family), "application-", "")
 
 		var logGroupName string
 		var logStreamPrefix string
@@ -304,7 +514,7 @@ func FetchECSServiceLogsCmd(ecsSvc *ecs.ECS, cloudwatchlogsSvc *cloudwatchlogs.C
 		twentyFourHoursAgo := time.Now().Add(-24 * time.Hour).UnixMilli()
 
 		streamNamePrefix := fmt.Sprintf("%s/%s", logStreamPrefix, serviceName)
-		logStreamsResult, err := cloudwatchlogsSvc.DescribeLogStreams(&cloudwatchlogs.DescribeLogStreamsInput{
+		logStreamsResult, err := cloudwatchlogsSvc.DescribeLogStreamsWithContext(ctx, &cloudwatchlogs.DescribeLogStreamsInput{
 			LogGroupName: aws.String(logGroupName),
 			// LogStreamNamePrefix: aws.String(streamNamePrefix),
 			Descending: aws.Bool(true),
@@ -328,13 +538,17 @@ func FetchECSServiceLogsCmd(ecsSvc *ecs.ECS, cloudwatchlogsSvc *cloudwatchlogs.C
 				Limit:         aws.Int64(50),
 			}
 
-			eventsResult, err := cloudwatchlogsSvc.GetLogEvents(getEventsInput)
+			eventsResult, err := cloudwatchlogsSvc.GetLogEventsWithContext(ctx, getEventsInput)
+			if ctx.Err() != nil {
+				return messages.ErrMsg(ctx.Err())
+			}
+			if err != nil {
+				allLogs.WriteString(fmt.Sprintf("--- Log Stream: %s ---\n", aws.StringValue(stream.LogStreamName)))
+				allLogs.WriteString(fmt.Sprintf("Error fetching events from %s: %v\n", aws.StringValue(stream.LogStreamName), err))
+				continue
+			}
 			if len(eventsResult.Events) > 0 {
 				allLogs.WriteString(fmt.Sprintf("--- Log Stream: %s ---\n", aws.StringValue(stream.LogStreamName)))
-				if err != nil {
-					allLogs.WriteString(fmt.Sprintf("Error fetching events from %s: %v\n", aws.StringValue(stream.LogStreamName), err))
-					continue
-				}
 
 				for _, event := range eventsResult.Events {
 					allLogs.WriteString(fmt.Sprintf("[%s] %s\n", time.UnixMilli(aws.Int64Value(event.Timestamp)).Format("15:04:05"), aws.StringValue(event.Message)))
@@ -358,127 +572,170 @@ func SshIntoInstanceCmd(publicIP string, keyName string) tea.Cmd {
 	})
 }
 
-// FetchSFNStateMachinesCmd fetches Step Functions state machines from AWS.
-func FetchSFNStateMachinesCmd(svc *sfn.SFN) tea.Cmd {
+// LoginCmd runs the command renewing the credentials of profile in the
+// terminal, since it may open a browser or ask for an MFA code.
+func LoginCmd(profile string, args []string) tea.Cmd {
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return messages.LoginDoneMsg{Profile: profile, Err: err}
+	})
+}
+
+// FetchSFNStateMachinesCmd fetches Step Functions state machines from every
+// given region, one page at a time.
+func FetchSFNStateMachinesCmd(ctx context.Context, cs []*clients.Clients) tea.Cmd {
+	return eachRegion(cs, func(c *clients.Clients) tea.Cmd { return fetchSFNStateMachinesPage(ctx, c, nil) })
+}
+
+func fetchSFNStateMachinesPage(ctx context.Context, c *clients.Clients, token *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.ListStateMachines(&sfn.ListStateMachinesInput{})
+		msg := messages.SfnStateMachinesFetchedMsg{Region: c.Region}
+		result, err := c.SFN.ListStateMachinesWithContext(ctx, &sfn.ListStateMachinesInput{
+			MaxResults: aws.Int64(1000),
+			NextToken:  token,
+		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to list state machines: %w", err))
+			msg.Err = regionErr(c, "list state machines", err)
+			return msg
 		}
-		return messages.SfnStateMachinesFetchedMsg(result.StateMachines)
+		msg.StateMachines = result.StateMachines
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchSFNStateMachinesPage(ctx, c, token) })
+		return msg
 	}
 }
 
-// FetchSFNExecutionsCmd fetches executions for a Step Functions state machine from AWS.
-func FetchSFNExecutionsCmd(svc *sfn.SFN, stateMachineArn *string) tea.Cmd {
+// FetchSFNExecutionsCmd fetches executions for a Step Functions state machine
+// from AWS, one page at a time.
+func FetchSFNExecutionsCmd(ctx context.Context, svc sfniface.SFNAPI, stateMachineArn *string) tea.Cmd {
+	return fetchSFNExecutionsPage(ctx, svc, stateMachineArn, nil)
+}
+
+func fetchSFNExecutionsPage(ctx context.Context, svc sfniface.SFNAPI, stateMachineArn, token *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.ListExecutions(&sfn.ListExecutionsInput{
+		var msg messages.SfnExecutionsFetchedMsg
+		result, err := svc.ListExecutionsWithContext(ctx, &sfn.ListExecutionsInput{
 			StateMachineArn: stateMachineArn,
+			MaxResults:      aws.Int64(1000),
+			NextToken:       token,
 		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to list executions: %w", err))
+			msg.Err = fmt.Errorf("failed to list executions: %w", err)
+			return msg
 		}
-		return messages.SfnExecutionsFetchedMsg(result.Executions)
+		msg.Executions = result.Executions
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchSFNExecutionsPage(ctx, svc, stateMachineArn, token) })
+		return msg
 	}
 }
 
-// FetchSFNExecutionHistoryCmd fetches the history of a Step Functions execution from AWS.
-func FetchSFNExecutionHistoryCmd(svc *sfn.SFN, executionArn *string) tea.Cmd {
+// FetchSFNExecutionHistoryCmd fetches the complete history of a Step Functions
+// execution from AWS. The history is only meaningful as a whole, so its pages
+// are collected before it is delivered.
+func FetchSFNExecutionHistoryCmd(ctx context.Context, svc sfniface.SFNAPI, executionArn *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.GetExecutionHistory(&sfn.GetExecutionHistoryInput{
-			ExecutionArn: executionArn,
-			MaxResults:   aws.Int64(1000), // Fetch up to 100 events
-		})
-		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to get execution history: %w", err))
+		var events []*sfn.HistoryEvent
+		var token *string
+		for {
+			result, err := svc.GetExecutionHistoryWithContext(ctx, &sfn.GetExecutionHistoryInput{
+				ExecutionArn: executionArn,
+				MaxResults:   aws.Int64(1000),
+				NextToken:    token,
+			})
+			if err != nil {
+				return messages.ErrMsg(fmt.Errorf("failed to get execution history: %w", err))
+			}
+			events = append(events, result.Events...)
+			if aws.StringValue(result.NextToken) == "" {
+				break
+			}
+			token = result.NextToken
 		}
-		return messages.SfnExecutionHistoryFetchedMsg(result.Events)
+		return messages.SfnExecutionHistoryFetchedMsg(events)
 	}
 }
 
-// StartSFNExecutionCmd starts a new execution for a Step Functions state machine.
-func StartSFNExecutionCmd(svc *sfn.SFN, stateMachineArn *string, input *string) tea.Cmd {
+// StartSFNExecutionCmd starts a new execution for a Step Functions state
+// machine and reports the ARN of the execution.
+func StartSFNExecutionCmd(ctx context.Context, svc sfniface.SFNAPI, stateMachineArn *string, input *string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := svc.StartExecution(&sfn.StartExecutionInput{
+		result, err := svc.StartExecutionWithContext(ctx, &sfn.StartExecutionInput{
 			StateMachineArn: stateMachineArn,
 			Input:           input,
 		})
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to start execution: %w", err))
 		}
-		return messages.SfnExecutionStartedMsg("Execution started successfully")
+		return messages.SfnExecutionStartedMsg(aws.StringValue(result.ExecutionArn))
 	}
 }
 
-// FetchBatchJobQueuesCmd fetches Batch job queues from AWS.
-func FetchBatchJobQueuesCmd(svc *batch.Batch) tea.Cmd {
+// FetchBatchJobQueuesCmd fetches Batch job queues from every given region,
+// one page at a time.
+func FetchBatchJobQueuesCmd(ctx context.Context, cs []*clients.Clients) tea.Cmd {
+	return eachRegion(cs, func(c *clients.Clients) tea.Cmd { return fetchBatchJobQueuesPage(ctx, c, nil) })
+}
+
+func fetchBatchJobQueuesPage(ctx context.Context, c *clients.Clients, token *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.DescribeJobQueues(&batch.DescribeJobQueuesInput{})
+		msg := messages.BatchJobQueuesFetchedMsg{Region: c.Region}
+		result, err := c.Batch.DescribeJobQueuesWithContext(ctx, &batch.DescribeJobQueuesInput{
+			MaxResults: aws.Int64(100),
+			NextToken:  token,
+		})
 		if err != nil {
-			return messages.ErrMsg(fmt.Errorf("failed to describe Batch job queues: %w", err))
+			msg.Err = regionErr(c, "describe Batch job queues", err)
+			return msg
 		}
-		return messages.BatchJobQueuesFetchedMsg(result.JobQueues)
+		msg.JobQueues = result.JobQueues
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchBatchJobQueuesPage(ctx, c, token) })
+		return msg
 	}
 }
 
-// FetchBatchJobsCmd fetches Batch jobs from a specific job queue for all statuses.
-func FetchBatchJobsCmd(svc *batch.Batch, jobQueue *string) tea.Cmd {
-	return func() tea.Msg {
-		statuses := []string{
-			"SUBMITTED",
-			"PENDING",
-			"RUNNABLE",
-			"STARTING",
-			"RUNNING",
-			"SUCCEEDED",
-			"FAILED",
-		}
-
-		var jobs []*batch.JobSummary
-		var errs []error
-		var wg sync.WaitGroup
-		var mu sync.Mutex
-
-		for _, status := range statuses {
-			wg.Add(1)
-			go func(status string) {
-				defer wg.Done()
-				result, err := svc.ListJobs(&batch.ListJobsInput{
-					JobQueue:  jobQueue,
-					JobStatus: aws.String(status),
-				})
-				mu.Lock()
-				defer mu.Unlock()
-				if err != nil {
-					errs = append(errs, err)
-					return
-				}
-				jobs = append(jobs, result.JobSummaryList...)
-			}(status)
-		}
-
-		wg.Wait()
+// BatchJobStatuses are the job statuses FetchBatchJobsCmd lists. Each status
+// is listed by its own page chain.
+var BatchJobStatuses = []string{
+	batch.JobStatusSubmitted,
+	batch.JobStatusPending,
+	batch.JobStatusRunnable,
+	batch.JobStatusStarting,
+	batch.JobStatusRunning,
+	batch.JobStatusSucceeded,
+	batch.JobStatusFailed,
+}
 
-		if len(errs) > 0 {
-			// For now, just return the first error.
-			return messages.ErrMsg(fmt.Errorf("failed to list Batch jobs: %w", errs[0]))
-		}
+// FetchBatchJobsCmd fetches Batch jobs from a specific job queue for all
+// statuses, one page at a time.
+func FetchBatchJobsCmd(ctx context.Context, svc batchiface.BatchAPI, jobQueue *string) tea.Cmd {
+	cmds := make([]tea.Cmd, len(BatchJobStatuses))
+	for i, status := range BatchJobStatuses {
+		cmds[i] = fetchBatchJobsPage(ctx, svc, jobQueue, status, nil)
+	}
+	return tea.Batch(cmds...)
+}
 
-		sort.Slice(jobs, func(i, j int) bool {
-			if jobs[i].CreatedAt == nil || jobs[j].CreatedAt == nil {
-				return false
-			}
-			return *jobs[i].CreatedAt > *jobs[j].CreatedAt // descending
+func fetchBatchJobsPage(ctx context.Context, svc batchiface.BatchAPI, jobQueue *string, status string, token *string) tea.Cmd {
+	return func() tea.Msg {
+		var msg messages.BatchJobsFetchedMsg
+		result, err := svc.ListJobsWithContext(ctx, &batch.ListJobsInput{
+			JobQueue:   jobQueue,
+			JobStatus:  aws.String(status),
+			MaxResults: aws.Int64(1000),
+			NextToken:  token,
 		})
-
-		return messages.BatchJobsFetchedMsg(jobs)
+		if err != nil {
+			msg.Err = fmt.Errorf("failed to list %s Batch jobs: %w", status, err)
+			return msg
+		}
+		msg.Jobs = result.JobSummaryList
+		msg.Next = nextPage(result.NextToken, func(token *string) tea.Cmd { return fetchBatchJobsPage(ctx, svc, jobQueue, status, token) })
+		return msg
 	}
 }
 
 // FetchBatchJobDetailsCmd fetches details for a specific Batch job.
-func FetchBatchJobDetailsCmd(svc *batch.Batch, jobID *string) tea.Cmd {
+func FetchBatchJobDetailsCmd(ctx context.Context, svc batchiface.BatchAPI, jobID *string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := svc.DescribeJobs(&batch.DescribeJobsInput{
+		result, err := svc.DescribeJobsWithContext(ctx, &batch.DescribeJobsInput{
 			Jobs: []*string{jobID},
 		})
 		if err != nil {
@@ -492,22 +749,21 @@ func FetchBatchJobDetailsCmd(svc *batch.Batch, jobID *string) tea.Cmd {
 }
 
 // StopBatchJobCmd stops a specific Batch job.
-func StopBatchJobCmd(svc *batch.Batch, jobID *string, reason *string) tea.Cmd {
+func StopBatchJobCmd(ctx context.Context, svc batchiface.BatchAPI, jobID *string, reason *string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := svc.TerminateJob(&batch.TerminateJobInput{
+		_, err := svc.TerminateJobWithContext(ctx, &batch.TerminateJobInput{
 			JobId:  jobID,
 			Reason: reason,
 		})
 		if err != nil {
 			return messages.ErrMsg(fmt.Errorf("failed to stop Batch job %s: %w", *jobID, err))
 		}
-		time.Sleep(2 * time.Second)
 		return messages.BatchJobActionMsg("stopped")
 	}
 }
 
 // FetchBatchJobLogsCmd fetches logs for a specific Batch job from CloudWatch Logs.
-func FetchBatchJobLogsCmd(svc *cloudwatchlogs.CloudWatchLogs, logStreamName *string) tea.Cmd {
+func FetchBatchJobLogsCmd(ctx context.Context, svc cloudwatchlogsiface.CloudWatchLogsAPI, logStreamName *string) tea.Cmd {
 	return func() tea.Msg {
 		var allLogs strings.Builder
 		// The log group for AWS Batch jobs is typically this, but you can
@@ -528,7 +784,7 @@ func FetchBatchJobLogsCmd(svc *cloudwatchlogs.CloudWatchLogs, logStreamName *str
 				StartFromHead: aws.Bool(true),
 			}
 
-			eventsResult, err := svc.GetLogEvents(getEventsInput)
+			eventsResult, err := svc.GetLogEventsWithContext(ctx, getEventsInput)
 			if err != nil {
 				return messages.ErrMsg(fmt.Errorf("failed to get log events for stream %s: %w", *logStreamName, err))
 			}
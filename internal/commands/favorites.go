@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/favorites"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchFavoritesCmd reads the favorites of profile.
+func FetchFavoritesCmd(store *favorites.Store, profile string) tea.Cmd {
+	return func() tea.Msg {
+		list, err := store.List(profile)
+		if err != nil {
+			return messages.ErrMsg(err)
+		}
+		return messages.FavoritesFetchedMsg(list)
+	}
+}
+
+// FetchFavoriteStatusCmd looks up the current status of a favorite with the
+// clients of its region: the state of an instance, the task counts of a
+// service, the image count of a repository, the status of the latest
+// execution of a state machine and the state of a job queue.
+func FetchFavoriteStatusCmd(ctx context.Context, c *clients.Clients, f favorites.Favorite) tea.Cmd {
+	return func() tea.Msg {
+		status, err := favoriteStatus(ctx, c, f)
+		return messages.FavoriteStatusMsg{Key: f.Key, Status: status, Err: err}
+	}
+}
+
+func favoriteStatus(ctx context.Context, c *clients.Clients, f favorites.Favorite) (string, error) {
+	switch f.Service {
+	case favorites.ServiceEC2:
+		result, err := c.EC2.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: []*string{aws.String(f.Path[0])},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe instance %s: %w", f.Path[0], err)
+		}
+		if len(result.Reservations) > 0 && len(result.Reservations[0].Instances) > 0 {
+			return aws.StringValue(result.Reservations[0].Instances[0].State.Name), nil
+		}
+		return "", fmt.Errorf("instance %s not found", f.Path[0])
+	case favorites.ServiceECS:
+		result, err := c.ECS.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+			Cluster:  aws.String(f.Path[0]),
+			Services: []*string{aws.String(f.Path[1])},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe ECS service %s: %w", f.Name, err)
+		}
+		if len(result.Services) == 0 {
+			return "", fmt.Errorf("ECS service %s not found", f.Name)
+		}
+		s := result.Services[0]
+		return fmt.Sprintf("%s, %d/%d running", aws.StringValue(s.Status), aws.Int64Value(s.RunningCount), aws.Int64Value(s.DesiredCount)), nil
+	case favorites.ServiceECR:
+		result, err := c.ECR.DescribeImagesWithContext(ctx, &ecr.DescribeImagesInput{
+			RepositoryName: aws.String(f.Name),
+			MaxResults:     aws.Int64(1000),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe images of %s: %w", f.Name, err)
+		}
+		if aws.StringValue(result.NextToken) != "" {
+			return fmt.Sprintf("%d+ images", len(result.ImageDetails)), nil
+		}
+		return fmt.Sprintf("%d images", len(result.ImageDetails)), nil
+	case favorites.ServiceSFN:
+		result, err := c.SFN.ListExecutionsWithContext(ctx, &sfn.ListExecutionsInput{
+			StateMachineArn: aws.String(f.Path[0]),
+			MaxResults:      aws.Int64(1),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list executions of %s: %w", f.Name, err)
+		}
+		if len(result.Executions) == 0 {
+			return "no executions", nil
+		}
+		return "last execution " + aws.StringValue(result.Executions[0].Status), nil
+	case favorites.ServiceBatch:
+		result, err := c.Batch.DescribeJobQueuesWithContext(ctx, &batch.DescribeJobQueuesInput{
+			JobQueues: []*string{aws.String(f.Path[0])},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe job queue %s: %w", f.Name, err)
+		}
+		for _, q := range result.JobQueues {
+			if aws.StringValue(q.JobQueueArn) == f.Path[0] {
+				return fmt.Sprintf("%s, %s", aws.StringValue(q.State), aws.StringValue(q.Status)), nil
+			}
+		}
+		return "", fmt.Errorf("job queue %s not found", f.Name)
+	}
+	return "", fmt.Errorf("unknown service %q", f.Service)
+}
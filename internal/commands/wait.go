@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/batch/batchiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// WaitInterval is how long waiters pause between polls, and WaitTimeout how
+// long they keep polling before giving up.
+var (
+	WaitInterval = 3 * time.Second
+	WaitTimeout  = 10 * time.Minute
+)
+
+// wait polls a resource until settled reports that it reached a terminal
+// state. Every observation is delivered through msg so the UI can update the
+// resource while it transitions; the message carries the command for the next
+// poll until the resource settles, fails or the timeout expires.
+func wait[T any](describe func() (T, error), settled func(T) (bool, error), msg func(T, messages.Wait) tea.Msg) tea.Cmd {
+	deadline := time.Now().Add(WaitTimeout)
+	var poll tea.Cmd
+	poll = func() tea.Msg {
+		resource, err := describe()
+		if err != nil {
+			return msg(resource, messages.Wait{Err: err})
+		}
+		done, err := settled(resource)
+		if done || err != nil {
+			return msg(resource, messages.Wait{Err: err})
+		}
+		if time.Now().After(deadline) {
+			return msg(resource, messages.Wait{Err: fmt.Errorf("timed out after %s", WaitTimeout)})
+		}
+		return msg(resource, messages.Wait{Next: tea.Tick(WaitInterval, func(time.Time) tea.Msg { return poll() })})
+	}
+	return poll
+}
+
+// WaitForInstanceCmd polls an EC2 instance until it reaches state, which is
+// either running or stopped.
+func WaitForInstanceCmd(ctx context.Context, svc ec2iface.EC2API, region string, instanceID *string, state string) tea.Cmd {
+	return wait(
+		func() (*ec2.Instance, error) {
+			result, err := svc.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+				InstanceIds: []*string{instanceID},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe instance %s: %w", *instanceID, err)
+			}
+			if len(result.Reservations) == 0 || len(result.Reservations[0].Instances) == 0 {
+				return nil, fmt.Errorf("instance %s not found", *instanceID)
+			}
+			return result.Reservations[0].Instances[0], nil
+		},
+		func(instance *ec2.Instance) (bool, error) {
+			current := aws.StringValue(instance.State.Name)
+			switch current {
+			case state:
+				return true, nil
+			case ec2.InstanceStateNameShuttingDown, ec2.InstanceStateNameTerminated:
+				return false, fmt.Errorf("instance %s is %s", *instanceID, current)
+			}
+			return false, nil
+		},
+		func(instance *ec2.Instance, w messages.Wait) tea.Msg {
+			return messages.InstanceWaitMsg{Wait: w, Region: region, InstanceID: aws.StringValue(instanceID), Instance: instance}
+		},
+	)
+}
+
+// WaitForECSServiceCmd polls an ECS service until its primary deployment has
+// completed and it runs its desired number of tasks.
+func WaitForECSServiceCmd(ctx context.Context, svc ecsiface.ECSAPI, clusterArn, serviceArn string) tea.Cmd {
+	return wait(
+		func() (*ecs.Service, error) {
+			result, err := svc.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+				Cluster:  aws.String(clusterArn),
+				Services: []*string{aws.String(serviceArn)},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe ECS service %s: %w", serviceArn, err)
+			}
+			if len(result.Services) == 0 {
+				return nil, fmt.Errorf("ECS service %s not found", serviceArn)
+			}
+			return result.Services[0], nil
+		},
+		func(service *ecs.Service) (bool, error) {
+			for _, deployment := range service.Deployments {
+				if aws.StringValue(deployment.Status) != "PRIMARY" {
+					continue
+				}
+				switch aws.StringValue(deployment.RolloutState) {
+				case ecs.DeploymentRolloutStateFailed:
+					return false, fmt.Errorf("deployment of ECS service %s failed: %s", aws.StringValue(service.ServiceName), aws.StringValue(deployment.RolloutStateReason))
+				case ecs.DeploymentRolloutStateInProgress:
+					return false, nil
+				}
+			}
+			// Services without a deployment circuit breaker report no rollout
+			// state; they are steady once the old deployments have drained.
+			return len(service.Deployments) <= 1 && aws.Int64Value(service.RunningCount) == aws.Int64Value(service.DesiredCount), nil
+		},
+		func(service *ecs.Service, w messages.Wait) tea.Msg {
+			return messages.EcsServiceWaitMsg{Wait: w, ServiceArn: serviceArn, Service: service}
+		},
+	)
+}
+
+// WaitForBatchJobCmd polls a Batch job until it has SUCCEEDED or FAILED.
+func WaitForBatchJobCmd(ctx context.Context, svc batchiface.BatchAPI, jobID *string) tea.Cmd {
+	return wait(
+		func() (*batch.JobDetail, error) {
+			result, err := svc.DescribeJobsWithContext(ctx, &batch.DescribeJobsInput{
+				Jobs: []*string{jobID},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe Batch job %s: %w", *jobID, err)
+			}
+			if len(result.Jobs) == 0 {
+				return nil, fmt.Errorf("Batch job %s not found", *jobID)
+			}
+			return result.Jobs[0], nil
+		},
+		func(job *batch.JobDetail) (bool, error) {
+			status := aws.StringValue(job.Status)
+			return status == batch.JobStatusSucceeded || status == batch.JobStatusFailed, nil
+		},
+		func(job *batch.JobDetail, w messages.Wait) tea.Msg {
+			return messages.BatchJobWaitMsg{Wait: w, JobID: aws.StringValue(jobID), Job: job}
+		},
+	)
+}
@@ -1,46 +1,400 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"path/filepath"
 
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
 	Theme string `yaml:"theme"`
+
+	// AWS connection settings. Leave them empty to use the shared AWS
+	// config as-is.
+	Profile            string            `yaml:"profile"`
+	Region             string            `yaml:"region"`
+	Endpoint           string            `yaml:"endpoint"`
+	Endpoints          map[string]string `yaml:"endpoints"`
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify"`
+	CABundle           string            `yaml:"ca_bundle"`
+
+	// AutoRefresh sets the auto-refresh interval of each view, keyed by
+	// service (see AutoRefreshViews). "default" applies to every view that
+	// is not listed.
+	AutoRefresh map[string]time.Duration `yaml:"auto_refresh"`
+
+	// ReadOnly disables every mutating action, whatever the policies say.
+	ReadOnly bool `yaml:"read_only"`
+
+	// Policies guard the mutating actions per profile name or account ID.
+	Policies map[string]Policy `yaml:"policies"`
+
+	// AuditLog is the file every mutating action is recorded in. It
+	// defaults to audit.jsonl next to config.yml.
+	AuditLog string `yaml:"audit_log"`
+
+	// Login is the command that renews expired credentials, with {profile}
+	// standing for the active profile. SSO profiles default to
+	// `aws sso login`.
+	Login string `yaml:"login_command"`
+
+	// Keys remaps actions to other keys, keyed by the action names of
+	// keys.ListKeyMap.Actions.
+	Keys map[string]KeyList `yaml:"keys"`
+
+	// Tables configures the table view of the lists that have one, keyed by
+	// view (see TableColumns).
+	Tables map[string]Table `yaml:"tables"`
+
+	// Views are the saved views listed in the main menu, followed by those
+	// of the project file (see ProjectFile).
+	Views []View `yaml:"views"`
 }
 
-func LoadConfig() *Config {
+// View is a saved view: a list of a service, optionally under a parent
+// resource, with a filter applied, in a given profile and region.
+type View struct {
+	Name string `yaml:"name"`
+	// Service is one of ViewServices.
+	Service string `yaml:"service"`
+	// Profile and Region switch the profile and region if set. Region may
+	// be "all".
+	Profile string `yaml:"profile"`
+	Region  string `yaml:"region"`
+	// Parent names the cluster, repository, state machine or job queue
+	// whose list the view shows. EC2 has none.
+	Parent string `yaml:"parent"`
+	// Filter is the query applied to the list.
+	Filter string `yaml:"filter"`
+}
+
+// ViewServices lists the services a saved view can show.
+var ViewServices = []string{"ec2", "ecs", "ecr", "sfn", "batch"}
+
+// ProjectFile is the project config file, looked up in the working directory
+// and its parents, so a team can share saved views in its repository. It may
+// only hold views: a repository should not be able to change the commands,
+// endpoints or guardrails of whoever opens it.
+const ProjectFile = ".awstui.yml"
+
+// projectConfig is what ProjectFile may set.
+type projectConfig struct {
+	Views []View `yaml:"views"`
+}
+
+// Table configures the table view of a list.
+type Table struct {
+	// Columns lists the columns of the table in order. Empty means the
+	// default columns of the view.
+	Columns []string `yaml:"columns"`
+	// Show starts the list in table view.
+	Show bool `yaml:"show"`
+}
+
+// Policy decides how mutating actions are guarded.
+type Policy string
+
+const (
+	// PolicyAllow asks for a plain y/N confirmation. It is the default.
+	PolicyAllow Policy = "allow"
+	// PolicyTypedConfirmation makes the user type the name of the resource.
+	PolicyTypedConfirmation Policy = "require-typed-confirmation"
+	// PolicyReadOnly blocks mutating actions altogether.
+	PolicyReadOnly Policy = "readonly"
+)
+
+// policies orders the policies from the least to the most strict.
+var policies = []Policy{PolicyAllow, PolicyTypedConfirmation, PolicyReadOnly}
+
+// Stricter reports whether p guards more than q.
+func (p Policy) Stricter(q Policy) bool {
+	return slices.Index(policies, p) > slices.Index(policies, q)
+}
+
+// Policy returns the policy for the given profile and account, the stricter
+// of the two if both are listed, and the reason it applies. The account may
+// be empty while it is not known yet.
+func (c *Config) Policy(profile, account string) (Policy, string) {
+	if c.ReadOnly {
+		return PolicyReadOnly, "read-only mode"
+	}
+	policy, reason := PolicyAllow, ""
+	if p, ok := c.Policies[profile]; ok && p.Stricter(policy) {
+		policy, reason = p, fmt.Sprintf("profile %s is %s", profile, p)
+	}
+	if p, ok := c.Policies[account]; ok && account != "" && p.Stricter(policy) {
+		policy, reason = p, fmt.Sprintf("account %s is %s", account, p)
+	}
+	return policy, reason
+}
+
+// HasAccountPolicies reports whether any policy is keyed by account ID, in
+// which case the account has to be known before mutating actions run.
+func (c *Config) HasAccountPolicies() bool {
+	for name := range c.Policies {
+		if isAccountID(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// KeyList is one key or a list of keys.
+type KeyList []string
 
-	var configPath string
+func (k *KeyList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*k = KeyList{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*k = list
+	return nil
+}
+
+// knownRegion reports whether region is in one of the partitions the SDK
+// knows of.
+func knownRegion(region string) bool {
+	for _, p := range endpoints.DefaultPartitions() {
+		if _, ok := p.Regions()[region]; ok {
+			return true
+		}
+	}
+	return false
+}
 
-	switch runtime.GOOS {
+// KeyBindings returns the keys section in the form keys.ListKeyMap.Remap
+// takes.
+func (c *Config) KeyBindings() map[string][]string {
+	bindings := make(map[string][]string, len(c.Keys))
+	for action, bound := range c.Keys {
+		bindings[action] = bound
+	}
+	return bindings
+}
+
+// AutoRefreshViews lists the keys accepted in Config.AutoRefresh.
+var AutoRefreshViews = []string{"default", "ec2", "ecs", "ecr", "sfn", "batch"}
+
+// RefreshInterval returns the auto-refresh interval configured for view, or
+// zero if it has none.
+func (c *Config) RefreshInterval(view string) time.Duration {
+	if d, ok := c.AutoRefresh[view]; ok {
+		return d
+	}
+	return c.AutoRefresh["default"]
+}
+
+// TableColumns lists the columns the table view of each list offers, the
+// default ones first: EC2 instances, ECS services and Batch jobs.
+var TableColumns = map[string][]string{
+	"ec2":   {"name", "id", "state", "type", "az", "private_ip", "launch_time", "public_ip", "region"},
+	"ecs":   {"name", "desired", "running", "pending", "task_definition", "status", "launch_type", "created"},
+	"batch": {"name", "status", "created", "duration", "id", "started", "stopped"},
+}
+
+// defaultColumns counts the default columns of each view in TableColumns.
+var defaultColumns = map[string]int{"ec2": 7, "ecs": 5, "batch": 4}
+
+// Columns returns the columns of the table view of view.
+func (c *Config) Columns(view string) []string {
+	if columns := c.Tables[view].Columns; len(columns) > 0 {
+		return columns
+	}
+	return TableColumns[view][:defaultColumns[view]]
+}
+
+// LoginCommand returns the command renewing the credentials of profile, or
+// nil if there is none. sso tells whether the profile uses IAM Identity
+// Center.
+func (c *Config) LoginCommand(profile string, sso bool) []string {
+	if c.Login != "" {
+		return strings.Fields(strings.ReplaceAll(c.Login, "{profile}", profile))
+	}
+	if sso {
+		return []string{"aws", "sso", "login", "--profile", profile}
+	}
+	return nil
+}
+
+// configDir returns the directory holding config.yml.
+func configDir() string {
+	return configDirFor(runtime.GOOS)
+}
+
+// configDirFor returns the directory holding config.yml on goos, resolved to
+// a real path.
+func configDirFor(goos string) string {
+	var dir string
+	switch goos {
 	case "windows":
-		configPath = "%LOCALAPPDATA%\\awstui\\config.yml"
+		dir = filepath.Join(os.Getenv("LOCALAPPDATA"), "awstui")
 	case "darwin":
-		configPath = "~/Library/Application\\ Support/awstui/config.yml"
-	case "linux":
-		configPath, _ = expandPath("~/.config/awstui/config.yml")
-
+		dir, _ = expandPath("~/Library/Application Support/awstui")
+	default:
+		dir, _ = expandPath("~/.config/awstui")
 	}
+	return dir
+}
+
+// DebugLogPath returns the file --debug writes to, debug.log next to
+// config.yml.
+func DebugLogPath() string {
+	return filepath.Join(configDir(), "debug.log")
+}
 
-	config := &Config{Theme: "tokyo_night"}
+// FavoritesPath returns the file the starred resources are kept in,
+// favorites.json next to config.yml.
+func FavoritesPath() string {
+	return filepath.Join(configDir(), "favorites.json")
+}
+
+func LoadConfig() *Config {
+	configPath := filepath.Join(configDir(), "config.yml")
+
+	config := &Config{Theme: "tokyo_night", AuditLog: filepath.Join(configDir(), "audit.jsonl")}
 	yamlFile, err := os.ReadFile(configPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		slog.Debug("no config file, using the defaults", "path", configPath)
+	case err != nil:
+		slog.Warn("failed to read config file, using the defaults", "path", configPath, "err", err)
+	default:
+		slog.Debug("loaded config file", "path", configPath)
+		if err := yaml.Unmarshal(yamlFile, config); err != nil {
+			log.Fatalf("Unmarshal: %v", err)
+		}
+	}
+	if path := findProjectFile(); path != "" {
+		config.mergeProject(path)
+	}
+	for view, interval := range config.AutoRefresh {
+		if !slices.Contains(AutoRefreshViews, view) {
+			log.Fatalf("auto_refresh: unknown view %q, expected one of %s", view, strings.Join(AutoRefreshViews, ", "))
+		}
+		if interval != 0 && interval < time.Second {
+			log.Fatalf("auto_refresh: interval of %s must be at least 1s, got %s", view, interval)
+		}
+	}
+	for name, policy := range config.Policies {
+		if !slices.Contains(policies, policy) {
+			log.Fatalf("policies: unknown policy %q for %s, expected one of %s", policy, name, strings.Join([]string{string(PolicyAllow), string(PolicyTypedConfirmation), string(PolicyReadOnly)}, ", "))
+		}
+	}
+	for view, table := range config.Tables {
+		columns, ok := TableColumns[view]
+		if !ok {
+			log.Fatalf("tables: unknown view %q, expected one of ec2, ecs, batch", view)
+		}
+		for i, column := range table.Columns {
+			if !slices.Contains(columns, column) {
+				log.Fatalf("tables: unknown column %q for %s, expected one of %s", column, view, strings.Join(columns, ", "))
+			}
+			if slices.Contains(table.Columns[:i], column) {
+				log.Fatalf("tables: column %q of %s is listed twice", column, view)
+			}
+		}
+	}
+	names := map[string]bool{}
+	for _, view := range config.Views {
+		switch {
+		case view.Name == "":
+			log.Fatalf("views: a view of %s has no name", view.Service)
+		case names[view.Name]:
+			log.Fatalf("views: view %q is defined twice", view.Name)
+		case !slices.Contains(ViewServices, view.Service):
+			log.Fatalf("views: unknown service %q for %s, expected one of %s", view.Service, view.Name, strings.Join(ViewServices, ", "))
+		case view.Service == "ec2" && view.Parent != "":
+			log.Fatalf("views: %s: ec2 views have no parent", view.Name)
+		case view.Region != "" && view.Region != "all" && !knownRegion(view.Region):
+			log.Fatalf("views: %s: unknown region %q, expected a region or all", view.Name, view.Region)
+		}
+		names[view.Name] = true
+	}
+	if err := keys.NewListKeyMap().Remap(config.KeyBindings()); err != nil {
+		log.Fatalf("keys: %v", err)
+	}
+	if config.AuditLog, err = expandPath(config.AuditLog); err != nil {
+		log.Fatalf("audit_log: %v", err)
+	}
+	if config.CABundle != "" {
+		config.CABundle, err = expandPath(config.CABundle)
+		if err != nil {
+			log.Fatalf("ca_bundle: %v", err)
+		}
+	}
+	return config
+}
+
+// findProjectFile returns the path of the closest ProjectFile, in the working
+// directory or one of its parents, or "" if there is none.
+func findProjectFile() string {
+	dir, err := os.Getwd()
 	if err != nil {
-		log.Printf("yamlFile.Get err   #%v ", err)
-		return config
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
 	}
-	err = yaml.Unmarshal(yamlFile, config)
+}
+
+// mergeProject adds the views of the project file at path. Views of
+// config.yml take precedence over project views of the same name.
+func (c *Config) mergeProject(path string) {
+	b, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Unmarshal: %v", err)
+		slog.Warn("failed to read project file", "path", path, "err", err)
+		return
+	}
+	var project projectConfig
+	if err := yaml.UnmarshalStrict(b, &project); err != nil {
+		log.Fatalf("%s: %v (only views can be set in a project file)", path, err)
+	}
+	slog.Debug("loaded project file", "path", path)
+	for _, view := range project.Views {
+		if slices.ContainsFunc(c.Views, func(v View) bool { return v.Name == view.Name }) {
+			slog.Debug("project view overridden by config.yml", "view", view.Name)
+			continue
+		}
+		c.Views = append(c.Views, view)
 	}
-	return config
 }
 
 func expandPath(path string) (string, error) {
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestKnownRegion(t *testing.T) {
+	for region, want := range map[string]bool{
+		"us-east-1":     true,
+		"eu-west-1":     true,
+		"cn-north-1":    true,
+		"us-gov-west-1": true,
+		"us-east-9":     false,
+		"all":           false,
+		"":              false,
+	} {
+		if got := knownRegion(region); got != want {
+			t.Errorf("knownRegion(%q) = %v, want %v", region, got, want)
+		}
+	}
+}
+
+func TestConfigDirFor(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("LOCALAPPDATA", `C:\Users\me\AppData\Local`)
+	for goos, want := range map[string]string{
+		"linux":   "/home/me/.config/awstui",
+		"darwin":  "/home/me/Library/Application Support/awstui",
+		"windows": filepath.Join(`C:\Users\me\AppData\Local`, "awstui"),
+	} {
+		if got := configDirFor(goos); got != want {
+			t.Errorf("configDirFor(%q) = %q, want %q", goos, got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package fake
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/batch/batchiface"
+)
+
+type batchClient struct {
+	batchiface.BatchAPI
+	backend *Backend
+	region  string
+}
+
+func (c *batchClient) DescribeJobQueues(input *batch.DescribeJobQueuesInput) (*batch.DescribeJobQueuesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	out := &batch.DescribeJobQueuesOutput{}
+	out.JobQueues, out.NextToken = page(c.backend, c.backend.region(c.region).JobQueues, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *batchClient) ListJobs(input *batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	queue := aws.StringValue(input.JobQueue)
+	if i := strings.LastIndex(queue, "/"); i >= 0 {
+		queue = queue[i+1:]
+	}
+	status := aws.StringValue(input.JobStatus)
+	if status == "" {
+		status = batch.JobStatusRunning
+	}
+	out := &batch.ListJobsOutput{JobSummaryList: []*batch.JobSummary{}}
+	for _, job := range c.backend.region(c.region).Jobs[queue] {
+		if aws.StringValue(job.Status) != status {
+			continue
+		}
+		out.JobSummaryList = append(out.JobSummaryList, &batch.JobSummary{
+			JobArn:       job.JobArn,
+			JobId:        job.JobId,
+			JobName:      job.JobName,
+			Status:       job.Status,
+			StatusReason: job.StatusReason,
+			CreatedAt:    job.CreatedAt,
+			StartedAt:    job.StartedAt,
+			StoppedAt:    job.StoppedAt,
+		})
+	}
+	out.JobSummaryList, out.NextToken = page(c.backend, out.JobSummaryList, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *batchClient) DescribeJobs(input *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	out := &batch.DescribeJobsOutput{}
+	for _, id := range input.Jobs {
+		if job := c.findJob(aws.StringValue(id)); job != nil {
+			out.Jobs = append(out.Jobs, job)
+		}
+	}
+	return out, nil
+}
+
+// TerminateJob marks the job as failed straight away.
+func (c *batchClient) TerminateJob(input *batch.TerminateJobInput) (*batch.TerminateJobOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	id := aws.StringValue(input.JobId)
+	job := c.findJob(id)
+	if job == nil {
+		return nil, notFound(batch.ErrCodeClientException, "job", id)
+	}
+	job.Status = aws.String(batch.JobStatusFailed)
+	job.StatusReason = input.Reason
+	job.StoppedAt = aws.Int64(time.Now().UnixMilli())
+	return &batch.TerminateJobOutput{}, nil
+}
+
+func (c *batchClient) findJob(id string) *batch.JobDetail {
+	for _, jobs := range c.backend.region(c.region).Jobs {
+		for _, job := range jobs {
+			if aws.StringValue(job.JobId) == id {
+				return job
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// The WithContext variants of the fake calls, which awstui uses so it can
+// abandon requests. Like the SDK they fail once ctx is done, and otherwise
+// behave as the plain calls.
+
+// canceled mimics the error the SDK returns for a request whose context is
+// done.
+func canceled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return awserr.New(request.CanceledErrorCode, "request context canceled", ctx.Err())
+}
+
+func (c *ec2Client) DescribeRegionsWithContext(ctx aws.Context, input *ec2.DescribeRegionsInput, _ ...request.Option) (*ec2.DescribeRegionsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeRegions(input)
+}
+
+func (c *ec2Client) DescribeInstancesWithContext(ctx aws.Context, input *ec2.DescribeInstancesInput, _ ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeInstances(input)
+}
+
+func (c *ec2Client) StopInstancesWithContext(ctx aws.Context, input *ec2.StopInstancesInput, _ ...request.Option) (*ec2.StopInstancesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.StopInstances(input)
+}
+
+func (c *ec2Client) StartInstancesWithContext(ctx aws.Context, input *ec2.StartInstancesInput, _ ...request.Option) (*ec2.StartInstancesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.StartInstances(input)
+}
+
+func (c *ecsClient) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, _ ...request.Option) (*ecs.ListClustersOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListClusters(input)
+}
+
+func (c *ecsClient) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, _ ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeClusters(input)
+}
+
+func (c *ecsClient) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, _ ...request.Option) (*ecs.ListServicesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListServices(input)
+}
+
+func (c *ecsClient) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, _ ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeServices(input)
+}
+
+func (c *ecsClient) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, _ ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.UpdateService(input)
+}
+
+func (c *ecsClient) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, _ ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeTaskDefinition(input)
+}
+
+func (c *ecsClient) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, _ ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeTasks(input)
+}
+
+func (c *ecrClient) DescribeRepositoriesWithContext(ctx aws.Context, input *ecr.DescribeRepositoriesInput, _ ...request.Option) (*ecr.DescribeRepositoriesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeRepositories(input)
+}
+
+func (c *ecrClient) DescribeImagesWithContext(ctx aws.Context, input *ecr.DescribeImagesInput, _ ...request.Option) (*ecr.DescribeImagesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeImages(input)
+}
+
+func (c *ecrClient) BatchDeleteImageWithContext(ctx aws.Context, input *ecr.BatchDeleteImageInput, _ ...request.Option) (*ecr.BatchDeleteImageOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.BatchDeleteImage(input)
+}
+
+func (c *ecrClient) GetAuthorizationTokenWithContext(ctx aws.Context, input *ecr.GetAuthorizationTokenInput, _ ...request.Option) (*ecr.GetAuthorizationTokenOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.GetAuthorizationToken(input)
+}
+
+func (c *logsClient) DescribeLogStreamsWithContext(ctx aws.Context, input *cloudwatchlogs.DescribeLogStreamsInput, _ ...request.Option) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeLogStreams(input)
+}
+
+func (c *logsClient) GetLogEventsWithContext(ctx aws.Context, input *cloudwatchlogs.GetLogEventsInput, _ ...request.Option) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.GetLogEvents(input)
+}
+
+func (c *sfnClient) ListStateMachinesWithContext(ctx aws.Context, input *sfn.ListStateMachinesInput, _ ...request.Option) (*sfn.ListStateMachinesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListStateMachines(input)
+}
+
+func (c *sfnClient) ListExecutionsWithContext(ctx aws.Context, input *sfn.ListExecutionsInput, _ ...request.Option) (*sfn.ListExecutionsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListExecutions(input)
+}
+
+func (c *sfnClient) GetExecutionHistoryWithContext(ctx aws.Context, input *sfn.GetExecutionHistoryInput, _ ...request.Option) (*sfn.GetExecutionHistoryOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.GetExecutionHistory(input)
+}
+
+func (c *sfnClient) StartExecutionWithContext(ctx aws.Context, input *sfn.StartExecutionInput, _ ...request.Option) (*sfn.StartExecutionOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.StartExecution(input)
+}
+
+func (c *batchClient) DescribeJobQueuesWithContext(ctx aws.Context, input *batch.DescribeJobQueuesInput, _ ...request.Option) (*batch.DescribeJobQueuesOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeJobQueues(input)
+}
+
+func (c *batchClient) ListJobsWithContext(ctx aws.Context, input *batch.ListJobsInput, _ ...request.Option) (*batch.ListJobsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.ListJobs(input)
+}
+
+func (c *batchClient) DescribeJobsWithContext(ctx aws.Context, input *batch.DescribeJobsInput, _ ...request.Option) (*batch.DescribeJobsOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.DescribeJobs(input)
+}
+
+func (c *batchClient) TerminateJobWithContext(ctx aws.Context, input *batch.TerminateJobInput, _ ...request.Option) (*batch.TerminateJobOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.TerminateJob(input)
+}
+
+func (c *stsClient) GetCallerIdentityWithContext(ctx aws.Context, input *sts.GetCallerIdentityInput, _ ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+	return c.GetCallerIdentity(input)
+}
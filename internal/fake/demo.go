@@ -0,0 +1,196 @@
+package fake
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/sfn"
+)
+
+// NewDemoBackend returns a backend seeded with a small, realistic set of
+// resources in two regions. Pages are kept small so that lists load the way
+// large accounts do.
+func NewDemoBackend() *Backend {
+	b := NewBackend()
+	b.PageSize = 2
+	now := time.Now()
+	for _, region := range []string{"us-east-1", "eu-west-1"} {
+		seedRegion(b.Region(region), region, now)
+	}
+	return b
+}
+
+func seedRegion(r *Region, region string, now time.Time) {
+	for i, spec := range []struct{ name, state, kind string }{
+		{"web-1", ec2.InstanceStateNameRunning, "t3.small"},
+		{"web-2", ec2.InstanceStateNameRunning, "t3.small"},
+		{"bastion", ec2.InstanceStateNameStopped, "t3.micro"},
+	} {
+		r.Instances = append(r.Instances, &ec2.Instance{
+			InstanceId:       aws.String(fmt.Sprintf("i-%s%013d", region[:2], i+1)),
+			InstanceType:     aws.String(spec.kind),
+			State:            &ec2.InstanceState{Name: aws.String(spec.state)},
+			LaunchTime:       aws.Time(now.Add(-time.Duration(i+1) * 24 * time.Hour)),
+			PrivateIpAddress: aws.String(fmt.Sprintf("10.0.%d.%d", i, 10+i)),
+			Placement:        &ec2.Placement{AvailabilityZone: aws.String(region + "a")},
+			VpcId:            aws.String("vpc-0demo"),
+			SubnetId:         aws.String("subnet-0demo"),
+			KeyName:          aws.String("demo"),
+			Tags:             []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String(spec.name)}, {Key: aws.String("env"), Value: aws.String("demo")}},
+		})
+	}
+
+	clusterArn := Arn("ecs", region, "cluster/demo")
+	r.Clusters = append(r.Clusters, &ecs.Cluster{
+		ClusterArn:  aws.String(clusterArn),
+		ClusterName: aws.String("demo"),
+		Status:      aws.String("ACTIVE"),
+	})
+	repositoryUri := fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/api", AccountID, region)
+	for _, name := range []string{"api", "worker"} {
+		taskDefinitionArn := Arn("ecs", region, "task-definition/"+name+":3")
+		r.TaskDefinitions[taskDefinitionArn] = &ecs.TaskDefinition{
+			TaskDefinitionArn: aws.String(taskDefinitionArn),
+			Family:            aws.String(name),
+			Revision:          aws.Int64(3),
+			Cpu:               aws.String("256"),
+			Memory:            aws.String("512"),
+			NetworkMode:       aws.String(ecs.NetworkModeAwsvpc),
+			ContainerDefinitions: []*ecs.ContainerDefinition{{
+				Name:  aws.String(name),
+				Image: aws.String(repositoryUri + ":v1.2.0"),
+				LogConfiguration: &ecs.LogConfiguration{
+					LogDriver: aws.String("awslogs"),
+					Options: map[string]*string{
+						"awslogs-group":         aws.String("/ecs/" + name),
+						"awslogs-stream-prefix": aws.String("ecs"),
+					},
+				},
+			}},
+		}
+		r.Services[clusterArn] = append(r.Services[clusterArn], &ecs.Service{
+			ServiceArn:     aws.String(Arn("ecs", region, "service/demo/"+name)),
+			ServiceName:    aws.String(name),
+			ClusterArn:     aws.String(clusterArn),
+			Status:         aws.String("ACTIVE"),
+			DesiredCount:   aws.Int64(2),
+			RunningCount:   aws.Int64(2),
+			PendingCount:   aws.Int64(0),
+			LaunchType:     aws.String(ecs.LaunchTypeFargate),
+			TaskDefinition: aws.String(taskDefinitionArn),
+			CreatedAt:      aws.Time(now.Add(-72 * time.Hour)),
+		})
+		r.LogEvents["/ecs/"+name] = map[string][]*cloudwatchlogs.OutputLogEvent{
+			"ecs/" + name + "/0123456789abcdef": demoLogEvents(now, name),
+		}
+	}
+
+	r.Repositories = append(r.Repositories, &ecr.Repository{
+		RepositoryArn:  aws.String(Arn("ecr", region, "repository/api")),
+		RepositoryName: aws.String("api"),
+		RepositoryUri:  aws.String(repositoryUri),
+		RegistryId:     aws.String(AccountID),
+	})
+	r.Images["api"] = []*ecr.ImageDetail{
+		{ImageDigest: aws.String("sha256:1111"), ImageTags: []*string{aws.String("v1.2.0"), aws.String("latest")}, ImagePushedAt: aws.Time(now.Add(-2 * time.Hour)), RepositoryName: aws.String("api")},
+		{ImageDigest: aws.String("sha256:2222"), ImageTags: []*string{aws.String("v1.1.0")}, ImagePushedAt: aws.Time(now.Add(-48 * time.Hour)), RepositoryName: aws.String("api")},
+	}
+
+	// The nightly ETL submits the first demo job; migrations run as a
+	// standalone task of the demo cluster.
+	taskArn := Arn("ecs", region, "task/demo/0123456789abcdef")
+	r.Tasks[clusterArn] = append(r.Tasks[clusterArn], &ecs.Task{
+		TaskArn:           aws.String(taskArn),
+		ClusterArn:        aws.String(clusterArn),
+		TaskDefinitionArn: aws.String(Arn("ecs", region, "task-definition/api:3")),
+		LastStatus:        aws.String("STOPPED"),
+		DesiredStatus:     aws.String("STOPPED"),
+		LaunchType:        aws.String(ecs.LaunchTypeFargate),
+		StartedAt:         aws.Time(now.Add(-3 * time.Hour)),
+		StoppedAt:         aws.Time(now.Add(-3*time.Hour + 2*time.Minute)),
+		StoppedReason:     aws.String("Essential container in task exited"),
+		Containers: []*ecs.Container{{
+			Name:       aws.String("api"),
+			Image:      aws.String(repositoryUri + ":v1.2.0"),
+			LastStatus: aws.String("STOPPED"),
+			ExitCode:   aws.Int64(0),
+		}},
+	})
+	for _, spec := range []struct{ name, task, resourceType, resource, parameters, output string }{
+		{"nightly-etl", "Extract", "batch", "submitJob.sync",
+			`{"JobName":"etl-1","JobQueue":"nightly","JobDefinition":"etl:1"}`,
+			`{"JobId":"00000001-demo-job","JobName":"etl-1","JobQueue":"` + Arn("batch", region, "job-queue/nightly") + `"}`},
+		{"deploy-migrations", "Migrate", "ecs", "runTask.sync",
+			`{"Cluster":"demo","TaskDefinition":"api:3","LaunchType":"FARGATE"}`,
+			`{"ClusterArn":"` + clusterArn + `","TaskArn":"` + taskArn + `","LastStatus":"STOPPED"}`},
+	} {
+		smArn := Arn("states", region, "stateMachine:"+spec.name)
+		r.StateMachines = append(r.StateMachines, &sfn.StateMachineListItem{
+			StateMachineArn: aws.String(smArn),
+			Name:            aws.String(spec.name),
+			Type:            aws.String(sfn.StateMachineTypeStandard),
+			CreationDate:    aws.Time(now.Add(-30 * 24 * time.Hour)),
+		})
+		execArn := Arn("states", region, "execution:"+spec.name+":run-1")
+		r.Executions[smArn] = []*sfn.ExecutionListItem{{
+			ExecutionArn:    aws.String(execArn),
+			StateMachineArn: aws.String(smArn),
+			Name:            aws.String("run-1"),
+			Status:          aws.String(sfn.ExecutionStatusSucceeded),
+			StartDate:       aws.Time(now.Add(-6 * time.Hour)),
+			StopDate:        aws.Time(now.Add(-6*time.Hour + 5*time.Second)),
+		}}
+		r.History[execArn] = TaskHistory(now.Add(-6*time.Hour), spec.task, "{}", spec.resourceType, spec.resource, region, spec.parameters, spec.output)
+	}
+
+	r.JobQueues = append(r.JobQueues, &batch.JobQueueDetail{
+		JobQueueArn:  aws.String(Arn("batch", region, "job-queue/nightly")),
+		JobQueueName: aws.String("nightly"),
+		State:        aws.String(batch.JQStateEnabled),
+		Status:       aws.String(batch.JQStatusValid),
+		Priority:     aws.Int64(1),
+		ComputeEnvironmentOrder: []*batch.ComputeEnvironmentOrder{{
+			Order:              aws.Int64(1),
+			ComputeEnvironment: aws.String(Arn("batch", region, "compute-environment/spot")),
+		}},
+	})
+	r.LogEvents["/aws/batch/job"] = map[string][]*cloudwatchlogs.OutputLogEvent{}
+	for i, status := range []string{batch.JobStatusRunning, batch.JobStatusSucceeded, batch.JobStatusFailed} {
+		id := fmt.Sprintf("%08d-demo-job", i+1)
+		stream := "etl/default/" + id
+		created := now.Add(-time.Duration(i+1) * time.Hour)
+		job := &batch.JobDetail{
+			JobArn:        aws.String(Arn("batch", region, "job/"+id)),
+			JobId:         aws.String(id),
+			JobName:       aws.String(fmt.Sprintf("etl-%d", i+1)),
+			JobQueue:      aws.String(Arn("batch", region, "job-queue/nightly")),
+			Status:        aws.String(status),
+			CreatedAt:     aws.Int64(created.UnixMilli()),
+			StartedAt:     aws.Int64(created.Add(time.Minute).UnixMilli()),
+			JobDefinition: aws.String(Arn("batch", region, "job-definition/etl:1")),
+			Container:     &batch.ContainerDetail{LogStreamName: aws.String(stream)},
+		}
+		if status != batch.JobStatusRunning {
+			job.StoppedAt = aws.Int64(created.Add(10 * time.Minute).UnixMilli())
+		}
+		r.Jobs["nightly"] = append(r.Jobs["nightly"], job)
+		r.LogEvents["/aws/batch/job"][stream] = demoLogEvents(created, "etl")
+	}
+}
+
+func demoLogEvents(start time.Time, name string) []*cloudwatchlogs.OutputLogEvent {
+	var events []*cloudwatchlogs.OutputLogEvent
+	for i, line := range []string{"starting " + name, "connected to database", "processed 1200 records", "done"} {
+		events = append(events, &cloudwatchlogs.OutputLogEvent{
+			Timestamp: aws.Int64(start.Add(time.Duration(i) * time.Second).UnixMilli()),
+			Message:   aws.String(line),
+		})
+	}
+	return events
+}
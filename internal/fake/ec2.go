@@ -0,0 +1,95 @@
+package fake
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+type ec2Client struct {
+	ec2iface.EC2API
+	backend *Backend
+	region  string
+}
+
+func (c *ec2Client) DescribeRegions(input *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	out := &ec2.DescribeRegionsOutput{}
+	for _, name := range c.backend.order {
+		out.Regions = append(out.Regions, &ec2.Region{RegionName: aws.String(name)})
+	}
+	return out, nil
+}
+
+func (c *ec2Client) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	wanted := map[string]bool{}
+	for _, id := range input.InstanceIds {
+		wanted[aws.StringValue(id)] = true
+	}
+	reservation := &ec2.Reservation{}
+	for _, instance := range c.backend.region(c.region).Instances {
+		if len(wanted) == 0 || wanted[aws.StringValue(instance.InstanceId)] {
+			reservation.Instances = append(reservation.Instances, instance)
+		}
+	}
+	if len(wanted) > 0 && len(reservation.Instances) == 0 {
+		return nil, notFound("InvalidInstanceID.NotFound", "instance", aws.StringValue(input.InstanceIds[0]))
+	}
+	out := &ec2.DescribeInstancesOutput{}
+	reservation.Instances, out.NextToken = page(c.backend, reservation.Instances, input.NextToken, input.MaxResults)
+	out.Reservations = []*ec2.Reservation{reservation}
+	return out, nil
+}
+
+func (c *ec2Client) StopInstances(input *ec2.StopInstancesInput) (*ec2.StopInstancesOutput, error) {
+	changes, err := c.setState(input.InstanceIds, ec2.InstanceStateNameStopped)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.StopInstancesOutput{StoppingInstances: changes}, nil
+}
+
+func (c *ec2Client) StartInstances(input *ec2.StartInstancesInput) (*ec2.StartInstancesOutput, error) {
+	changes, err := c.setState(input.InstanceIds, ec2.InstanceStateNameRunning)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.StartInstancesOutput{StartingInstances: changes}, nil
+}
+
+// setState moves instances straight to their target state; the fake has no
+// transitional pending/stopping phase.
+func (c *ec2Client) setState(ids []*string, state string) ([]*ec2.InstanceStateChange, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	var changes []*ec2.InstanceStateChange
+	for _, id := range ids {
+		instance := c.find(aws.StringValue(id))
+		if instance == nil {
+			return nil, notFound("InvalidInstanceID.NotFound", "instance", aws.StringValue(id))
+		}
+		previous := instance.State
+		instance.State = &ec2.InstanceState{Name: aws.String(state)}
+		changes = append(changes, &ec2.InstanceStateChange{
+			InstanceId:    id,
+			PreviousState: previous,
+			CurrentState:  instance.State,
+		})
+	}
+	return changes, nil
+}
+
+func (c *ec2Client) find(id string) *ec2.Instance {
+	for _, instance := range c.backend.region(c.region).Instances {
+		if aws.StringValue(instance.InstanceId) == id {
+			return instance
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package fake
+
+import (
+	"encoding/base64"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+)
+
+type ecrClient struct {
+	ecriface.ECRAPI
+	backend *Backend
+	region  string
+}
+
+func (c *ecrClient) DescribeRepositories(input *ecr.DescribeRepositoriesInput) (*ecr.DescribeRepositoriesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	out := &ecr.DescribeRepositoriesOutput{}
+	out.Repositories, out.NextToken = page(c.backend, c.backend.region(c.region).Repositories, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *ecrClient) DescribeImages(input *ecr.DescribeImagesInput) (*ecr.DescribeImagesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	name := aws.StringValue(input.RepositoryName)
+	images, ok := c.backend.region(c.region).Images[name]
+	if !ok {
+		return nil, notFound(ecr.ErrCodeRepositoryNotFoundException, "repository", name)
+	}
+	out := &ecr.DescribeImagesOutput{}
+	out.ImageDetails, out.NextToken = page(c.backend, images, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+// BatchDeleteImage removes images by digest. Unknown digests are reported as
+// failures, as ECR does.
+func (c *ecrClient) BatchDeleteImage(input *ecr.BatchDeleteImageInput) (*ecr.BatchDeleteImageOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	name := aws.StringValue(input.RepositoryName)
+	region := c.backend.region(c.region)
+	images, ok := region.Images[name]
+	if !ok {
+		return nil, notFound(ecr.ErrCodeRepositoryNotFoundException, "repository", name)
+	}
+	out := &ecr.BatchDeleteImageOutput{}
+	for _, id := range input.ImageIds {
+		i := slices.IndexFunc(images, func(image *ecr.ImageDetail) bool {
+			return aws.StringValue(image.ImageDigest) == aws.StringValue(id.ImageDigest)
+		})
+		if i < 0 {
+			out.Failures = append(out.Failures, &ecr.ImageFailure{
+				ImageId:       id,
+				FailureCode:   aws.String(ecr.ImageFailureCodeImageNotFound),
+				FailureReason: aws.String("Requested image not found"),
+			})
+			continue
+		}
+		images = slices.Concat(images[:i], images[i+1:])
+		out.ImageIds = append(out.ImageIds, id)
+	}
+	region.Images[name] = images
+	return out, nil
+}
+
+func (c *ecrClient) GetAuthorizationToken(input *ecr.GetAuthorizationTokenInput) (*ecr.GetAuthorizationTokenOutput, error) {
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []*ecr.AuthorizationData{{
+			AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS:fake-token"))),
+			ExpiresAt:          aws.Time(time.Now().Add(12 * time.Hour)),
+			ProxyEndpoint:      aws.String("https://" + AccountID + ".dkr.ecr." + c.region + ".amazonaws.com"),
+		}},
+	}, nil
+}
@@ -0,0 +1,183 @@
+package fake
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+)
+
+type ecsClient struct {
+	ecsiface.ECSAPI
+	backend *Backend
+	region  string
+}
+
+func (c *ecsClient) ListClusters(input *ecs.ListClustersInput) (*ecs.ListClustersOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	out := &ecs.ListClustersOutput{ClusterArns: []*string{}}
+	for _, cluster := range c.backend.region(c.region).Clusters {
+		out.ClusterArns = append(out.ClusterArns, cluster.ClusterArn)
+	}
+	out.ClusterArns, out.NextToken = page(c.backend, out.ClusterArns, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *ecsClient) DescribeClusters(input *ecs.DescribeClustersInput) (*ecs.DescribeClustersOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	if len(input.Clusters) > 100 {
+		return nil, awserr.New(ecs.ErrCodeInvalidParameterException, "clusters can have at most 100 items", nil)
+	}
+	out := &ecs.DescribeClustersOutput{}
+	for _, id := range input.Clusters {
+		if cluster := c.findCluster(aws.StringValue(id)); cluster != nil {
+			out.Clusters = append(out.Clusters, cluster)
+		} else {
+			out.Failures = append(out.Failures, &ecs.Failure{Arn: id, Reason: aws.String("MISSING")})
+		}
+	}
+	return out, nil
+}
+
+func (c *ecsClient) ListServices(input *ecs.ListServicesInput) (*ecs.ListServicesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	cluster := c.findCluster(aws.StringValue(input.Cluster))
+	if cluster == nil {
+		return nil, notFound(ecs.ErrCodeClusterNotFoundException, "cluster", aws.StringValue(input.Cluster))
+	}
+	out := &ecs.ListServicesOutput{ServiceArns: []*string{}}
+	for _, service := range c.backend.region(c.region).Services[aws.StringValue(cluster.ClusterArn)] {
+		out.ServiceArns = append(out.ServiceArns, service.ServiceArn)
+	}
+	out.ServiceArns, out.NextToken = page(c.backend, out.ServiceArns, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *ecsClient) DescribeServices(input *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	cluster := c.findCluster(aws.StringValue(input.Cluster))
+	if cluster == nil {
+		return nil, notFound(ecs.ErrCodeClusterNotFoundException, "cluster", aws.StringValue(input.Cluster))
+	}
+	if len(input.Services) > 10 {
+		return nil, awserr.New(ecs.ErrCodeInvalidParameterException, "services can have at most 10 items", nil)
+	}
+	out := &ecs.DescribeServicesOutput{}
+	for _, id := range input.Services {
+		if service := c.findService(cluster, aws.StringValue(id)); service != nil {
+			out.Services = append(out.Services, service)
+		} else {
+			out.Failures = append(out.Failures, &ecs.Failure{Arn: id, Reason: aws.String("MISSING")})
+		}
+	}
+	return out, nil
+}
+
+// UpdateService applies desired count and forced deployments immediately, so
+// the new deployment is reported as already completed.
+func (c *ecsClient) UpdateService(input *ecs.UpdateServiceInput) (*ecs.UpdateServiceOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	cluster := c.findCluster(aws.StringValue(input.Cluster))
+	if cluster == nil {
+		return nil, notFound(ecs.ErrCodeClusterNotFoundException, "cluster", aws.StringValue(input.Cluster))
+	}
+	service := c.findService(cluster, aws.StringValue(input.Service))
+	if service == nil {
+		return nil, notFound(ecs.ErrCodeServiceNotFoundException, "service", aws.StringValue(input.Service))
+	}
+	if input.DesiredCount != nil {
+		service.DesiredCount = input.DesiredCount
+	}
+	if input.TaskDefinition != nil {
+		service.TaskDefinition = input.TaskDefinition
+	}
+	service.RunningCount = service.DesiredCount
+	service.PendingCount = aws.Int64(0)
+	now := time.Now()
+	service.Deployments = []*ecs.Deployment{{
+		Id:             aws.String("ecs-svc/" + now.Format("20060102150405")),
+		Status:         aws.String("PRIMARY"),
+		RolloutState:   aws.String(ecs.DeploymentRolloutStateCompleted),
+		TaskDefinition: service.TaskDefinition,
+		DesiredCount:   service.DesiredCount,
+		RunningCount:   service.RunningCount,
+		CreatedAt:      aws.Time(now),
+		UpdatedAt:      aws.Time(now),
+	}}
+	return &ecs.UpdateServiceOutput{Service: service}, nil
+}
+
+func (c *ecsClient) DescribeTaskDefinition(input *ecs.DescribeTaskDefinitionInput) (*ecs.DescribeTaskDefinitionOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	id := aws.StringValue(input.TaskDefinition)
+	for arn, taskDefinition := range c.backend.region(c.region).TaskDefinitions {
+		if arn == id || strings.HasSuffix(arn, "/"+id) {
+			return &ecs.DescribeTaskDefinitionOutput{TaskDefinition: taskDefinition}, nil
+		}
+	}
+	return nil, notFound(ecs.ErrCodeClientException, "task definition", id)
+}
+
+func (c *ecsClient) DescribeTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	cluster := c.findCluster(aws.StringValue(input.Cluster))
+	if cluster == nil {
+		return nil, notFound(ecs.ErrCodeClusterNotFoundException, "cluster", aws.StringValue(input.Cluster))
+	}
+	if len(input.Tasks) > 100 {
+		return nil, awserr.New(ecs.ErrCodeInvalidParameterException, "tasks can have at most 100 items", nil)
+	}
+	out := &ecs.DescribeTasksOutput{}
+	for _, id := range input.Tasks {
+		if task := c.findTask(cluster, aws.StringValue(id)); task != nil {
+			out.Tasks = append(out.Tasks, task)
+		} else {
+			out.Failures = append(out.Failures, &ecs.Failure{Arn: id, Reason: aws.String("MISSING")})
+		}
+	}
+	return out, nil
+}
+
+func (c *ecsClient) findCluster(id string) *ecs.Cluster {
+	for _, cluster := range c.backend.region(c.region).Clusters {
+		if aws.StringValue(cluster.ClusterArn) == id || aws.StringValue(cluster.ClusterName) == id {
+			return cluster
+		}
+	}
+	return nil
+}
+
+func (c *ecsClient) findService(cluster *ecs.Cluster, id string) *ecs.Service {
+	for _, service := range c.backend.region(c.region).Services[aws.StringValue(cluster.ClusterArn)] {
+		if aws.StringValue(service.ServiceArn) == id || aws.StringValue(service.ServiceName) == id {
+			return service
+		}
+	}
+	return nil
+}
+
+func (c *ecsClient) findTask(cluster *ecs.Cluster, id string) *ecs.Task {
+	for _, task := range c.backend.region(c.region).Tasks[aws.StringValue(cluster.ClusterArn)] {
+		if arn := aws.StringValue(task.TaskArn); arn == id || strings.HasSuffix(arn, "/"+id) {
+			return task
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package fake provides an in-memory AWS backend that implements the SDK
+// service interfaces used by awstui, so the TUI can run without an account.
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/sfn"
+)
+
+// AccountID is the account every fake resource belongs to.
+const AccountID = "123456789012"
+
+// Region holds the resources of a single fake region. Seed it before handing
+// the backend to a model; the fake clients mutate it in response to actions.
+type Region struct {
+	Instances []*ec2.Instance
+
+	Clusters        []*ecs.Cluster
+	Services        map[string][]*ecs.Service // keyed by cluster ARN
+	TaskDefinitions map[string]*ecs.TaskDefinition
+	Tasks           map[string][]*ecs.Task // keyed by cluster ARN
+
+	Repositories []*ecr.Repository
+	Images       map[string][]*ecr.ImageDetail // keyed by repository name
+
+	StateMachines []*sfn.StateMachineListItem
+	Executions    map[string][]*sfn.ExecutionListItem // keyed by state machine ARN
+	History       map[string][]*sfn.HistoryEvent      // keyed by execution ARN
+
+	JobQueues []*batch.JobQueueDetail
+	Jobs      map[string][]*batch.JobDetail // keyed by job queue name
+
+	LogEvents map[string]map[string][]*cloudwatchlogs.OutputLogEvent // group, then stream
+}
+
+// Backend is the shared state behind every fake client.
+type Backend struct {
+	// PageSize caps the page size of every list call, so that small data
+	// sets still exercise pagination. Zero honours MaxResults alone.
+	PageSize int
+
+	mu      sync.Mutex
+	regions map[string]*Region
+	order   []string
+}
+
+// NewBackend returns an empty backend.
+func NewBackend() *Backend {
+	return &Backend{regions: map[string]*Region{}}
+}
+
+// Region returns the resources of a region, creating it on first use.
+func (b *Backend) Region(name string) *Region {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.region(name)
+}
+
+func (b *Backend) region(name string) *Region {
+	r, ok := b.regions[name]
+	if !ok {
+		r = &Region{
+			Services:        map[string][]*ecs.Service{},
+			TaskDefinitions: map[string]*ecs.TaskDefinition{},
+			Tasks:           map[string][]*ecs.Task{},
+			Images:          map[string][]*ecr.ImageDetail{},
+			Executions:      map[string][]*sfn.ExecutionListItem{},
+			History:         map[string][]*sfn.HistoryEvent{},
+			Jobs:            map[string][]*batch.JobDetail{},
+			LogEvents:       map[string]map[string][]*cloudwatchlogs.OutputLogEvent{},
+		}
+		b.regions[name] = r
+		b.order = append(b.order, name)
+	}
+	return r
+}
+
+// Clients returns fake clients for a region of the backend.
+func (b *Backend) Clients(profile, region string) *clients.Clients {
+	return &clients.Clients{
+		Profile: profile,
+		Region:  region,
+		EC2:     &ec2Client{backend: b, region: region},
+		ECS:     &ecsClient{backend: b, region: region},
+		ECR:     &ecrClient{backend: b, region: region},
+		Logs:    &logsClient{backend: b, region: region},
+		SFN:     &sfnClient{backend: b, region: region},
+		Batch:   &batchClient{backend: b, region: region},
+		STS:     &stsClient{profile: profile},
+	}
+}
+
+// Pool returns a client pool backed by b.
+func (b *Backend) Pool(profile, defaultRegion string) *clients.Pool {
+	return clients.NewFactoryPool(profile, defaultRegion, func(region string) *clients.Clients {
+		return b.Clients(profile, region)
+	})
+}
+
+// Arn builds a fake ARN for a resource in region.
+func Arn(service, region, resource string) string {
+	return fmt.Sprintf("arn:aws:%s:%s:%s:%s", service, region, AccountID, resource)
+}
+
+// page returns the slice of items a paginated list call would return for
+// token, along with the token of the following page. Tokens are the offset of
+// the next item.
+func page[T any](b *Backend, items []T, token *string, maxResults *int64) ([]T, *string) {
+	start, _ := strconv.Atoi(aws.StringValue(token))
+	start = min(start, len(items))
+	size := len(items) - start
+	if n := int(aws.Int64Value(maxResults)); n > 0 {
+		size = min(size, n)
+	}
+	if b.PageSize > 0 {
+		size = min(size, b.PageSize)
+	}
+	end := start + size
+	if end == len(items) {
+		return items[start:end], nil
+	}
+	return items[start:end], aws.String(strconv.Itoa(end))
+}
+
+// notFound mimics the error the SDK returns for a missing resource.
+func notFound(code, kind, id string) error {
+	return awserr.New(code, fmt.Sprintf("%s %s not found", kind, id), nil)
+}
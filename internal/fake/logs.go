@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+)
+
+type logsClient struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	backend *Backend
+	region  string
+}
+
+func (c *logsClient) DescribeLogStreams(input *cloudwatchlogs.DescribeLogStreamsInput) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	group := aws.StringValue(input.LogGroupName)
+	streams, ok := c.backend.region(c.region).LogEvents[group]
+	if !ok {
+		return nil, notFound(cloudwatchlogs.ErrCodeResourceNotFoundException, "log group", group)
+	}
+	out := &cloudwatchlogs.DescribeLogStreamsOutput{}
+	for name, events := range streams {
+		stream := &cloudwatchlogs.LogStream{LogStreamName: aws.String(name)}
+		if len(events) > 0 {
+			stream.LastEventTimestamp = events[len(events)-1].Timestamp
+		}
+		out.LogStreams = append(out.LogStreams, stream)
+	}
+	sort.Slice(out.LogStreams, func(i, j int) bool {
+		return aws.Int64Value(out.LogStreams[i].LastEventTimestamp) > aws.Int64Value(out.LogStreams[j].LastEventTimestamp)
+	})
+	if limit := int(aws.Int64Value(input.Limit)); limit > 0 && len(out.LogStreams) > limit {
+		out.LogStreams = out.LogStreams[:limit]
+	}
+	return out, nil
+}
+
+// GetLogEvents returns every event of a stream in one page. The forward token
+// is stable so callers paginating until it repeats stop after one call.
+func (c *logsClient) GetLogEvents(input *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	group := aws.StringValue(input.LogGroupName)
+	stream := aws.StringValue(input.LogStreamName)
+	events, ok := c.backend.region(c.region).LogEvents[group][stream]
+	if !ok {
+		return nil, notFound(cloudwatchlogs.ErrCodeResourceNotFoundException, "log stream", group+"/"+stream)
+	}
+	token := aws.String("f/" + stream)
+	if input.NextToken != nil {
+		events = nil
+	}
+	return &cloudwatchlogs.GetLogEventsOutput{
+		Events:            events,
+		NextForwardToken:  token,
+		NextBackwardToken: aws.String("b/" + stream),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+)
+
+type sfnClient struct {
+	sfniface.SFNAPI
+	backend *Backend
+	region  string
+}
+
+func (c *sfnClient) ListStateMachines(input *sfn.ListStateMachinesInput) (*sfn.ListStateMachinesOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	out := &sfn.ListStateMachinesOutput{}
+	out.StateMachines, out.NextToken = page(c.backend, c.backend.region(c.region).StateMachines, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *sfnClient) ListExecutions(input *sfn.ListExecutionsInput) (*sfn.ListExecutionsOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	arn := aws.StringValue(input.StateMachineArn)
+	if c.findStateMachine(arn) == nil {
+		return nil, notFound(sfn.ErrCodeStateMachineDoesNotExist, "state machine", arn)
+	}
+	out := &sfn.ListExecutionsOutput{}
+	out.Executions, out.NextToken = page(c.backend, c.backend.region(c.region).Executions[arn], input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+func (c *sfnClient) GetExecutionHistory(input *sfn.GetExecutionHistoryInput) (*sfn.GetExecutionHistoryOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	arn := aws.StringValue(input.ExecutionArn)
+	events, ok := c.backend.region(c.region).History[arn]
+	if !ok {
+		return nil, notFound(sfn.ErrCodeExecutionDoesNotExist, "execution", arn)
+	}
+	out := &sfn.GetExecutionHistoryOutput{}
+	out.Events, out.NextToken = page(c.backend, events, input.NextToken, input.MaxResults)
+	return out, nil
+}
+
+// StartExecution records a new execution that has already succeeded.
+func (c *sfnClient) StartExecution(input *sfn.StartExecutionInput) (*sfn.StartExecutionOutput, error) {
+	c.backend.mu.Lock()
+	defer c.backend.mu.Unlock()
+
+	smArn := aws.StringValue(input.StateMachineArn)
+	sm := c.findStateMachine(smArn)
+	if sm == nil {
+		return nil, notFound(sfn.ErrCodeStateMachineDoesNotExist, "state machine", smArn)
+	}
+	r := c.backend.region(c.region)
+	now := time.Now()
+	name := aws.StringValue(input.Name)
+	if name == "" {
+		name = fmt.Sprintf("execution-%d", len(r.Executions[smArn])+1)
+	}
+	execArn := strings.Replace(smArn, ":stateMachine:", ":execution:", 1) + ":" + name
+	r.Executions[smArn] = append([]*sfn.ExecutionListItem{{
+		ExecutionArn:    aws.String(execArn),
+		StateMachineArn: sm.StateMachineArn,
+		Name:            aws.String(name),
+		Status:          aws.String(sfn.ExecutionStatusSucceeded),
+		StartDate:       aws.Time(now),
+		StopDate:        aws.Time(now),
+	}}, r.Executions[smArn]...)
+	r.History[execArn] = SimpleHistory(now, "Run", aws.StringValue(input.Input))
+	return &sfn.StartExecutionOutput{ExecutionArn: aws.String(execArn), StartDate: aws.Time(now)}, nil
+}
+
+func (c *sfnClient) findStateMachine(arn string) *sfn.StateMachineListItem {
+	for _, sm := range c.backend.region(c.region).StateMachines {
+		if aws.StringValue(sm.StateMachineArn) == arn {
+			return sm
+		}
+	}
+	return nil
+}
+
+// SimpleHistory returns the events of a successful single task execution.
+func SimpleHistory(start time.Time, task, input string) []*sfn.HistoryEvent {
+	return TaskHistory(start, task, input, "batch", "submitJob.sync", "us-east-1", "{}", "{}")
+}
+
+// TaskHistory returns the events of a successful execution of a single task
+// calling resource of a service integration, such as "submitJob.sync" of
+// "batch", with the given parameters and output.
+func TaskHistory(start time.Time, task, input, resourceType, resource, region, parameters, output string) []*sfn.HistoryEvent {
+	at := func(i int) *time.Time { return aws.Time(start.Add(time.Duration(i) * time.Second)) }
+	return []*sfn.HistoryEvent{
+		{Id: aws.Int64(1), Type: aws.String(sfn.HistoryEventTypeExecutionStarted), Timestamp: at(0),
+			ExecutionStartedEventDetails: &sfn.ExecutionStartedEventDetails{Input: aws.String(input)}},
+		{Id: aws.Int64(2), PreviousEventId: aws.Int64(1), Type: aws.String(sfn.HistoryEventTypeTaskStateEntered), Timestamp: at(1),
+			StateEnteredEventDetails: &sfn.StateEnteredEventDetails{Name: aws.String(task), Input: aws.String(input)}},
+		{Id: aws.Int64(3), PreviousEventId: aws.Int64(2), Type: aws.String(sfn.HistoryEventTypeTaskScheduled), Timestamp: at(2),
+			TaskScheduledEventDetails: &sfn.TaskScheduledEventDetails{Resource: aws.String(resource), ResourceType: aws.String(resourceType), Region: aws.String(region), Parameters: aws.String(parameters)}},
+		{Id: aws.Int64(4), PreviousEventId: aws.Int64(3), Type: aws.String(sfn.HistoryEventTypeTaskSucceeded), Timestamp: at(3),
+			TaskSucceededEventDetails: &sfn.TaskSucceededEventDetails{Resource: aws.String(resource), ResourceType: aws.String(resourceType), Output: aws.String(output)}},
+		{Id: aws.Int64(5), PreviousEventId: aws.Int64(4), Type: aws.String(sfn.HistoryEventTypeTaskStateExited), Timestamp: at(4),
+			StateExitedEventDetails: &sfn.StateExitedEventDetails{Name: aws.String(task), Output: aws.String("{}")}},
+		{Id: aws.Int64(6), PreviousEventId: aws.Int64(5), Type: aws.String(sfn.HistoryEventTypeExecutionSucceeded), Timestamp: at(5),
+			ExecutionSucceededEventDetails: &sfn.ExecutionSucceededEventDetails{Output: aws.String("{}")}},
+	}
+}
@@ -0,0 +1,21 @@
+package fake
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+type stsClient struct {
+	stsiface.STSAPI
+	profile string
+}
+
+// GetCallerIdentity reports an assumed role named after the profile.
+func (c *stsClient) GetCallerIdentity(*sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(AccountID),
+		Arn:     aws.String("arn:aws:sts::" + AccountID + ":assumed-role/" + c.profile + "/awstui"),
+		UserId:  aws.String("AROADEMO:awstui"),
+	}, nil
+}
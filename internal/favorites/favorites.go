@@ -0,0 +1,158 @@
+// Package favorites keeps the resources the user has starred, per profile,
+// in a local JSON file.
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Services of a Favorite.
+const (
+	ServiceEC2   = "ec2"
+	ServiceECS   = "ecs"
+	ServiceECR   = "ecr"
+	ServiceSFN   = "sfn"
+	ServiceBatch = "batch"
+)
+
+// Favorite is a starred resource.
+type Favorite struct {
+	Profile string `json:"profile"`
+	Service string `json:"service"`
+	Region  string `json:"region"`
+	// Key identifies the resource within its profile: the region and ID of
+	// an instance, the ARN of anything else.
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// Path leads from the service's first list to the resource, such as the
+	// cluster and service ARNs of an ECS service.
+	Path []string `json:"path"`
+}
+
+// Store is a favorites file. A Store without a path keeps its favorites in
+// memory, which the demo mode uses to leave the real file alone.
+type Store struct {
+	path string
+
+	mu     sync.Mutex
+	loaded bool
+	list   []Favorite
+}
+
+// Open returns the favorites stored at path, or an in-memory store if path is
+// empty. The file is read on first use and created on the first Toggle.
+func Open(path string) *Store {
+	return &Store{path: path, loaded: path == ""}
+}
+
+// Path returns where the favorites are stored, or "" for an in-memory store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// load reads the file the first time the favorites are needed. A file that
+// does not exist yet holds no favorites.
+func (s *Store) load() error {
+	if s.loaded {
+		return nil
+	}
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read favorites: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.list); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+	s.loaded = true
+	return nil
+}
+
+// List returns the favorites of profile in the order they were starred.
+func (s *Store) List(profile string) ([]Favorite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	var list []Favorite
+	for _, f := range s.list {
+		if f.Profile == profile {
+			list = append(list, f)
+		}
+	}
+	return list, nil
+}
+
+// Has reports whether the resource with key is a favorite of profile. A
+// file that cannot be read has none.
+func (s *Store) Has(profile, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.load() != nil {
+		return false
+	}
+	return s.index(profile, key) >= 0
+}
+
+func (s *Store) index(profile, key string) int {
+	for i, f := range s.list {
+		if f.Profile == profile && f.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Toggle stars f, or removes it if it is a favorite already, and saves the
+// favorites. It reports whether f was added.
+func (s *Store) Toggle(f Favorite) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	list := append([]Favorite(nil), s.list...)
+	added := true
+	if i := s.index(f.Profile, f.Key); i >= 0 {
+		list = append(list[:i], list[i+1:]...)
+		added = false
+	} else {
+		list = append(list, f)
+	}
+	if err := s.save(list); err != nil {
+		return false, err
+	}
+	s.list = list
+	return added, nil
+}
+
+// save writes list to the file, replacing it whole.
+func (s *Store) save(list []Favorite) error {
+	if s.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create favorites directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write favorites: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write favorites: %w", err)
+	}
+	return nil
+}
@@ -1,19 +1,48 @@
 package keys
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
 
 type ListKeyMap struct {
-	Details     key.Binding
-	Start       key.Binding
-	Stop        key.Binding
-	Ssh         key.Binding
-	Refresh     key.Binding
-	Logs        key.Binding
-	ForceDeploy key.Binding
-	Pull        key.Binding
-	Push        key.Binding
-	Choose      key.Binding
+	Details        key.Binding
+	Start          key.Binding
+	Stop           key.Binding
+	Ssh            key.Binding
+	Refresh        key.Binding
+	Logs           key.Binding
+	ForceDeploy    key.Binding
+	Pull           key.Binding
+	Push           key.Binding
+	Choose         key.Binding
 	StartExecution key.Binding
+	Delete         key.Binding
+	Mark           key.Binding
+	MarkAll        key.Binding
+	Table          key.Binding
+	Sort           key.Binding
+	Favorite       key.Binding
+	GoTo           key.Binding
+	Up             key.Binding
+	Down           key.Binding
+	Profile        key.Binding
+	Region         key.Binding
+	AutoRefresh    key.Binding
+	Command        key.Binding
+	Notifications  key.Binding
+	Operations     key.Binding
+	Back           key.Binding
+	Confirm        key.Binding
+	Cancel         key.Binding
+
+	// HideMutating hides the bindings of mutating actions from help, for
+	// when they are blocked by policy.
+	HideMutating bool
 }
 
 func NewListKeyMap() *ListKeyMap {
@@ -62,5 +91,224 @@ func NewListKeyMap() *ListKeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "execute"),
 		),
+		Delete: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "delete"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark"),
+		),
+		MarkAll: key.NewBinding(
+			key.WithKeys("ctrl+a"),
+			key.WithHelp("ctrl+a", "mark all"),
+		),
+		Table: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "table view"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "sort by column"),
+		),
+		Favorite: key.NewBinding(
+			key.WithKeys("*"),
+			key.WithHelp("*", "star"),
+		),
+		GoTo: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "go to"),
+		),
+		// Up and Down move the mark over the links of a detail view.
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Profile: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "switch profile"),
+		),
+		Region: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "switch region"),
+		),
+		AutoRefresh: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "auto-refresh"),
+		),
+		Command: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "command"),
+		),
+		Notifications: key.NewBinding(
+			key.WithKeys("!"),
+			key.WithHelp("!", "notifications"),
+		),
+		Operations: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "operations"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc", "backspace"),
+			key.WithHelp("esc", "back"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("y", "Y"),
+			key.WithHelp("y", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("n", "N"),
+			key.WithHelp("N", "cancel"),
+		),
+	}
+}
+
+// Actions lists the bindings by the names used in the keys section of
+// config.yml.
+func (k *ListKeyMap) Actions() []string {
+	var names []string
+	for _, a := range k.actions() {
+		names = append(names, a.name)
+	}
+	return names
+}
+
+type action struct {
+	name    string
+	binding *key.Binding
+}
+
+func (k *ListKeyMap) actions() []action {
+	return []action{
+		{"details", &k.Details},
+		{"start", &k.Start},
+		{"stop", &k.Stop},
+		{"ssh", &k.Ssh},
+		{"refresh", &k.Refresh},
+		{"logs", &k.Logs},
+		{"force_deploy", &k.ForceDeploy},
+		{"pull", &k.Pull},
+		{"push", &k.Push},
+		{"choose", &k.Choose},
+		{"start_execution", &k.StartExecution},
+		{"delete", &k.Delete},
+		{"mark", &k.Mark},
+		{"mark_all", &k.MarkAll},
+		{"table", &k.Table},
+		{"sort", &k.Sort},
+		{"favorite", &k.Favorite},
+		{"go_to", &k.GoTo},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"profile", &k.Profile},
+		{"region", &k.Region},
+		{"auto_refresh", &k.AutoRefresh},
+		{"command", &k.Command},
+		{"notifications", &k.Notifications},
+		{"operations", &k.Operations},
+		{"back", &k.Back},
+		{"confirm", &k.Confirm},
+		{"cancel", &k.Cancel},
+	}
+}
+
+// Remap binds the named actions to new keys. The help text follows the new
+// keys. It fails on unknown actions, on keys bound to more than one action
+// and on keys the lists use themselves, unless the action has them by
+// default.
+func (k *ListKeyMap) Remap(bindings map[string][]string) error {
+	actions := k.actions()
+	defaults := NewListKeyMap().actions()
+	taken := listKeys()
+	for name, ks := range bindings {
+		i := indexOf(actions, name)
+		if i < 0 {
+			return fmt.Errorf("unknown action %q, expected one of %s", name, strings.Join(k.Actions(), ", "))
+		}
+		if len(ks) == 0 {
+			return fmt.Errorf("%s: no keys given", name)
+		}
+		for _, s := range ks {
+			if use, ok := taken[s]; ok && !slices.Contains(defaults[i].binding.Keys(), s) {
+				return fmt.Errorf("%s: key %q is taken by the lists for %q", name, s, use)
+			}
+		}
+		b := actions[i].binding
+		b.SetKeys(ks...)
+		b.SetHelp(strings.Join(ks, "/"), b.Help().Desc)
+	}
+
+	owners := map[string]string{}
+	for _, a := range actions {
+		for _, s := range a.binding.Keys() {
+			if owner, ok := owners[s]; ok {
+				return fmt.Errorf("key %q is bound to both %s and %s", s, owner, a.name)
+			}
+			owners[s] = a.name
+		}
+	}
+	return nil
+}
+
+// listKeys maps the keys the lists handle themselves while browsing to what
+// they use them for.
+func listKeys() map[string]string {
+	l := list.DefaultKeyMap()
+	// Force quit has no help of its own.
+	l.ForceQuit.SetHelp("ctrl+c", "quit")
+	taken := map[string]string{}
+	for _, b := range []key.Binding{
+		l.CursorUp, l.CursorDown, l.PrevPage, l.NextPage, l.GoToStart, l.GoToEnd,
+		l.Filter, l.ClearFilter, l.ShowFullHelp, l.CloseFullHelp, l.Quit, l.ForceQuit,
+	} {
+		for _, s := range b.Keys() {
+			if _, ok := taken[s]; !ok {
+				taken[s] = b.Help().Desc
+			}
+		}
+	}
+	return taken
+}
+
+// Mutating reports whether b triggers an action that changes AWS resources.
+func (k *ListKeyMap) Mutating(b key.Binding) bool {
+	for _, m := range []key.Binding{k.Start, k.Stop, k.ForceDeploy, k.Push, k.StartExecution, k.Delete} {
+		if b.Help() == m.Help() {
+			return true
+		}
+	}
+	return false
+}
+
+// Help returns the bindings to list in help, leaving out mutating ones while
+// HideMutating is set.
+func (k *ListKeyMap) Help(bindings ...key.Binding) []key.Binding {
+	if !k.HideMutating {
+		return bindings
+	}
+	var visible []key.Binding
+	for _, b := range bindings {
+		if !k.Mutating(b) {
+			visible = append(visible, b)
+		}
+	}
+	return visible
+}
+
+// ConfirmPrompt is the hint appended to confirmation questions, e.g. "(y/N)".
+func (k *ListKeyMap) ConfirmPrompt() string {
+	return fmt.Sprintf("(%s/%s)", k.Confirm.Help().Key, k.Cancel.Help().Key)
+}
+
+func indexOf(actions []action, name string) int {
+	for i, a := range actions {
+		if a.name == name {
+			return i
+		}
 	}
+	return -1
 }
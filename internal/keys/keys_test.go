@@ -0,0 +1,52 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemap(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[string][]string
+		err      string
+	}{
+		{"new keys", map[string][]string{"stop": {"S"}, "back": {"esc", "backspace", "H"}}, ""},
+		{"default keys the lists use", map[string][]string{"details": {"d"}, "back": {"esc"}}, ""},
+		{"unknown action", map[string][]string{"halt": {"h"}}, `unknown action "halt"`},
+		{"no keys", map[string][]string{"stop": {}}, "stop: no keys given"},
+		{"bound twice", map[string][]string{"stop": {"o"}}, `key "o" is bound to both`},
+		{"list key", map[string][]string{"back": {"esc", "h"}}, `back: key "h" is taken by the lists for "prev page"`},
+		{"list key without help", map[string][]string{"refresh": {"ctrl+c"}}, `for "quit"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewListKeyMap().Remap(tt.bindings)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("Remap() = %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("Remap() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRemapHelp(t *testing.T) {
+	k := NewListKeyMap()
+	if err := k.Remap(map[string][]string{"back": {"esc", "H"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := k.Back.Help().Key; got != "esc/H" {
+		t.Fatalf("help = %q, want the new keys", got)
+	}
+}
+
+func TestGoToIsFreeInLists(t *testing.T) {
+	taken := listKeys()
+	for _, s := range NewListKeyMap().GoTo.Keys() {
+		if use, ok := taken[s]; ok {
+			t.Errorf("go to key %q is taken by the lists for %q", s, use)
+		}
+	}
+}
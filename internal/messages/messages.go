@@ -1,40 +1,180 @@
 package messages
 
 import (
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/favorites"
+
 	"github.com/aws/aws-sdk-go/service/batch"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/sfn"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Page is embedded in the result of every streamed list fetch. A fetch runs
+// one or more page chains (one per region, or per job status); Next fetches
+// the following page of the same chain and is nil on its last page. Err is
+// set, on a last page, when the chain failed.
+type Page struct {
+	Next tea.Cmd
+	Err  error
+}
+
+// Last reports whether this is the final page of its chain.
+func (p Page) Last() bool {
+	return p.Next == nil
+}
+
+// Paging returns the page itself. It lets code that does not know the
+// concrete message type follow a chain through the Paged interface.
+func (p Page) Paging() Page {
+	return p
+}
+
+// Paged is implemented by every message that embeds Page.
+type Paged interface {
+	Paging() Page
+}
+
+// Wait is embedded in the updates of a waiter that tracks a resource after
+// a mutation. Next polls the resource again and is nil once waiting is over;
+// Err is then set if the resource failed to settle or the wait timed out.
+type Wait struct {
+	Next tea.Cmd
+	Err  error
+}
+
+// Done reports whether this is the waiter's final update.
+func (w Wait) Done() bool {
+	return w.Next == nil
+}
+
+// BulkResult is the outcome of a bulk action for one of its resources,
+// identified by its ID in the API the action called.
+type BulkResult struct {
+	Region string
+	ID     string
+	Err    error
+}
+
 // messages are used to pass data between commands and the Update function.
 type (
-	InstancesFetchedMsg []*ec2.Instance
-	InstanceActionMsg   string
-	InstanceDetailsMsg  *ec2.Instance
+	InstancesFetchedMsg struct {
+		Page
+		Region    string
+		Instances []*ec2.Instance
+	}
+	InstanceActionMsg  string
+	InstanceDetailsMsg *ec2.Instance
+	InstanceWaitMsg    struct {
+		Wait
+		Region     string
+		InstanceID string
+		Instance   *ec2.Instance
+	}
 
-	EcsClustersFetchedMsg    []*ecs.Cluster
-	EcsServicesFetchedMsg    []*ecs.Service
+	EcsClustersFetchedMsg struct {
+		Page
+		Region   string
+		Clusters []*ecs.Cluster
+	}
+	EcsServicesFetchedMsg struct {
+		Page
+		Services []*ecs.Service
+	}
 	EcsServiceDetailsMsg     *ecs.Service
 	EcsServiceActionMsg      string
 	EcsServiceLogsFetchedMsg string
+	EcsTaskDetailsMsg        *ecs.Task
+	EcsTaskDefinitionMsg     *ecs.TaskDefinition
+	EcsServiceWaitMsg        struct {
+		Wait
+		ServiceArn string
+		Service    *ecs.Service
+	}
 
-	EcrRepositoriesFetchedMsg []*ecr.Repository
-	EcrImagesFetchedMsg       []*ecr.ImageDetail
-	EcrImageActionMsg         string
+	EcrRepositoriesFetchedMsg struct {
+		Page
+		Region       string
+		Repositories []*ecr.Repository
+	}
+	EcrImagesFetchedMsg struct {
+		Page
+		Images []*ecr.ImageDetail
+	}
+	EcrImageActionMsg string
 
-	SfnStateMachinesFetchedMsg    []*sfn.StateMachineListItem
-	SfnExecutionsFetchedMsg       []*sfn.ExecutionListItem
+	SfnStateMachinesFetchedMsg struct {
+		Page
+		Region        string
+		StateMachines []*sfn.StateMachineListItem
+	}
+	SfnExecutionsFetchedMsg struct {
+		Page
+		Executions []*sfn.ExecutionListItem
+	}
 	SfnExecutionHistoryFetchedMsg []*sfn.HistoryEvent
 	SfnExecutionStartedMsg        string
 
-	BatchJobQueuesFetchedMsg []*batch.JobQueueDetail
-	BatchJobsFetchedMsg      []*batch.JobSummary
-	BatchJobDetailsMsg       *batch.JobDetail
-	BatchJobActionMsg        string
-	BatchJobLogsFetchedMsg   string
+	BatchJobQueuesFetchedMsg struct {
+		Page
+		Region    string
+		JobQueues []*batch.JobQueueDetail
+	}
+	BatchJobsFetchedMsg struct {
+		Page
+		Jobs []*batch.JobSummary
+	}
+	BatchJobDetailsMsg     *batch.JobDetail
+	BatchJobActionMsg      string
+	BatchJobLogsFetchedMsg string
+	BatchJobWaitMsg        struct {
+		Wait
+		JobID string
+		Job   *batch.JobDetail
+	}
+
+	RegionsFetchedMsg []string
+	CallerIdentityMsg struct {
+		Profile string
+		Account string
+		Arn     string
+	}
+
+	AuditLogFetchedMsg []audit.Entry
+	APICallsFetchedMsg []apilog.Call
+
+	FavoritesFetchedMsg []favorites.Favorite
+	// FavoriteStatusMsg is the current status of the favorite with Key, or
+	// the error looking it up.
+	FavoriteStatusMsg struct {
+		Key    string
+		Status string
+		Err    error
+	}
+
+	// ProgressMsg is an update of a long-running command, such as a docker
+	// push. Next delivers the following update, and eventually the
+	// command's result.
+	ProgressMsg struct {
+		Text string
+		Next tea.Cmd
+	}
+
+	// BulkActionMsg is the result of an action applied to several
+	// resources at once, such as stopping the marked instances. Action is
+	// what was done to them, e.g. "stopped".
+	BulkActionMsg struct {
+		Action  string
+		Results []BulkResult
+	}
 
-	SshExitMsg struct{ Err error }
-	ErrMsg     error
+	SshExitMsg   struct{ Err error }
+	LoginDoneMsg struct {
+		Profile string
+		Err     error
+	}
+	ErrMsg error
 )
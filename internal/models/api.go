@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// apiModel lists the AWS requests made in this session, newest first. The
+// list filter matches the service, operation, region, parameters, request
+// ID and error code.
+type apiModel struct {
+	calls    *apilog.Recorder
+	callList list.Model
+	detail   *apilog.Call
+	status   string
+	keys     *keys.ListKeyMap
+	nav      *navigation
+}
+
+func (m apiModel) Init() tea.Cmd {
+	return commands.FetchAPICallsCmd(m.calls)
+}
+
+func (m apiModel) Update(msg tea.Msg) (apiModel, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.callList.SetSize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		if m.callList.FilterState() == list.Filtering {
+			break
+		}
+		if m.detail != nil {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, m.keys.Refresh):
+			m.status = "Loading API calls..."
+			return m, m.Init()
+		case key.Matches(msg, m.keys.Details), key.Matches(msg, m.keys.Choose):
+			if m.callList.SelectedItem() != nil {
+				call := m.callList.SelectedItem().(apiCallItem).call
+				m.detail = &call
+				m.nav.push(stateAPI, screenDetails, call.Service+" "+call.Operation)
+			}
+			return m, nil
+		}
+	case messages.APICallsFetchedMsg:
+		items := make([]list.Item, len(msg))
+		for i, c := range msg {
+			items[i] = apiCallItem{call: c}
+		}
+		cmd = m.callList.SetItems(items)
+		m.status = "Ready"
+		return m, cmd
+	}
+	m.callList, cmd = m.callList.Update(msg)
+	return m, cmd
+}
+
+// show returns to a screen opened before.
+func (m apiModel) show(screen int) apiModel {
+	if screen == screenList {
+		m.detail = nil
+	}
+	m.status = "Ready"
+	return m
+}
+
+func (m apiModel) View() string {
+	if m.detail != nil {
+		c := m.detail
+		var b strings.Builder
+		fmt.Fprintf(&b, "Time: %s\n", c.Time.Local().Format(time.DateTime+".000"))
+		fmt.Fprintf(&b, "Service: %s\n", c.Service)
+		fmt.Fprintf(&b, "Operation: %s\n", c.Operation)
+		fmt.Fprintf(&b, "Region: %s\n", c.Region)
+		fmt.Fprintf(&b, "Parameters: %s\n", c.Params)
+		fmt.Fprintf(&b, "Latency: %s\n", c.Latency.Round(time.Millisecond))
+		fmt.Fprintf(&b, "Retries: %d\n", c.Retries)
+		fmt.Fprintf(&b, "Request ID: %s\n", c.RequestID)
+		if c.Error != "" {
+			fmt.Fprintf(&b, "Error Code: %s\n", c.ErrorCode)
+			fmt.Fprintf(&b, "Error: %s\n", c.Error)
+		}
+		fmt.Fprintf(&b, "\nPress '%s' to go back.", m.keys.Back.Help().Key)
+		return "\n" + styles.DetailStyle.Render(b.String())
+	}
+	if len(m.callList.Items()) == 0 && m.status == "Ready" {
+		return "No AWS requests made yet.\n"
+	}
+	return m.callList.View()
+}
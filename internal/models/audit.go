@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// auditModel browses the audit log, newest action first. The list filter
+// matches the action, resource, profile, region, caller and result.
+type auditModel struct {
+	log       *audit.Log
+	entryList list.Model
+	detail    *audit.Entry
+	status    string
+	err       error
+	keys      *keys.ListKeyMap
+	nav       *navigation
+}
+
+func (m auditModel) Init() tea.Cmd {
+	return commands.FetchAuditLogCmd(m.log)
+}
+
+func (m auditModel) Update(msg tea.Msg) (auditModel, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.entryList.SetSize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		if m.entryList.FilterState() == list.Filtering {
+			break
+		}
+		if m.detail != nil {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, m.keys.Refresh):
+			m.status = "Loading audit log..."
+			return m, m.Init()
+		case key.Matches(msg, m.keys.Details), key.Matches(msg, m.keys.Choose):
+			if m.entryList.SelectedItem() != nil {
+				entry := m.entryList.SelectedItem().(auditItem).entry
+				m.detail = &entry
+				m.nav.push(stateAudit, screenDetails, entry.Action)
+			}
+			return m, nil
+		}
+	case messages.AuditLogFetchedMsg:
+		items := make([]list.Item, len(msg))
+		for i, e := range msg {
+			items[i] = auditItem{entry: e}
+		}
+		cmd = m.entryList.SetItems(items)
+		m.status = "Ready"
+		m.err = nil
+		return m, cmd
+	}
+	m.entryList, cmd = m.entryList.Update(msg)
+	return m, cmd
+}
+
+// show returns to a screen opened before.
+func (m auditModel) show(screen int) auditModel {
+	if screen == screenList {
+		m.detail = nil
+	}
+	m.status = "Ready"
+	return m
+}
+
+func (m auditModel) View() string {
+	if m.detail != nil {
+		b, _ := json.MarshalIndent(m.detail, "", "  ")
+		return "\n" + styles.DetailStyle.Render(fmt.Sprintf("%s\n\nPress '%s' to go back.", b, m.keys.Back.Help().Key))
+	}
+	if len(m.entryList.Items()) == 0 && m.status == "Ready" {
+		where := "memory"
+		if m.log.Path() != "" {
+			where = m.log.Path()
+		}
+		return fmt.Sprintf("No actions recorded yet in %s.\n", where)
+	}
+	return m.entryList.View()
+}
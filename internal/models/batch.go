@@ -1,10 +1,14 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/commands"
 	"github.com/theoreticallyjosh/awstui/internal/keys"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
@@ -12,7 +16,6 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/batch"
-	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/paginator"
@@ -29,29 +32,38 @@ const (
 )
 
 type batchModel struct {
-	parent            *Model
-	batchSvc          *batch.Batch
-	cloudwatchlogsSvc *cloudwatchlogs.CloudWatchLogs
-	jobQueueList      list.Model
-	jobList           list.Model
-	status            string
-	err               error
-	keys              *keys.ListKeyMap
-	paginator         paginator.Model
-	state             batchState
-	header            []string
-	detailJobQueue    *batch.JobQueueDetail
-	detailJob         *batch.JobDetail
-	jobLogs           string
-	confirming        bool
-	action            string
-	actionID          *string
-	getLogs           bool
+	parent         *Model
+	pool           *clients.Pool
+	regions        []string
+	regionClients  *clients.Clients
+	jobQueueList   list.Model
+	jobList        list.Model
+	jobMarks       *selection
+	jobTable       *table
+	jobQueueLoader listLoader
+	jobLoader      listLoader
+	status         string
+	err            error
+	keys           *keys.ListKeyMap
+	paginator      paginator.Model
+	state          batchState
+	nav            *navigation
+	detailJobQueue *batch.JobQueueDetail
+	detailJob      *batch.JobDetail
+	jobLogs        string
+	confirm        confirmDialog
+	guard          *guard
+	scope          *scope
+	notes          *notifier
+	target         *batch.JobSummary
+	getLogs        bool
+	jumpTo         []string
 }
 
 // item delegates
 type batchJobQueueItem struct {
 	jobQueue *batch.JobQueueDetail
+	region   string
 }
 
 func (i batchJobQueueItem) Title() string {
@@ -59,11 +71,20 @@ func (i batchJobQueueItem) Title() string {
 }
 
 func (i batchJobQueueItem) Description() string {
-	return fmt.Sprintf("Status: %s", aws.StringValue(i.jobQueue.Status))
+	return fmt.Sprintf("Status: %s | Region: %s", aws.StringValue(i.jobQueue.Status), i.region)
 }
 
 func (i batchJobQueueItem) FilterValue() string {
-	return aws.StringValue(i.jobQueue.JobQueueName)
+	return fields(
+		field{"name", aws.StringValue(i.jobQueue.JobQueueName)},
+		field{"state", aws.StringValue(i.jobQueue.State)},
+		field{"status", aws.StringValue(i.jobQueue.Status)},
+		field{"region", i.region},
+	)
+}
+
+func (i batchJobQueueItem) key() string {
+	return aws.StringValue(i.jobQueue.JobQueueArn)
 }
 
 type batchJobItem struct {
@@ -79,16 +100,34 @@ func (i batchJobItem) Description() string {
 }
 
 func (i batchJobItem) FilterValue() string {
-	return aws.StringValue(i.job.JobName)
+	return fields(
+		field{"name", aws.StringValue(i.job.JobName)},
+		field{"id", aws.StringValue(i.job.JobId)},
+		field{"status", aws.StringValue(i.job.Status)},
+		field{"reason", aws.StringValue(i.job.StatusReason)},
+	)
+}
+
+func (i batchJobItem) key() string {
+	return aws.StringValue(i.job.JobId)
 }
 
-func (m batchModel) Init() tea.Cmd {
-	return tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobQueuesCmd(m.batchSvc))
+// loadJobQueues starts loading the job queues, abandoning the load in
+// progress.
+func (m batchModel) loadJobQueues() (batchModel, tea.Cmd) {
+	cmd := m.jobQueueLoader.load(commands.FetchBatchJobQueuesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
+}
+
+// loadJobs starts loading the jobs of the job queue on show, abandoning the
+// load in progress.
+func (m batchModel) loadJobs() (batchModel, tea.Cmd) {
+	cmd := m.jobLoader.load(commands.FetchBatchJobsCmd(m.scope.context(), m.regionClients.Batch, m.detailJobQueue.JobQueueName))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
 }
 
 func (m batchModel) Update(msg tea.Msg) (batchModel, tea.Cmd) {
 	var cmd tea.Cmd
-	m.header = []string{"Batch Job Queues"}
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -96,47 +135,19 @@ func (m batchModel) Update(msg tea.Msg) (batchModel, tea.Cmd) {
 		m.jobQueueList.SetSize(msg.Width, msg.Height)
 		m.jobList.SetSize(msg.Width, msg.Height)
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back"))):
-			if m.state == batchStateJobList {
-				m.state = batchStateJobQueueList
-				m.status = "Ready"
+		if m.confirm.visible {
+			var result confirmResult
+			m.confirm, result = m.confirm.update(msg, m.keys)
+			switch result {
+			case confirmAccepted:
+				m.status = m.confirm.running
 				m.err = nil
-				m.jobList.SetItems([]list.Item{})
-				return m, nil
-			} else if m.state == batchStateJobDetails {
-				m.state = batchStateJobList
-				m.status = "Ready"
-				m.err = nil
-				m.detailJob = nil
-				return m, nil
-			} else if m.state == batchStateJobLogs {
-				m.state = batchStateJobList
-				m.status = "Ready"
-				m.err = nil
-				m.jobLogs = ""
-				return m, nil
-			}
-		}
-		if m.confirming {
-			switch msg.String() {
-			case "y", "Y":
-				m.confirming = false
-				m.status = fmt.Sprintf("%sing job %s...", m.action, *m.actionID)
-				m.err = nil
-				if m.action == "stop" {
-					reason := "Terminated by user"
-					return m, tea.Batch(m.parent.spinner.Tick, commands.StopBatchJobCmd(m.batchSvc, m.actionID, &reason))
-				}
-			case "n", "N":
-				m.confirming = false
+				return m, tea.Batch(m.parent.spinner.Tick, m.confirm.accept)
+			case confirmRejected:
 				m.status = "Ready"
-				m.action = ""
-				m.state = batchStateJobList
 			}
 			return m, nil
 		}
-
 		switch m.state {
 		case batchStateJobQueueList:
 			if m.jobQueueList.FilterState() == list.Filtering {
@@ -146,78 +157,117 @@ func (m batchModel) Update(msg tea.Msg) (batchModel, tea.Cmd) {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = "Refreshing Batch job queues..."
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobQueuesCmd(m.batchSvc))
+				return m.loadJobQueues()
 			case key.Matches(msg, m.keys.Choose):
 				if m.jobQueueList.SelectedItem() != nil {
-					selectedItem := m.jobQueueList.SelectedItem().(batchJobQueueItem)
-					m.detailJobQueue = selectedItem.jobQueue
-					m.state = batchStateJobList
-					m.status = fmt.Sprintf("Loading jobs for job queue %s...", aws.StringValue(m.detailJobQueue.JobQueueName))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobsCmd(m.batchSvc, m.detailJobQueue.JobQueueName))
+					return m.openJobQueue(m.jobQueueList.SelectedItem().(batchJobQueueItem))
 				}
 			}
 		case batchStateJobList:
-			m.header = append(m.header, aws.StringValue(m.detailJobQueue.JobQueueName), "Jobs")
 			if m.jobList.FilterState() == list.Filtering {
 				break
 			}
+			if m.jobMarks.update(msg, m.keys, &m.jobList) || m.jobTable.update(msg, m.keys, &m.jobList) {
+				return m, nil
+			}
 			switch {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = fmt.Sprintf("Refreshing jobs for job queue %s...", aws.StringValue(m.detailJobQueue.JobQueueName))
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobsCmd(m.batchSvc, m.detailJobQueue.JobQueueName))
+				return m.loadJobs()
 			case key.Matches(msg, m.keys.Stop):
-				if m.jobList.SelectedItem() != nil {
+				if marked := m.jobMarks.marked(m.jobList); len(marked) > 0 {
+					if err := m.guard.check("stop jobs"); err != nil {
+						m.status = blocked(err)
+						break
+					}
+					m = m.confirmBulk(marked)
+				} else if m.jobList.SelectedItem() != nil {
 					selectedItem := m.jobList.SelectedItem().(batchJobItem)
 					selectedJob := selectedItem.job
-					m.confirming = true
-					m.action = "stop"
-					m.actionID = selectedJob.JobId
-					m.status = fmt.Sprintf("Confirm stopping job %s (%s)? (y/N)",
-						aws.StringValue(selectedJob.JobName), aws.StringValue(selectedJob.JobId))
+					if err := m.guard.check("stop jobs"); err != nil {
+						m.status = blocked(err)
+						break
+					}
+					reason := "Terminated by user"
+					m.target = selectedJob
+					m.confirm = m.guard.open(confirmDialog{
+						title:    "Stop job",
+						resource: fmt.Sprintf("%s (%s)", aws.StringValue(selectedJob.JobName), aws.StringValue(selectedJob.JobId)),
+						name:     aws.StringValue(selectedJob.JobName),
+						region:   m.regionClients.Region,
+						changes:  []change{{"Status", aws.StringValue(selectedJob.Status), batch.JobStatusFailed}},
+						accept: m.guard.audited("batch:TerminateJob", m.regionClients.Region, aws.StringValue(selectedJob.JobArn),
+							map[string]string{"reason": reason},
+							commands.StopBatchJobCmd(context.Background(), m.regionClients.Batch, selectedJob.JobId, &reason)),
+						running: fmt.Sprintf("Stopping job %s...", aws.StringValue(selectedJob.JobId)),
+					})
 				}
 			case key.Matches(msg, m.keys.Logs):
 				if m.jobList.SelectedItem() != nil {
 					m.getLogs = true
 					selectedItem := m.jobList.SelectedItem().(batchJobItem)
 					m.status = fmt.Sprintf("Fetching logs for job %s...", aws.StringValue(selectedItem.job.JobName))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobDetailsCmd(m.batchSvc, selectedItem.job.JobId))
+					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobDetailsCmd(m.scope.context(), m.regionClients.Batch, selectedItem.job.JobId))
 				}
 			case key.Matches(msg, m.keys.Details):
 				if m.jobList.SelectedItem() != nil {
 					selectedItem := m.jobList.SelectedItem().(batchJobItem)
 					m.status = fmt.Sprintf("Fetching details for job %s...", aws.StringValue(selectedItem.job.JobName))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobDetailsCmd(m.batchSvc, selectedItem.job.JobId))
+					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobDetailsCmd(m.scope.context(), m.regionClients.Batch, selectedItem.job.JobId))
 				}
 
 			}
 		case batchStateJobDetails:
 			// No key handling in these states for now
 		case batchStateJobLogs:
-			m.header = append(m.header, aws.StringValue(m.detailJobQueue.JobQueueName), m.jobList.SelectedItem().FilterValue(), "Logs")
 			m.paginator, cmd = m.paginator.Update(msg)
 			return m, cmd
 		}
 
+	case pageMsg:
+		if !m.jobQueueLoader.current(msg) && !m.jobLoader.current(msg) {
+			return m, nil
+		}
+		return m.Update(msg.msg)
 	case messages.BatchJobQueuesFetchedMsg:
-		listItems := make([]list.Item, len(msg))
-		for i, jobQueue := range msg {
-			listItems[i] = batchJobQueueItem{jobQueue: jobQueue}
+		listItems := make([]list.Item, len(msg.JobQueues))
+		for i, jobQueue := range msg.JobQueues {
+			listItems[i] = batchJobQueueItem{jobQueue: jobQueue, region: msg.Region}
 		}
-		m.jobQueueList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.jobQueueLoader.add(&m.jobQueueList, listItems, msg.Page)
+		m.status = m.jobQueueLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.jobQueueLoader.loading() {
+			var jumpCmd tea.Cmd
+			m, jumpCmd = m.jumpToJobQueue()
+			return m, tea.Batch(cmd, jumpCmd)
+		}
+		return m, cmd
 	case messages.BatchJobsFetchedMsg:
-		m.header = append(m.header, m.jobQueueList.SelectedItem().FilterValue(), "Jobs")
-		listItems := make([]list.Item, len(msg))
-		for i, job := range msg {
+		listItems := make([]list.Item, len(msg.Jobs))
+		for i, job := range msg.Jobs {
 			listItems[i] = batchJobItem{job: job}
 		}
-		m.jobList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.jobLoader.add(&m.jobList, listItems, msg.Page)
+		// Jobs of every status arrive interleaved; keep the newest first.
+		jobs := slices.Clone(m.jobList.Items())
+		sort.SliceStable(jobs, func(i, j int) bool {
+			return aws.Int64Value(jobs[i].(batchJobItem).job.CreatedAt) > aws.Int64Value(jobs[j].(batchJobItem).job.CreatedAt)
+		})
+		setItems(&m.jobList, jobs)
+		m.jobTable.sort(&m.jobList)
+		m.status = m.jobLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.jobLoader.loading() {
+			if err := jumpSelect(&m.jobList, "job", m.jumpTo[0]); err != nil {
+				m.err = err
+				m.status = fmt.Sprintf("Error: %v", err)
+				m.notes.failure(err)
+			}
+			m.jumpTo = nil
+		}
+		return m, cmd
 	case messages.BatchJobDetailsMsg:
 		m.detailJob = msg
 		if msg.Container == nil {
@@ -225,33 +275,68 @@ func (m batchModel) Update(msg tea.Msg) (batchModel, tea.Cmd) {
 			m.err = fmt.Errorf("container is nil")
 			return m, nil
 		}
-		m.state = batchStateJobDetails
 		if m.getLogs {
 			m.getLogs = false
 			m.state = batchStateJobLogs
-			return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobLogsCmd(m.cloudwatchlogsSvc, m.detailJob.Container.LogStreamName))
+			m.nav.push(stateBatch, int(batchStateJobLogs), aws.StringValue(msg.JobName), "Logs")
+			svc, stream := m.regionClients.Logs, m.detailJob.Container.LogStreamName
+			cmd := startOp(stateBatch, fmt.Sprintf("Download logs of job %s", aws.StringValue(m.detailJob.JobName)), false, func(ctx context.Context) tea.Cmd {
+				return commands.FetchBatchJobLogsCmd(ctx, svc, stream)
+			})
+			return m, tea.Batch(m.parent.spinner.Tick, cmd)
 		}
+		m.state = batchStateJobDetails
+		m.nav.push(stateBatch, int(batchStateJobDetails), aws.StringValue(msg.JobName))
 		m.status = "Ready"
 		return m, nil
 	case messages.BatchJobLogsFetchedMsg:
-		m.header = append(m.header, aws.StringValue(m.detailJobQueue.JobQueueName), m.jobList.SelectedItem().FilterValue(), "Logs")
 		m.jobLogs = string(msg)
 		m.paginator.SetTotalPages(len(strings.Split(m.jobLogs, "\n")))
 		m.status = "Ready"
 		m.err = nil
 		return m, nil
 	case messages.BatchJobActionMsg:
-		m.status = fmt.Sprintf("Job %s %s. Refreshing...", *m.actionID, msg)
+		m.status = fmt.Sprintf("Job %s %s. Waiting for it to finish...", aws.StringValue(m.target.JobId), msg)
 		m.err = nil
-		m.action = ""
-		m.actionID = nil
-		m.confirming = false
-		return m, tea.Batch(m.parent.spinner.Tick, commands.FetchBatchJobsCmd(m.batchSvc, m.detailJobQueue.JobQueueName))
+		return m, tea.Batch(m.parent.spinner.Tick, m.wait(aws.StringValue(m.target.JobId)))
+	case messages.BulkActionMsg:
+		summary, err := reportBulk(m.notes, msg, "job", m.jobName)
+		var waits []tea.Cmd
+		for _, r := range msg.Results {
+			// The job queue may have been left in the meantime.
+			if r.Err == nil && listed(m.jobList, r.ID) {
+				m.jobMarks.unmark(r.ID)
+				waits = append(waits, m.wait(r.ID))
+			}
+		}
+		switch {
+		case err != nil:
+			m.status = "Error: " + summary
+		case len(waits) > 0:
+			m.status = fmt.Sprintf("%s. Waiting for them to finish...", summary)
+		}
+		return m, tea.Batch(m.parent.spinner.Tick, tea.Batch(waits...))
+	case messages.BatchJobWaitMsg:
+		if msg.Job != nil {
+			m.updateJob(msg.Job)
+			m.jobTable.sort(&m.jobList)
+		}
+		switch {
+		case msg.Err != nil:
+			m.err = fmt.Errorf("waiting for job %s: %w", msg.JobID, msg.Err)
+			m.status = fmt.Sprintf("Error: %v", m.err)
+			m.notes.failure(m.err)
+		case msg.Done():
+			m.status = "Ready"
+			m.notes.success("Job %s is %s", msg.JobID, aws.StringValue(msg.Job.Status))
+		default:
+			m.status = fmt.Sprintf("Job %s is %s...", msg.JobID, aws.StringValue(msg.Job.Status))
+		}
+		return m, nil
 	case messages.ErrMsg:
 		m.err = msg
 		m.status = "Error"
-		m.confirming = false
-		m.action = ""
+		m.confirm.visible = false
 		m.detailJob = nil
 		m.jobLogs = ""
 		return m, nil
@@ -270,7 +355,7 @@ func (m batchModel) View() string {
 	switch m.state {
 	case batchStateJobQueueList:
 		if len(m.jobQueueList.Items()) == 0 && m.status == "Ready" {
-			s = "No Batch job queues found in this region.\n"
+			s = "No Batch job queues found in the selected regions.\n"
 		} else {
 			s = m.jobQueueList.View()
 		}
@@ -287,7 +372,7 @@ func (m batchModel) View() string {
 					fmt.Sprintf("Job ID:        %s\n", aws.StringValue(m.detailJob.JobId))+
 					fmt.Sprintf("Status:        %s\n", aws.StringValue(m.detailJob.Status))+
 					fmt.Sprintf("Created At:    %s\n", time.Unix(aws.Int64Value(m.detailJob.CreatedAt)/1000, 0).Format(time.RFC822))+
-					fmt.Sprintf("Stopped At:    %s\n", time.Unix(aws.Int64Value(m.detailJob.StoppedAt)/1000, 0).Format(time.RFC822))+"\nPress 'esc' or 'backspace' to go back."+"\n",
+					fmt.Sprintf("Stopped At:    %s\n", time.Unix(aws.Int64Value(m.detailJob.StoppedAt)/1000, 0).Format(time.RFC822))+fmt.Sprintf("\nPress '%s' to go back.\n", m.keys.Back.Help().Key),
 			)
 		} else {
 			s = styles.StatusStyle.Render("No job details available.\n")
@@ -303,8 +388,154 @@ func (m batchModel) View() string {
 			}
 		}
 		s += m.paginator.View()
-		s += "\n" + styles.HelpStyle.Render("Press 'esc' or 'backspace' to go back.")
+		s += "\n" + styles.HelpStyle.Render(fmt.Sprintf("Press '%s' to go back.", m.keys.Back.Help().Key))
 	}
 
 	return s
 }
+
+// openJobQueue shows the jobs of a job queue.
+func (m batchModel) openJobQueue(selectedItem batchJobQueueItem) (batchModel, tea.Cmd) {
+	m.detailJobQueue = selectedItem.jobQueue
+	m.jobMarks.clear()
+	m.regionClients = m.pool.Region(selectedItem.region)
+	m.state = batchStateJobList
+	m.nav.push(stateBatch, int(batchStateJobList), aws.StringValue(m.detailJobQueue.JobQueueName), "Jobs")
+	m.status = fmt.Sprintf("Loading jobs for job queue %s...", aws.StringValue(m.detailJobQueue.JobQueueName))
+	return m.loadJobs()
+}
+
+// show returns to a screen opened before, dropping what the screens above it
+// loaded.
+func (m batchModel) show(state batchState) batchModel {
+	switch state {
+	case batchStateJobQueueList:
+		m.jobList.SetItems([]list.Item{})
+		m.jobMarks.clear()
+		fallthrough
+	case batchStateJobList:
+		m.detailJob = nil
+		m.jobLogs = ""
+	}
+	m.state = state
+	m.status = "Ready"
+	m.err = nil
+	return m
+}
+
+// jump shows the job queue list and, once it has loaded, opens the job queue
+// and selects the job named by path.
+func (m batchModel) jump(path []string) (batchModel, tea.Cmd) {
+	m.state = batchStateJobQueueList
+	m.confirm.visible = false
+	m.jumpTo = path
+	if m.jobQueueLoader.loading() {
+		return m, nil
+	}
+	m.status = "Loading job queues..."
+	return m.loadJobQueues()
+}
+
+// jumpToJobQueue opens the job queue a jump is headed for.
+func (m batchModel) jumpToJobQueue() (batchModel, tea.Cmd) {
+	if err := jumpSelect(&m.jobQueueList, "job queue", m.jumpTo[0]); err != nil {
+		m.jumpTo = nil
+		m.err = err
+		m.status = fmt.Sprintf("Error: %v", err)
+		m.notes.failure(err)
+		return m, nil
+	}
+	m.jumpTo = m.jumpTo[1:]
+	return m.openJobQueue(m.jobQueueList.SelectedItem().(batchJobQueueItem))
+}
+
+// resume reloads the current list if leaving another screen cut its load
+// short.
+func (m batchModel) resume() (batchModel, tea.Cmd) {
+	if m.state == batchStateJobQueueList && m.jobQueueLoader.interrupted || m.state == batchStateJobList && m.jobLoader.interrupted {
+		return m.autoRefresh()
+	}
+	return m, nil
+}
+
+// autoRefresh reloads the current list in the background, unless the user
+// is busy with it.
+func (m batchModel) autoRefresh() (batchModel, tea.Cmd) {
+	if m.confirm.visible {
+		return m, nil
+	}
+	switch {
+	case m.state == batchStateJobQueueList && !m.jobQueueLoader.loading():
+		return m, m.jobQueueLoader.reload(commands.FetchBatchJobQueuesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	case m.state == batchStateJobList && !m.jobLoader.loading():
+		return m, m.jobLoader.reload(commands.FetchBatchJobsCmd(m.scope.context(), m.regionClients.Batch, m.detailJobQueue.JobQueueName))
+	}
+	return m, nil
+}
+
+// wait starts a background operation waiting for a job to finish.
+func (m batchModel) wait(jobID string) tea.Cmd {
+	svc := m.regionClients.Batch
+	return startOp(stateBatch, fmt.Sprintf("Wait for job %s to finish", jobID), true, func(ctx context.Context) tea.Cmd {
+		return commands.WaitForBatchJobCmd(ctx, svc, aws.String(jobID))
+	})
+}
+
+// confirmBulk asks to stop the marked jobs.
+func (m batchModel) confirmBulk(marked []list.Item) batchModel {
+	reason := "Terminated by user"
+	ids := make([]string, len(marked))
+	arns := map[string]string{}
+	rows := make([]change, len(marked))
+	for i, listItem := range marked {
+		job := listItem.(batchJobItem).job
+		ids[i] = aws.StringValue(job.JobId)
+		arns[ids[i]] = aws.StringValue(job.JobArn)
+		rows[i] = change{fmt.Sprintf("%s (%s)", aws.StringValue(job.JobName), ids[i]), aws.StringValue(job.Status), batch.JobStatusFailed}
+	}
+	n := count(len(marked), "job")
+	rc, guard := m.regionClients, m.guard
+	m.confirm = m.guard.open(confirmDialog{
+		title:    "Stop " + n,
+		resource: fmt.Sprintf("%s of job queue %s", n, aws.StringValue(m.detailJobQueue.JobQueueName)),
+		name:     n,
+		region:   rc.Region,
+		changes:  bulkChanges(rows),
+		accept: startOp(stateBatch, "Stop "+n, true, func(ctx context.Context) tea.Cmd {
+			return guard.auditedBulk("batch:TerminateJob", map[string]string{"reason": reason}, func(r messages.BulkResult) string {
+				return arns[r.ID]
+			}, commands.StopBatchJobsCmd(ctx, rc, ids, reason))
+		}),
+		running: fmt.Sprintf("Stopping %s...", n),
+	})
+	return m
+}
+
+// jobName names job id for the user, by its name if it is still listed.
+func (m batchModel) jobName(r messages.BulkResult) string {
+	for _, listItem := range m.jobList.Items() {
+		if job := listItem.(batchJobItem).job; aws.StringValue(job.JobId) == r.ID {
+			return fmt.Sprintf("%s (%s)", aws.StringValue(job.JobName), r.ID)
+		}
+	}
+	return r.ID
+}
+
+// updateJob replaces the row of a job with the summary of fresh details.
+func (m *batchModel) updateJob(job *batch.JobDetail) {
+	for i, listItem := range m.jobList.Items() {
+		if aws.StringValue(listItem.(batchJobItem).job.JobId) == aws.StringValue(job.JobId) {
+			m.jobList.SetItem(i, batchJobItem{job: &batch.JobSummary{
+				JobArn:       job.JobArn,
+				JobId:        job.JobId,
+				JobName:      job.JobName,
+				Status:       job.Status,
+				StatusReason: job.StatusReason,
+				CreatedAt:    job.CreatedAt,
+				StartedAt:    job.StartedAt,
+				StoppedAt:    job.StoppedAt,
+			}})
+			return
+		}
+	}
+}
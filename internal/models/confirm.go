@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// change is a value an action is about to change.
+type change struct {
+	field, from, to string
+}
+
+// confirmResult is the outcome of a key press on an open dialog.
+type confirmResult int
+
+const (
+	confirmPending confirmResult = iota
+	confirmAccepted
+	confirmRejected
+)
+
+// confirmDialog is a modal asking the user to confirm an action. It shows
+// what is about to change and where. For high-risk actions, or under a
+// typed-confirmation policy, the user has to type the name of the resource
+// instead of pressing the confirm key.
+//
+// Views open it with guard.open and, once accepted, run accept and show
+// running as their status.
+type confirmDialog struct {
+	title    string
+	resource string
+	name     string
+	account  string
+	region   string
+	changes  []change
+	accept   tea.Cmd
+	running  string
+
+	// highRisk always asks for the name to be typed.
+	highRisk bool
+	// local marks actions that only change the local machine, which no
+	// policy applies to.
+	local bool
+
+	visible bool
+	typed   bool
+	input   string
+}
+
+// update handles a key press.
+func (d confirmDialog) update(msg tea.KeyMsg, k *keys.ListKeyMap) (confirmDialog, confirmResult) {
+	result := confirmPending
+	if !d.typed {
+		switch {
+		case key.Matches(msg, k.Confirm):
+			result = confirmAccepted
+		case key.Matches(msg, k.Cancel), key.Matches(msg, k.Back):
+			result = confirmRejected
+		}
+	} else {
+		switch {
+		case msg.Type == tea.KeyBackspace:
+			_, size := utf8.DecodeLastRuneInString(d.input)
+			d.input = d.input[:len(d.input)-size]
+		case msg.Type == tea.KeyRunes, msg.Type == tea.KeySpace:
+			d.input += string(msg.Runes)
+		case key.Matches(msg, k.Back):
+			result = confirmRejected
+		case key.Matches(msg, k.Choose):
+			if d.input == d.name {
+				result = confirmAccepted
+			}
+			d.input = ""
+		}
+	}
+	if result != confirmPending {
+		d.visible = false
+	}
+	return d, result
+}
+
+// hint is the status line shown while the dialog is open.
+func (d confirmDialog) hint(k *keys.ListKeyMap) string {
+	if !d.typed {
+		return fmt.Sprintf("%s? %s", d.title, k.ConfirmPrompt())
+	}
+	return fmt.Sprintf("Type %q and press %s to confirm, %s to cancel.", d.name, k.Choose.Help().Key, k.Back.Help().Key)
+}
+
+// View renders the dialog centred in an area of the given size.
+func (d confirmDialog) View(k *keys.ListKeyMap, width, height int) string {
+	var b strings.Builder
+	b.WriteString(styles.TitleStyle.Render(d.title) + "\n\n")
+	rows := []change{{field: "Resource", to: d.resource}}
+	if d.account != "" {
+		rows = append(rows, change{field: "Account", to: d.account})
+	}
+	if d.region != "" {
+		rows = append(rows, change{field: "Region", to: d.region})
+	}
+	d.rows(&b, rows)
+	if len(d.changes) > 0 {
+		b.WriteString("\n")
+		d.rows(&b, d.changes)
+	}
+	b.WriteString("\n")
+	if d.typed {
+		fmt.Fprintf(&b, "Type %s to confirm: %s_", styles.ConfirmStyle.UnsetPaddingTop().Render(d.name), d.input)
+	} else {
+		b.WriteString(d.hint(k))
+	}
+	content := strings.TrimSuffix(b.String(), "\n")
+	// Pad every line to the same width so the block is centred as a whole.
+	content = lipgloss.NewStyle().Width(lipgloss.Width(content)).Render(content)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, styles.DialogStyle.Render(content))
+}
+
+// rows writes aligned "field  value" lines, with "old → new" for changes.
+func (d confirmDialog) rows(b *strings.Builder, rows []change) {
+	w := 0
+	for _, r := range rows {
+		w = max(w, len(r.field))
+	}
+	for _, r := range rows {
+		value := r.to
+		if r.from != "" {
+			value = r.from + " → " + r.to
+		}
+		fmt.Fprintf(b, "%-*s  %s\n", w, r.field, value)
+	}
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+// selectInstance moves the EC2 list to the instance called name.
+func (tm *testModel) selectInstance(name string) ec2InstanceItem {
+	tm.t.Helper()
+	tm.command("ec2")
+	for i := 0; i < len(tm.m.ec2Model.instanceList.Items()); i++ {
+		if item, ok := tm.m.ec2Model.instanceList.SelectedItem().(ec2InstanceItem); ok && getInstanceName(item.instance) == name {
+			return item
+		}
+		tm.press("down")
+	}
+	tm.t.Fatalf("no instance %s", name)
+	return ec2InstanceItem{}
+}
+
+// instanceState is the state of an instance in the backend.
+func (tm *testModel) instanceState(region, id string) string {
+	for _, instance := range tm.backend.Region(region).Instances {
+		if aws.StringValue(instance.InstanceId) == id {
+			return aws.StringValue(instance.State.Name)
+		}
+	}
+	tm.t.Fatalf("no instance %s in %s", id, region)
+	return ""
+}
+
+// desiredCount is the desired count of a service in the backend.
+func (tm *testModel) desiredCount(name string) int64 {
+	for _, services := range tm.backend.Region("us-east-1").Services {
+		for _, s := range services {
+			if aws.StringValue(s.ServiceName) == name {
+				return aws.Int64Value(s.DesiredCount)
+			}
+		}
+	}
+	tm.t.Fatalf("no service %s", name)
+	return 0
+}
+
+func TestConfirmStopInstance(t *testing.T) {
+	tm := newTestModel(t, nil)
+	item := tm.selectInstance("web-1")
+	id := aws.StringValue(item.instance.InstanceId)
+
+	tm.press("s")
+	tm.wantView("Stop instance", "running → stopped", "us-east-1")
+	tm.press("n")
+	tm.wantNoView("Stop instance")
+	if got := tm.instanceState("us-east-1", id); got != "running" {
+		t.Fatalf("instance is %s after the dialog was cancelled", got)
+	}
+
+	tm.press("s", "y")
+	if got := tm.instanceState("us-east-1", id); got != "stopped" {
+		t.Fatalf("instance is %s after the dialog was accepted", got)
+	}
+	// The waiter updates the instance in place once it has stopped.
+	item = tm.m.ec2Model.instanceList.SelectedItem().(ec2InstanceItem)
+	if got := aws.StringValue(item.instance.State.Name); got != "stopped" {
+		t.Fatalf("instance listed as %s after stopping", got)
+	}
+	if entries, _ := tm.log.Entries(); len(entries) != 1 || entries[0].Action != "ec2:StopInstances" {
+		t.Fatalf("audit log = %+v, want the stop", entries)
+	}
+}
+
+func TestConfirmUsesRemappedKeys(t *testing.T) {
+	tm := newTestModel(t, &config.Config{Keys: map[string]config.KeyList{"stop": {"S"}, "confirm": {"Y"}}})
+	tm.selectInstance("web-1")
+
+	tm.press("s")
+	tm.wantNoView("Stop instance")
+	tm.press("S")
+	tm.wantView("Stop instance? (Y/N)")
+	tm.press("y")
+	tm.wantView("Stop instance? (Y/N)")
+	tm.press("Y")
+	tm.wantNoView("Stop instance")
+}
+
+func TestConfirmStopServiceMustBeTyped(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ecs demo")
+	name := aws.StringValue(tm.m.ecsModel.serviceList.SelectedItem().(ecsServiceItem).service.ServiceName)
+
+	tm.press("s")
+	tm.wantView("Stop service", "Type "+name)
+	// The confirm key is typed into the dialog rather than accepting it.
+	tm.press("y", "enter")
+	if tm.desiredCount(name) == 0 {
+		t.Fatal("service stopped without its name being typed")
+	}
+	tm.wantView("Stop service", "Type "+name+" to confirm: _")
+
+	for _, r := range name + "x" {
+		tm.press(string(r))
+	}
+	tm.press("backspace")
+	tm.wantView("Type "+name, name+"_")
+	tm.press("enter")
+	if n := tm.desiredCount(name); n != 0 {
+		t.Fatalf("service %s still wants %d tasks", name, n)
+	}
+}
+
+func TestConfirmReadOnlyBlocksActions(t *testing.T) {
+	tm := newTestModel(t, &config.Config{ReadOnly: true})
+	item := tm.selectInstance("web-1")
+
+	tm.press("s")
+	tm.wantNoView("Stop instance")
+	tm.wantView("Blocked:")
+	if got := tm.instanceState("us-east-1", aws.StringValue(item.instance.InstanceId)); got != "running" {
+		t.Fatalf("instance is %s under a read-only config", got)
+	}
+}
+
+func TestConfirmPullUntaggedImage(t *testing.T) {
+	tm := newTestModel(t, nil)
+	r := tm.backend.Region("us-east-1")
+	r.Images["api"] = append(r.Images["api"], &ecr.ImageDetail{
+		RepositoryName: aws.String("api"),
+		ImageDigest:    aws.String("sha256:3333"),
+		ImagePushedAt:  aws.Time(time.Now()),
+	})
+	tm.command("ecr")
+	tm.press("enter")
+	for i := 0; i < 3 && tm.m.ecrModel.imageList.SelectedItem().(ecrImageItem).ref() != "sha256:3333"; i++ {
+		tm.press("down")
+	}
+
+	tm.press("p")
+	tm.wantView("Pull image", ".amazonaws.com/api@sha256:3333")
+	tm.press("n")
+	tm.press("u")
+	tm.wantView("Push image", ".amazonaws.com/api@sha256:3333")
+}
+
+func TestConfirmBackspaceTrimsWholeRunes(t *testing.T) {
+	k := keys.NewListKeyMap()
+	d := confirmDialog{name: "café", visible: true, typed: true}
+	for _, r := range "caféé" {
+		d, _ = d.update(keyMsg(string(r)), k)
+	}
+	d, _ = d.update(keyMsg("backspace"), k)
+	if d.input != "café" {
+		t.Fatalf("input = %q after backspace, want the last é removed", d.input)
+	}
+	if _, result := d.update(keyMsg("enter"), k); result != confirmAccepted {
+		t.Fatalf("result = %v, want the typed name accepted", result)
+	}
+
+	d = confirmDialog{visible: true, typed: true}
+	if d, _ = d.update(keyMsg("backspace"), k); d.input != "" {
+		t.Fatalf("input = %q after backspace on nothing", d.input)
+	}
+}
+
+func TestConfirmedStopOutlivesTheScreen(t *testing.T) {
+	tm := newTestModel(t, nil)
+	item := tm.selectInstance("web-1")
+	id := aws.StringValue(item.instance.InstanceId)
+
+	// Leave for the menu before the result of the stop arrives.
+	tm.press("s")
+	next, cmd := tm.m.Update(keyMsg("y"))
+	tm.m = next.(Model)
+	tm.press("esc")
+	tm.run(cmd)
+	if tm.m.state != stateMenu {
+		t.Fatalf("state = %v, want the menu", tm.m.state)
+	}
+
+	if got := tm.instanceState("us-east-1", id); got != "stopped" {
+		t.Fatalf("instance is %s after the dialog was accepted", got)
+	}
+	tm.wantNotice("Instance " + id + " is stopped")
+}
+
+func TestConfirmedServiceStopOutlivesTheCluster(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ecs demo")
+	name := aws.StringValue(tm.m.ecsModel.serviceList.SelectedItem().(ecsServiceItem).service.ServiceName)
+
+	// Leave the cluster before the result of the stop arrives.
+	tm.press("s")
+	for _, r := range name {
+		tm.press(string(r))
+	}
+	next, cmd := tm.m.Update(keyMsg("enter"))
+	tm.m = next.(Model)
+	tm.press("esc")
+	tm.run(cmd)
+	tm.wantHeader("ECS Clusters")
+
+	if n := tm.desiredCount(name); n != 0 {
+		t.Fatalf("service %s still wants %d tasks", name, n)
+	}
+	tm.wantNotice("Service " + name + " has settled")
+}
+
+// wantNotice fails unless the last notice of the session is text.
+func (tm *testModel) wantNotice(text string) {
+	tm.t.Helper()
+	history := tm.m.notes.history
+	if len(history) == 0 || history[len(history)-1].text != text {
+		tm.t.Fatalf("notices = %+v, want %q last", history, text)
+	}
+}
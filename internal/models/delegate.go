@@ -10,7 +10,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-type ItemDelegate struct{}
+// ItemDelegate renders an item as its title over its description. Items
+// marked in marks, if set, get a mark before their title, and favorites in
+// stars a star.
+type ItemDelegate struct {
+	marks *selection
+	stars *stars
+}
 
 func (d ItemDelegate) Height() int                               { return 2 }
 func (d ItemDelegate) Spacing() int                              { return 1 }
@@ -21,7 +27,14 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		return
 	}
 
-	str := fmt.Sprintf("%s\n%s", styles.TitleStyle.Render(i.Title()), styles.DescriptionStyle.Render(i.Description()))
+	title := styles.TitleStyle.Render(i.Title())
+	if d.stars.has(listItem) {
+		title = styles.MarkedStyle.Render("★ ") + title
+	}
+	if d.marks.has(listItem) {
+		title = styles.MarkedStyle.Render("● ") + title
+	}
+	str := fmt.Sprintf("%s\n%s", title, styles.DescriptionStyle.Render(i.Description()))
 
 	fn := styles.UnselectedItemStyle.Render
 	if index == m.Index() {
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/commands"
 	"github.com/theoreticallyjosh/awstui/internal/keys"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
@@ -19,25 +22,33 @@ import (
 
 type ec2Model struct {
 	parent         *Model
-	ec2Svc         *ec2.EC2
+	pool           *clients.Pool
+	regions        []string
 	instanceList   list.Model
+	marks          *selection
+	table          *table
+	loader         listLoader
 	status         string
 	err            error
-	confirming     bool
-	action         string
-	actionID       *string
+	confirm        confirmDialog
+	target         ec2InstanceItem
+	guard          *guard
+	scope          *scope
+	notes          *notifier
 	showDetails    bool
 	detailInstance *ec2.Instance
+	jumpTo         []string
 	keys           *keys.ListKeyMap
-	Header         []string
+	nav            *navigation
 }
 
-func (m ec2Model) Init() tea.Cmd {
-	return tea.Batch(m.parent.spinner.Tick, commands.FetchInstancesCmd(m.ec2Svc))
+// load starts loading the instances, abandoning the load in progress.
+func (m ec2Model) load() (ec2Model, tea.Cmd) {
+	cmd := m.loader.load(commands.FetchInstancesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
 }
 
 func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
-	m.Header = []string{"EC2 Instances"}
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -46,34 +57,25 @@ func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
 		if m.instanceList.FilterState() == list.Filtering {
 			break
 		}
-		if m.confirming {
-			switch msg.String() {
-			case "y", "Y":
-				m.confirming = false
-				m.status = fmt.Sprintf("%sing instance %s...", m.action, *m.actionID)
+		if m.confirm.visible {
+			var result confirmResult
+			m.confirm, result = m.confirm.update(msg, m.keys)
+			switch result {
+			case confirmAccepted:
+				m.status = m.confirm.running
 				m.err = nil
-				if m.action == "stop" {
-					return m, tea.Batch(m.parent.spinner.Tick, commands.StopInstanceCmd(m.ec2Svc, m.actionID))
-				} else if m.action == "start" {
-					return m, tea.Batch(m.parent.spinner.Tick, commands.StartInstanceCmd(m.ec2Svc, m.actionID))
-				}
-			case "n", "N":
-				m.confirming = false
+				return m, tea.Batch(m.parent.spinner.Tick, m.confirm.accept)
+			case confirmRejected:
 				m.status = "Action cancelled."
-				m.action = ""
-				m.actionID = nil
 			}
 			return m, nil
 		}
 
 		if m.showDetails {
-			switch msg.String() {
-			case "esc", "backspace":
-				m.showDetails = false
-				m.detailInstance = nil
-				m.status = "Ready"
-				m.err = nil
-			}
+			return m, nil
+		}
+
+		if m.marks.update(msg, m.keys, &m.instanceList) || m.table.update(msg, m.keys, &m.instanceList) {
 			return m, nil
 		}
 
@@ -81,31 +83,57 @@ func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Refresh):
 			m.status = styles.StatusStyle.Render("Refreshing instances...")
 			m.err = nil
-			return m, tea.Batch(m.parent.spinner.Tick, commands.FetchInstancesCmd(m.ec2Svc))
+			return m.load()
 		case key.Matches(msg, m.keys.Stop):
-			if m.instanceList.SelectedItem() != nil {
+			if marked := m.marks.marked(m.instanceList); len(marked) > 0 {
+				if err := m.guard.check("stop instances"); err != nil {
+					m.status = blocked(err)
+				} else {
+					m = m.confirmBulk(marked, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopped)
+				}
+			} else if m.instanceList.SelectedItem() != nil {
 				selectedItem := m.instanceList.SelectedItem().(ec2InstanceItem)
 				selectedInstance := selectedItem.instance
-				if *selectedInstance.State.Name == ec2.InstanceStateNameRunning {
-					m.confirming = true
-					m.action = "stop"
-					m.actionID = selectedInstance.InstanceId
-					m.status = fmt.Sprintf("Confirm stopping instance %s (%s)? (y/N)",
-						utils.GetInstanceName(selectedInstance), *selectedInstance.InstanceId)
+				if err := m.guard.check("stop instances"); err != nil {
+					m.status = blocked(err)
+				} else if *selectedInstance.State.Name == ec2.InstanceStateNameRunning {
+					m.target = selectedItem
+					m.confirm = m.guard.open(confirmDialog{
+						title:    "Stop instance",
+						resource: fmt.Sprintf("%s (%s)", utils.GetInstanceName(selectedInstance), *selectedInstance.InstanceId),
+						name:     getInstanceName(selectedInstance),
+						region:   selectedItem.region,
+						changes:  []change{{"State", ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopped}},
+						accept:   m.act(selectedItem, ec2.InstanceStateNameStopped),
+						running:  fmt.Sprintf("Stopping instance %s...", *selectedInstance.InstanceId),
+					})
 				} else {
 					m.status = fmt.Sprintf("Instance %s is not running. Cannot stop.", utils.GetInstanceName(selectedInstance))
 				}
 			}
 		case key.Matches(msg, m.keys.Start):
-			if m.instanceList.SelectedItem() != nil {
+			if marked := m.marks.marked(m.instanceList); len(marked) > 0 {
+				if err := m.guard.check("start instances"); err != nil {
+					m.status = blocked(err)
+				} else {
+					m = m.confirmBulk(marked, ec2.InstanceStateNameStopped, ec2.InstanceStateNameRunning)
+				}
+			} else if m.instanceList.SelectedItem() != nil {
 				selectedItem := m.instanceList.SelectedItem().(ec2InstanceItem)
 				selectedInstance := selectedItem.instance
-				if *selectedInstance.State.Name == ec2.InstanceStateNameStopped {
-					m.confirming = true
-					m.action = "start"
-					m.actionID = selectedInstance.InstanceId
-					m.status = fmt.Sprintf("Confirm starting instance %s (%s)? (y/N)",
-						utils.GetInstanceName(selectedInstance), *selectedInstance.InstanceId)
+				if err := m.guard.check("start instances"); err != nil {
+					m.status = blocked(err)
+				} else if *selectedInstance.State.Name == ec2.InstanceStateNameStopped {
+					m.target = selectedItem
+					m.confirm = m.guard.open(confirmDialog{
+						title:    "Start instance",
+						resource: fmt.Sprintf("%s (%s)", utils.GetInstanceName(selectedInstance), *selectedInstance.InstanceId),
+						name:     getInstanceName(selectedInstance),
+						region:   selectedItem.region,
+						changes:  []change{{"State", ec2.InstanceStateNameStopped, ec2.InstanceStateNameRunning}},
+						accept:   m.act(selectedItem, ec2.InstanceStateNameRunning),
+						running:  fmt.Sprintf("Starting instance %s...", *selectedInstance.InstanceId),
+					})
 				} else {
 					m.status = fmt.Sprintf("Instance %s is not stopped. Cannot start.", utils.GetInstanceName(selectedInstance))
 				}
@@ -116,7 +144,7 @@ func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
 				selectedInstance := selectedItem.instance
 				m.status = "Fetching instance details..."
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchInstanceDetailsCmd(m.ec2Svc, selectedInstance.InstanceId))
+				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchInstanceDetailsCmd(m.scope.context(), m.pool.Region(selectedItem.region).EC2, selectedInstance.InstanceId))
 			}
 		case key.Matches(msg, m.keys.Ssh):
 			if m.instanceList.SelectedItem() != nil {
@@ -133,24 +161,73 @@ func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
 				}
 			}
 		}
+	case pageMsg:
+		if !m.loader.current(msg) {
+			return m, nil
+		}
+		return m.Update(msg.msg)
 	case messages.InstancesFetchedMsg:
-		listItems := make([]list.Item, len(msg))
-		for i, instance := range msg {
-			listItems[i] = ec2InstanceItem{instance: instance}
+		listItems := make([]list.Item, len(msg.Instances))
+		for i, instance := range msg.Instances {
+			listItems[i] = ec2InstanceItem{instance: instance, region: msg.Region}
 		}
-		m.instanceList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.loader.add(&m.instanceList, listItems, msg.Page)
+		m.table.sort(&m.instanceList)
+		m.status = m.loader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.loader.loading() {
+			if err := jumpSelect(&m.instanceList, "instance", m.jumpTo[0]); err != nil {
+				m.err = err
+				m.status = fmt.Sprintf("Error: %v", err)
+				m.notes.failure(err)
+			}
+			m.jumpTo = nil
+		}
+		return m, cmd
 	case messages.InstanceActionMsg:
-		m.status = fmt.Sprintf("Instance %s %s. Refreshing...", *m.actionID, msg)
+		id := aws.StringValue(m.target.instance.InstanceId)
+		m.status = fmt.Sprintf("Waiting for instance %s to be %s...", id, msg)
 		m.err = nil
-		m.action = ""
-		m.actionID = nil
-		return m, tea.Batch(m.parent.spinner.Tick, commands.FetchInstancesCmd(m.ec2Svc))
+		return m, tea.Batch(m.parent.spinner.Tick, m.wait(m.target.region, id, string(msg)))
+	case messages.BulkActionMsg:
+		summary, err := reportBulk(m.notes, msg, "instance", func(r messages.BulkResult) string {
+			return m.instanceName(r.Region, r.ID)
+		})
+		var waits []tea.Cmd
+		for _, r := range msg.Results {
+			if r.Err == nil {
+				m.marks.unmark(r.Region + "/" + r.ID)
+				waits = append(waits, m.wait(r.Region, r.ID, msg.Action))
+			}
+		}
+		switch {
+		case err != nil:
+			m.status = "Error: " + summary
+		case len(waits) > 0:
+			m.status = fmt.Sprintf("Waiting for %s to be %s...", count(len(waits), "instance"), msg.Action)
+		}
+		return m, tea.Batch(m.parent.spinner.Tick, tea.Batch(waits...))
+	case messages.InstanceWaitMsg:
+		if msg.Instance != nil {
+			m.updateInstance(msg.Region, msg.Instance)
+			m.table.sort(&m.instanceList)
+		}
+		switch {
+		case msg.Err != nil:
+			m.err = fmt.Errorf("waiting for instance %s: %w", msg.InstanceID, msg.Err)
+			m.status = fmt.Sprintf("Error: %v", m.err)
+			m.notes.failure(m.err)
+		case msg.Done():
+			m.status = "Ready"
+			m.notes.success("Instance %s is %s", msg.InstanceID, aws.StringValue(msg.Instance.State.Name))
+		default:
+			m.status = fmt.Sprintf("Instance %s is %s...", msg.InstanceID, aws.StringValue(msg.Instance.State.Name))
+		}
+		return m, nil
 	case messages.InstanceDetailsMsg:
 		m.detailInstance = msg
 		m.showDetails = true
+		m.nav.push(stateEC2, screenDetails, getInstanceName(msg))
 		m.status = "Ready"
 		m.err = nil
 		return m, nil
@@ -158,17 +235,16 @@ func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
 		if msg.Err != nil {
 			m.err = fmt.Errorf("SSH command failed: %s", msg.Err)
 			m.status = "SSH Failed"
+			m.notes.failure(m.err)
 		} else {
 			m.status = "SSH session ended."
 			m.err = nil
 		}
-		return m, tea.Batch(m.parent.spinner.Tick, commands.FetchInstancesCmd(m.ec2Svc))
+		return m.load()
 	case messages.ErrMsg:
 		m.err = msg
 		m.status = "Error"
-		m.confirming = false
-		m.action = ""
-		m.actionID = nil
+		m.confirm.visible = false
 		m.showDetails = false
 		m.detailInstance = nil
 		return m, nil
@@ -177,6 +253,145 @@ func (m ec2Model) Update(msg tea.Msg) (ec2Model, tea.Cmd) {
 	return m, cmd
 }
 
+// show returns to a screen opened before.
+func (m ec2Model) show(screen int) ec2Model {
+	if screen == screenList {
+		m.showDetails = false
+		m.detailInstance = nil
+	}
+	m.status = "Ready"
+	m.err = nil
+	return m
+}
+
+// jump shows the instance list and, once it has loaded, selects the instance
+// named by path.
+func (m ec2Model) jump(path []string) (ec2Model, tea.Cmd) {
+	m.showDetails = false
+	m.confirm.visible = false
+	m.jumpTo = path
+	if m.loader.loading() {
+		return m, nil
+	}
+	m.status = "Loading instances..."
+	return m.load()
+}
+
+// resume reloads the current list if leaving another screen cut its load
+// short.
+func (m ec2Model) resume() (ec2Model, tea.Cmd) {
+	if m.loader.interrupted {
+		return m.autoRefresh()
+	}
+	return m, nil
+}
+
+// autoRefresh reloads the instances in the background, unless the user is
+// busy with them.
+func (m ec2Model) autoRefresh() (ec2Model, tea.Cmd) {
+	if m.confirm.visible || m.showDetails || m.loader.loading() {
+		return m, nil
+	}
+	return m, m.loader.reload(commands.FetchInstancesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+}
+
+// wait starts a background operation waiting for an instance to reach state.
+func (m ec2Model) wait(region, id, state string) tea.Cmd {
+	svc := m.pool.Region(region).EC2
+	return startOp(stateEC2, fmt.Sprintf("Wait for instance %s to be %s", id, state), true, func(ctx context.Context) tea.Cmd {
+		return commands.WaitForInstanceCmd(ctx, svc, region, aws.String(id), state)
+	})
+}
+
+// act returns the command moving the instance of item to state to. It runs
+// as an operation, so its result and the waiter following it are not lost
+// when the user leaves the screen.
+func (m ec2Model) act(item ec2InstanceItem, to string) tea.Cmd {
+	title, action, run := "Stop", "ec2:StopInstances", commands.StopInstanceCmd
+	if to == ec2.InstanceStateNameRunning {
+		title, action, run = "Start", "ec2:StartInstances", commands.StartInstanceCmd
+	}
+	id := aws.StringValue(item.instance.InstanceId)
+	svc, guard := m.pool.Region(item.region).EC2, m.guard
+	return startOp(stateEC2, fmt.Sprintf("%s instance %s", title, id), true, func(ctx context.Context) tea.Cmd {
+		return guard.audited(action, item.region, guard.arn("ec2", item.region, "instance/"+id), nil,
+			run(ctx, svc, aws.String(id)))
+	})
+}
+
+// confirmBulk asks to move the marked instances that are in state from to
+// state to, with one call per region. Marked instances in another state are
+// skipped.
+func (m ec2Model) confirmBulk(marked []list.Item, from, to string) ec2Model {
+	title, running, action, run := "Stop", "Stopping", "ec2:StopInstances", commands.StopInstancesCmd
+	if to == ec2.InstanceStateNameRunning {
+		title, running, action, run = "Start", "Starting", "ec2:StartInstances", commands.StartInstancesCmd
+	}
+	ids := map[string][]string{}
+	var regions []string
+	var rows []change
+	for _, listItem := range marked {
+		item := listItem.(ec2InstanceItem)
+		if aws.StringValue(item.instance.State.Name) != from {
+			continue
+		}
+		id := aws.StringValue(item.instance.InstanceId)
+		if _, ok := ids[item.region]; !ok {
+			regions = append(regions, item.region)
+		}
+		ids[item.region] = append(ids[item.region], id)
+		rows = append(rows, change{fmt.Sprintf("%s (%s)", getInstanceName(item.instance), id), from, to})
+	}
+	if len(rows) == 0 {
+		m.status = fmt.Sprintf("None of the %s is %s.", count(len(marked), "marked instance"), from)
+		return m
+	}
+	n := count(len(rows), "instance")
+	skipped := len(marked) - len(rows)
+	rows = bulkChanges(rows)
+	if skipped > 0 {
+		rows = append(rows, change{field: fmt.Sprintf("Skipping %s not %s", count(skipped, "instance"), from)})
+	}
+	cs, guard := m.pool.Regions(regions), m.guard
+	m.confirm = m.guard.open(confirmDialog{
+		title:    title + " " + n,
+		resource: n,
+		name:     n,
+		region:   strings.Join(regions, ", "),
+		changes:  rows,
+		accept: startOp(stateEC2, title+" "+n, true, func(ctx context.Context) tea.Cmd {
+			return guard.auditedBulk(action, nil, func(r messages.BulkResult) string {
+				return guard.arn("ec2", r.Region, "instance/"+r.ID)
+			}, run(ctx, cs, ids))
+		}),
+		running: fmt.Sprintf("%s %s...", running, n),
+	})
+	return m
+}
+
+// instanceName names the instance id of region for the user, by its Name
+// tag if it is still listed.
+func (m ec2Model) instanceName(region, id string) string {
+	for _, listItem := range m.instanceList.Items() {
+		item := listItem.(ec2InstanceItem)
+		if item.region == region && aws.StringValue(item.instance.InstanceId) == id {
+			return fmt.Sprintf("%s (%s)", getInstanceName(item.instance), id)
+		}
+	}
+	return id
+}
+
+// updateInstance replaces the row of an instance with fresh data.
+func (m *ec2Model) updateInstance(region string, instance *ec2.Instance) {
+	for i, listItem := range m.instanceList.Items() {
+		item := listItem.(ec2InstanceItem)
+		if item.region == region && aws.StringValue(item.instance.InstanceId) == aws.StringValue(instance.InstanceId) {
+			m.instanceList.SetItem(i, ec2InstanceItem{instance: instance, region: region})
+			return
+		}
+	}
+}
+
 func (m ec2Model) View() string {
 	if m.showDetails {
 		if m.detailInstance != nil {
@@ -191,7 +406,7 @@ func (m ec2Model) View() string {
 					fmt.Sprintf("Availability Zone: %s\n", aws.StringValue(m.detailInstance.Placement.AvailabilityZone))+
 					fmt.Sprintf("VPC ID:        %s\n", aws.StringValue(m.detailInstance.VpcId))+
 					fmt.Sprintf("Subnet ID:     %s\n", aws.StringValue(m.detailInstance.SubnetId))+
-					"\nPress 'esc' or 'backspace' to go back.",
+					fmt.Sprintf("\nPress '%s' to go back.", m.keys.Back.Help().Key),
 			)
 		}
 		return styles.StatusStyle.Render("No details available.\n")
@@ -199,7 +414,7 @@ func (m ec2Model) View() string {
 
 	var s string
 	if len(m.instanceList.Items()) == 0 && m.status == "Ready" {
-		s = styles.StatusStyle.Render("No EC2 instances found in this region.\n")
+		s = styles.StatusStyle.Render("No EC2 instances found in the selected regions.\n")
 	} else {
 		s = m.instanceList.View()
 	}
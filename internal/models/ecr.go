@@ -1,12 +1,15 @@
 package models
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/commands"
 	"github.com/theoreticallyjosh/awstui/internal/keys"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
 	"github.com/theoreticallyjosh/awstui/internal/styles"
+	"github.com/theoreticallyjosh/awstui/internal/utils"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecr"
@@ -24,58 +27,58 @@ const (
 
 type ecrModel struct {
 	parent             *Model
-	ecrSvc             *ecr.ECR
+	pool               *clients.Pool
+	regions            []string
+	regionClients      *clients.Clients
 	repositoryList     list.Model
 	imageList          list.Model
+	imageMarks         *selection
+	repositoryLoader   listLoader
+	imageLoader        listLoader
 	status             string
 	err                error
 	keys               *keys.ListKeyMap
 	state              ecrState
-	confirming         bool
-	action             string
-	actionID           *string
+	confirm            confirmDialog
+	guard              *guard
+	scope              *scope
+	notes              *notifier
 	selectedRepository *ecr.Repository
-	header             []string
+	jumpTo             []string
+	nav                *navigation
 }
 
-func (m ecrModel) Init() tea.Cmd {
-	return tea.Batch(m.parent.spinner.Tick, commands.FetchECRRepositoriesCmd(m.ecrSvc))
+// loadRepositories starts loading the repositories, abandoning the load in
+// progress.
+func (m ecrModel) loadRepositories() (ecrModel, tea.Cmd) {
+	cmd := m.repositoryLoader.load(commands.FetchECRRepositoriesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
+}
+
+// loadImages starts loading the images of the repository on show, abandoning
+// the load in progress.
+func (m ecrModel) loadImages() (ecrModel, tea.Cmd) {
+	cmd := m.imageLoader.load(commands.FetchECRImagesCmd(m.scope.context(), m.regionClients.ECR, m.selectedRepository.RepositoryName))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
 }
 
 func (m ecrModel) Update(msg tea.Msg) (ecrModel, tea.Cmd) {
-	m.header = []string{"ECR Repositories"}
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.repositoryList.SetSize(msg.Width, msg.Height)
 		m.imageList.SetSize(msg.Width, msg.Height)
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back"))):
-			if m.state == ecrStateImageList {
-				m.state = ecrStateRepositoryList
-				m.status = "Ready"
+		if m.confirm.visible {
+			var result confirmResult
+			m.confirm, result = m.confirm.update(msg, m.keys)
+			switch result {
+			case confirmAccepted:
+				m.status = m.confirm.running
 				m.err = nil
-				m.imageList.SetItems([]list.Item{})
-				return m, nil
-			}
-		}
-		if m.confirming {
-			switch msg.String() {
-			case "y", "Y":
-				m.confirming = false
-				m.status = fmt.Sprintf("%sing image %s...", m.action, *m.actionID)
-				m.err = nil
-				if m.action == "pull" {
-					return m, tea.Batch(m.parent.spinner.Tick, commands.PullEcrImageCmd(m.ecrSvc, aws.StringValue(m.selectedRepository.RepositoryUri), *m.actionID))
-				} else if m.action == "push" {
-					return m, tea.Batch(m.parent.spinner.Tick, commands.PushEcrImageCmd(m.ecrSvc, aws.StringValue(m.selectedRepository.RepositoryUri), *m.actionID))
-				}
-			case "n", "N":
-				m.confirming = false
+				return m, tea.Batch(m.parent.spinner.Tick, m.confirm.accept)
+			case confirmRejected:
 				m.status = "Action cancelled."
-				m.action = ""
-				m.actionID = nil
 			}
 			return m, nil
 		}
@@ -88,66 +91,132 @@ func (m ecrModel) Update(msg tea.Msg) (ecrModel, tea.Cmd) {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = "Refreshing ECR repositories..."
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECRRepositoriesCmd(m.ecrSvc))
-			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select repository"))):
+				return m.loadRepositories()
+			case key.Matches(msg, m.keys.Choose):
 				if m.repositoryList.SelectedItem() != nil {
-					selectedItem := m.repositoryList.SelectedItem().(ecrRepositoryItem)
-					m.selectedRepository = selectedItem.repository
-					m.state = ecrStateImageList
-					m.status = fmt.Sprintf("Loading images for repository %s...", aws.StringValue(selectedItem.repository.RepositoryName))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECRImagesCmd(m.ecrSvc, selectedItem.repository.RepositoryName))
+					return m.openRepository(m.repositoryList.SelectedItem().(ecrRepositoryItem))
 				}
 			}
 		case ecrStateImageList:
 			if m.imageList.FilterState() == list.Filtering {
 				break
 			}
+			if m.imageMarks.update(msg, m.keys, &m.imageList) {
+				return m, nil
+			}
 			switch {
 			case key.Matches(msg, m.keys.Pull):
 				if m.imageList.SelectedItem() != nil {
 					selectedItem := m.imageList.SelectedItem().(ecrImageItem)
-					m.confirming = true
-					m.action = "pull"
-					m.actionID = selectedItem.image.ImageTags[0]
-					m.status = fmt.Sprintf("Confirm pulling image %s? (y/N)", aws.StringValue(selectedItem.image.ImageTags[0]))
+					ref := selectedItem.ref()
+					uri := aws.StringValue(m.selectedRepository.RepositoryUri)
+					image := utils.ImageName(uri, ref)
+					svc := m.regionClients.ECR
+					m.confirm = m.guard.open(confirmDialog{
+						title:    "Pull image",
+						resource: image,
+						name:     ref,
+						region:   m.regionClients.Region,
+						changes:  []change{{"Local image", "", image}},
+						accept: startOp(stateECR, "Pull image "+image, true, func(ctx context.Context) tea.Cmd {
+							return commands.PullEcrImageCmd(ctx, svc, uri, ref)
+						}),
+						running: fmt.Sprintf("Pulling image %s in the background...", ref),
+						local:   true,
+					})
 				}
 			case key.Matches(msg, m.keys.Push):
-				if m.imageList.SelectedItem() != nil {
+				if err := m.guard.check("push images"); err != nil {
+					m.status = blocked(err)
+				} else if m.imageList.SelectedItem() != nil {
 					selectedItem := m.imageList.SelectedItem().(ecrImageItem)
-					m.confirming = true
-					m.action = "push"
-					m.actionID = selectedItem.image.ImageTags[0]
-					m.status = fmt.Sprintf("Confirm pushing image %s? (y/N)", aws.StringValue(selectedItem.image.ImageTags[0]))
+					ref := selectedItem.ref()
+					uri := aws.StringValue(m.selectedRepository.RepositoryUri)
+					image := utils.ImageName(uri, ref)
+					svc, region, arn := m.regionClients.ECR, m.regionClients.Region, aws.StringValue(m.selectedRepository.RepositoryArn)
+					guard := m.guard
+					m.confirm = m.guard.open(confirmDialog{
+						title:    "Push image",
+						resource: image,
+						name:     ref,
+						region:   m.regionClients.Region,
+						changes:  []change{{"Image " + ref, aws.StringValue(selectedItem.image.ImageDigest), "local image"}},
+						accept: startOp(stateECR, "Push image "+image, true, func(ctx context.Context) tea.Cmd {
+							return guard.audited("ecr:PushImage", region, arn, map[string]string{"image": ref}, commands.PushEcrImageCmd(ctx, svc, uri, ref))
+						}),
+						running: fmt.Sprintf("Pushing image %s in the background...", ref),
+					})
+				}
+			case key.Matches(msg, m.keys.Delete):
+				marked := m.imageMarks.marked(m.imageList)
+				if len(marked) == 0 && m.imageList.SelectedItem() != nil {
+					marked = []list.Item{m.imageList.SelectedItem()}
+				}
+				if err := m.guard.check("delete images"); err != nil {
+					m.status = blocked(err)
+				} else if len(marked) > 0 {
+					m = m.confirmDelete(marked)
 				}
 			}
 		}
 
+	case pageMsg:
+		if !m.repositoryLoader.current(msg) && !m.imageLoader.current(msg) {
+			return m, nil
+		}
+		return m.Update(msg.msg)
 	case messages.EcrRepositoriesFetchedMsg:
-		listItems := make([]list.Item, len(msg))
-		for i, repository := range msg {
-			listItems[i] = ecrRepositoryItem{repository: repository}
+		listItems := make([]list.Item, len(msg.Repositories))
+		for i, repository := range msg.Repositories {
+			listItems[i] = ecrRepositoryItem{repository: repository, region: msg.Region}
 		}
-		m.repositoryList.SetItems(listItems)
-		m.status = "Ready"
+		cmd := m.repositoryLoader.add(&m.repositoryList, listItems, msg.Page)
+		m.status = m.repositoryLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.repositoryLoader.loading() {
+			var jumpCmd tea.Cmd
+			m, jumpCmd = m.jumpToRepository()
+			return m, tea.Batch(cmd, jumpCmd)
+		}
+		return m, cmd
 
 	case messages.EcrImagesFetchedMsg:
-		m.header = append(m.header, aws.StringValue(m.selectedRepository.RepositoryName), "Images")
-		listItems := make([]list.Item, len(msg))
-		for i, image := range msg {
+		listItems := make([]list.Item, len(msg.Images))
+		for i, image := range msg.Images {
 			listItems[i] = ecrImageItem{image: image}
 		}
-		m.imageList.SetItems(listItems)
-		m.status = "Ready"
+		cmd := m.imageLoader.add(&m.imageList, listItems, msg.Page)
+		m.status = m.imageLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.imageLoader.loading() {
+			if err := jumpSelect(&m.imageList, "image", m.jumpTo[0]); err != nil {
+				m.err = err
+				m.status = fmt.Sprintf("Error: %v", err)
+				m.notes.failure(err)
+			}
+			m.jumpTo = nil
+		}
+		return m, cmd
+	case messages.BulkActionMsg:
+		summary, err := reportBulk(m.notes, msg, "image", m.imageName)
+		for _, r := range msg.Results {
+			if r.Err == nil {
+				m.imageMarks.unmark(r.ID)
+			}
+		}
+		m.status = summary + ". Refreshing..."
+		if err != nil {
+			m.status = "Error: " + summary
+		}
+		if m.state != ecrStateImageList {
+			return m, nil
+		}
+		return m.loadImages()
 	case messages.EcrImageActionMsg:
 		m.status = fmt.Sprintf("Image %s. Refreshing...", msg)
+		m.notes.success("Image %s %s", m.confirm.resource, msg)
 		m.err = nil
-		m.action = ""
-		m.actionID = nil
-		return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECRImagesCmd(m.ecrSvc, m.selectedRepository.RepositoryName))
+		return m.loadImages()
 
 	case messages.ErrMsg:
 		m.err = msg
@@ -157,18 +226,133 @@ func (m ecrModel) Update(msg tea.Msg) (ecrModel, tea.Cmd) {
 	if m.state == ecrStateRepositoryList {
 		m.repositoryList, cmd = m.repositoryList.Update(msg)
 	} else if m.state == ecrStateImageList {
-		m.header = append(m.header, aws.StringValue(m.selectedRepository.RepositoryName), "Images")
 		m.imageList, cmd = m.imageList.Update(msg)
 	}
 	return m, cmd
 }
 
+// openRepository shows the images of a repository.
+func (m ecrModel) openRepository(selectedItem ecrRepositoryItem) (ecrModel, tea.Cmd) {
+	m.selectedRepository = selectedItem.repository
+	m.imageMarks.clear()
+	m.regionClients = m.pool.Region(selectedItem.region)
+	m.state = ecrStateImageList
+	m.nav.push(stateECR, int(ecrStateImageList), aws.StringValue(selectedItem.repository.RepositoryName), "Images")
+	m.status = fmt.Sprintf("Loading images for repository %s...", aws.StringValue(selectedItem.repository.RepositoryName))
+	return m.loadImages()
+}
+
+// confirmDelete asks to delete the given images of the open repository.
+// Deleted images cannot be recovered, so the repository name has to be typed
+// whatever the policy.
+func (m ecrModel) confirmDelete(images []list.Item) ecrModel {
+	digests := make([]string, len(images))
+	rows := make([]change, len(images))
+	for i, listItem := range images {
+		item := listItem.(ecrImageItem)
+		digests[i] = aws.StringValue(item.image.ImageDigest)
+		rows[i] = change{field: item.Title(), to: "deleted"}
+	}
+	n := count(len(images), "image")
+	name, arn := aws.StringValue(m.selectedRepository.RepositoryName), aws.StringValue(m.selectedRepository.RepositoryArn)
+	rc, guard := m.regionClients, m.guard
+	m.confirm = m.guard.open(confirmDialog{
+		title:    "Delete " + n,
+		resource: fmt.Sprintf("%s of repository %s", n, name),
+		name:     name,
+		region:   rc.Region,
+		changes:  bulkChanges(rows),
+		accept: startOp(stateECR, fmt.Sprintf("Delete %s from %s", n, name), true, func(ctx context.Context) tea.Cmd {
+			return guard.auditedBulk("ecr:BatchDeleteImage", nil, func(r messages.BulkResult) string {
+				return arn + "@" + r.ID
+			}, commands.DeleteEcrImagesCmd(ctx, rc, name, digests))
+		}),
+		running:  fmt.Sprintf("Deleting %s...", n),
+		highRisk: true,
+	})
+	return m
+}
+
+// imageName names the image with the digest of r by its tags, if it is still
+// listed.
+func (m ecrModel) imageName(r messages.BulkResult) string {
+	for _, listItem := range m.imageList.Items() {
+		if item := listItem.(ecrImageItem); aws.StringValue(item.image.ImageDigest) == r.ID {
+			return item.Title()
+		}
+	}
+	return r.ID
+}
+
+// show returns to a screen opened before, dropping what the screens above it
+// loaded.
+func (m ecrModel) show(state ecrState) ecrModel {
+	if state == ecrStateRepositoryList {
+		m.imageList.SetItems([]list.Item{})
+		m.imageMarks.clear()
+	}
+	m.state = state
+	m.status = "Ready"
+	m.err = nil
+	return m
+}
+
+// jump shows the repository list and, once it has loaded, opens the
+// repository and selects the image named by path.
+func (m ecrModel) jump(path []string) (ecrModel, tea.Cmd) {
+	m.state = ecrStateRepositoryList
+	m.confirm.visible = false
+	m.jumpTo = path
+	if m.repositoryLoader.loading() {
+		return m, nil
+	}
+	m.status = "Loading repositories..."
+	return m.loadRepositories()
+}
+
+// jumpToRepository opens the repository a jump is headed for.
+func (m ecrModel) jumpToRepository() (ecrModel, tea.Cmd) {
+	if err := jumpSelect(&m.repositoryList, "repository", m.jumpTo[0]); err != nil {
+		m.jumpTo = nil
+		m.err = err
+		m.status = fmt.Sprintf("Error: %v", err)
+		m.notes.failure(err)
+		return m, nil
+	}
+	m.jumpTo = m.jumpTo[1:]
+	return m.openRepository(m.repositoryList.SelectedItem().(ecrRepositoryItem))
+}
+
+// resume reloads the current list if leaving another screen cut its load
+// short.
+func (m ecrModel) resume() (ecrModel, tea.Cmd) {
+	if m.state == ecrStateRepositoryList && m.repositoryLoader.interrupted || m.state == ecrStateImageList && m.imageLoader.interrupted {
+		return m.autoRefresh()
+	}
+	return m, nil
+}
+
+// autoRefresh reloads the current list in the background, unless the user
+// is busy with it.
+func (m ecrModel) autoRefresh() (ecrModel, tea.Cmd) {
+	if m.confirm.visible {
+		return m, nil
+	}
+	switch {
+	case m.state == ecrStateRepositoryList && !m.repositoryLoader.loading():
+		return m, m.repositoryLoader.reload(commands.FetchECRRepositoriesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	case m.state == ecrStateImageList && !m.imageLoader.loading():
+		return m, m.imageLoader.reload(commands.FetchECRImagesCmd(m.scope.context(), m.regionClients.ECR, m.selectedRepository.RepositoryName))
+	}
+	return m, nil
+}
+
 func (m ecrModel) View() string {
 	var s string
 	switch m.state {
 	case ecrStateRepositoryList:
 		if len(m.repositoryList.Items()) == 0 && m.status == "Ready" {
-			s = styles.StatusStyle.Render("No ECR repositories found in this region.\n")
+			s = styles.StatusStyle.Render("No ECR repositories found in the selected regions.\n")
 		} else {
 			s = m.repositoryList.View()
 		}
@@ -1,18 +1,20 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/theoreticallyjosh/awstui/internal/styles"
 
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/commands"
 	"github.com/theoreticallyjosh/awstui/internal/keys"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -26,37 +28,62 @@ const (
 	ecsStateClusterList ecsState = iota
 	ecsStateServiceList
 	ecsStateServiceDetails
-	ecsStateServiceConfirmAction
 	ecsStateServiceLogs
+	ecsStateTaskDetails
+	ecsStateTaskDefinition
 )
 
 type ecsModel struct {
-	parent                  *Model
-	ecsSvc                  *ecs.ECS
-	cloudwatchlogsSvc       *cloudwatchlogs.CloudWatchLogs
-	clusterList             list.Model
-	serviceList             list.Model
-	status                  string
-	err                     error
-	confirming              bool
-	action                  string
-	detailCluster           *ecs.Cluster
-	detailService           *ecs.Service
-	ecsServiceActionService *ecs.Service
-	serviceLogs             string
-	keys                    *keys.ListKeyMap
-	paginator               paginator.Model
-	state                   ecsState
-	header                  []string
+	parent        *Model
+	pool          *clients.Pool
+	regions       []string
+	regionClients *clients.Clients
+	clusterList   list.Model
+	serviceList   list.Model
+	serviceMarks  *selection
+	serviceTable  *table
+	clusterLoader listLoader
+	serviceLoader listLoader
+	status        string
+	err           error
+	confirm       confirmDialog
+	guard         *guard
+	scope         *scope
+	notes         *notifier
+	detailCluster *ecs.Cluster
+	detailService *ecs.Service
+	target        *ecs.Service
+	targetClients *clients.Clients
+	serviceLogs   string
+	jumpTo        []string
+	keys          *keys.ListKeyMap
+	paginator     paginator.Model
+	state         ecsState
+	nav           *navigation
+
+	// detailTask is a task opened from a link, of the cluster taskCluster.
+	detailTask  *ecs.Task
+	taskCluster string
+	// taskDefinition is shown in place of the details it was opened from.
+	taskDefinition *ecs.TaskDefinition
+	linkCursor     linkCursor
+}
+
+// loadClusters starts loading the clusters, abandoning the load in progress.
+func (m ecsModel) loadClusters() (ecsModel, tea.Cmd) {
+	cmd := m.clusterLoader.load(commands.FetchECSClustersCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
 }
 
-func (m ecsModel) Init() tea.Cmd {
-	return tea.Batch(m.parent.spinner.Tick, commands.FetchECSClustersCmd(m.ecsSvc))
+// loadServices starts loading the services of the cluster on show,
+// abandoning the load in progress.
+func (m ecsModel) loadServices() (ecsModel, tea.Cmd) {
+	cmd := m.serviceLoader.load(commands.FetchECSServicesCmd(m.scope.context(), m.regionClients.ECS, aws.StringValue(m.detailCluster.ClusterArn)))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
 }
 
 func (m ecsModel) Update(msg tea.Msg) (ecsModel, tea.Cmd) {
 	var cmd tea.Cmd
-	m.header = []string{"ECS Clusters"}
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -64,56 +91,19 @@ func (m ecsModel) Update(msg tea.Msg) (ecsModel, tea.Cmd) {
 		m.clusterList.SetSize(msg.Width, msg.Height)
 		m.serviceList.SetSize(msg.Width, msg.Height)
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back"))):
-			if m.state == ecsStateServiceList {
-				m.state = ecsStateClusterList
-				m.status = "Ready"
-				m.err = nil
-				m.serviceList.SetItems([]list.Item{})
-				return m, nil
-			} else if m.state == ecsStateServiceDetails {
-				m.state = ecsStateServiceList
-				m.status = "Ready"
+		if m.confirm.visible {
+			var result confirmResult
+			m.confirm, result = m.confirm.update(msg, m.keys)
+			switch result {
+			case confirmAccepted:
+				m.status = m.confirm.running
 				m.err = nil
-				m.detailService = nil
-				return m, nil
-			} else if m.state == ecsStateServiceConfirmAction {
-				m.state = ecsStateServiceList
-				m.confirming = false
-				m.action = ""
-				m.ecsServiceActionService = nil
-				m.status = "Ready"
-				return m, nil
-			} else if m.state == ecsStateServiceLogs {
-				m.state = ecsStateServiceList
+				return m, tea.Batch(m.parent.spinner.Tick, m.confirm.accept)
+			case confirmRejected:
 				m.status = "Ready"
-				m.err = nil
-				m.serviceLogs = ""
-				return m, nil
-			}
-		}
-		if m.state == ecsStateServiceConfirmAction {
-			switch msg.String() {
-			case "y", "Y":
-				m.confirming = false
-				m.status = fmt.Sprintf("%sing service %s...", m.action, aws.StringValue(m.ecsServiceActionService.ServiceName))
-				m.err = nil
-				if m.action == "stop" {
-					return m, tea.Batch(m.parent.spinner.Tick, commands.StopECSServiceCmd(m.ecsSvc, aws.StringValue(m.detailCluster.ClusterArn), aws.StringValue(m.ecsServiceActionService.ServiceArn)))
-				} else if m.action == "force-deploy" {
-					return m, tea.Batch(m.parent.spinner.Tick, commands.ForceDeployECSServiceCmd(m.ecsSvc, aws.StringValue(m.detailCluster.ClusterArn), aws.StringValue(m.ecsServiceActionService.ServiceArn)))
-				}
-			case "n", "N":
-				m.confirming = false
-				m.status = "Ready"
-				m.action = ""
-				m.state = ecsStateServiceList
-				m.ecsServiceActionService = nil
 			}
 			return m, nil
 		}
-
 		switch m.state {
 		case ecsStateClusterList:
 			if m.clusterList.FilterState() == list.Filtering {
@@ -123,111 +113,205 @@ func (m ecsModel) Update(msg tea.Msg) (ecsModel, tea.Cmd) {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = "Refreshing ECS clusters..."
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSClustersCmd(m.ecsSvc))
+				return m.loadClusters()
 			case key.Matches(msg, m.keys.Choose):
 				if m.clusterList.SelectedItem() != nil {
-					selectedItem := m.clusterList.SelectedItem().(ecsClusterItem)
-					m.detailCluster = selectedItem.cluster
-					m.state = ecsStateServiceList
-					m.status = fmt.Sprintf("Loading services for cluster %s...", aws.StringValue(m.detailCluster.ClusterName))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSServicesCmd(m.ecsSvc, aws.StringValue(m.detailCluster.ClusterArn)))
+					return m.openCluster(m.clusterList.SelectedItem().(ecsClusterItem))
 				}
 			}
 		case ecsStateServiceList:
-			m.header = append(m.header, aws.StringValue(m.detailCluster.ClusterName), "Services")
 			if m.serviceList.FilterState() == list.Filtering {
 				break
 			}
+			if m.serviceMarks.update(msg, m.keys, &m.serviceList) || m.serviceTable.update(msg, m.keys, &m.serviceList) {
+				return m, nil
+			}
 			switch {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = fmt.Sprintf("Refreshing services for cluster %s...", aws.StringValue(m.detailCluster.ClusterName))
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSServicesCmd(m.ecsSvc, aws.StringValue(m.detailCluster.ClusterArn)))
+				return m.loadServices()
 			case key.Matches(msg, m.keys.Details):
 				if m.serviceList.SelectedItem() != nil {
 					selectedItem := m.serviceList.SelectedItem().(ecsServiceItem)
 					m.detailService = selectedItem.service
 					m.state = ecsStateServiceDetails
+					m.nav.push(stateECS, int(ecsStateServiceDetails), aws.StringValue(selectedItem.service.ServiceName))
 					m.status = "Ready"
 					m.err = nil
+					m.linkCursor = 0
 				}
 			case key.Matches(msg, m.keys.Stop):
 				if m.serviceList.SelectedItem() != nil {
 					selectedItem := m.serviceList.SelectedItem().(ecsServiceItem)
 					selectedService := selectedItem.service
-					if aws.Int64Value(selectedService.DesiredCount) > 0 {
-						m.confirming = true
-						m.action = "stop"
-						m.ecsServiceActionService = selectedService
-						m.state = ecsStateServiceConfirmAction
-						m.status = fmt.Sprintf("Confirm stopping service %s (Desired: %d)? (y/N)",
-							aws.StringValue(selectedService.ServiceName), aws.Int64Value(selectedService.DesiredCount))
+					if err := m.guard.check("stop services"); err != nil {
+						m.status = blocked(err)
+					} else if aws.Int64Value(selectedService.DesiredCount) > 0 {
+						// Scaling to zero takes the service down, so the
+						// name has to be typed whatever the policy.
+						m.target, m.targetClients = selectedService, m.regionClients
+						m.confirm = m.guard.open(confirmDialog{
+							title:    "Stop service",
+							resource: aws.StringValue(selectedService.ServiceName),
+							name:     aws.StringValue(selectedService.ServiceName),
+							region:   m.regionClients.Region,
+							changes:  []change{{"Desired count", fmt.Sprint(aws.Int64Value(selectedService.DesiredCount)), "0"}},
+							accept:   m.act(selectedService, false),
+							running:  fmt.Sprintf("Stopping service %s...", aws.StringValue(selectedService.ServiceName)),
+							highRisk: true,
+						})
 					} else {
 						m.status = fmt.Sprintf("Service %s is already stopped (Desired: 0).", aws.StringValue(selectedService.ServiceName))
 					}
 				}
 			case key.Matches(msg, m.keys.ForceDeploy):
-				if m.serviceList.SelectedItem() != nil {
+				if marked := m.serviceMarks.marked(m.serviceList); len(marked) > 0 {
+					if err := m.guard.check("force deployments"); err != nil {
+						m.status = blocked(err)
+						break
+					}
+					m = m.confirmBulk(marked)
+				} else if m.serviceList.SelectedItem() != nil {
 					selectedItem := m.serviceList.SelectedItem().(ecsServiceItem)
 					selectedService := selectedItem.service
-					m.confirming = true
-					m.action = "force-deploy"
-					m.ecsServiceActionService = selectedService
-					m.state = ecsStateServiceConfirmAction
-					m.status = fmt.Sprintf("Confirm force deployment of service %s? (y/N)",
-						aws.StringValue(selectedService.ServiceName))
+					if err := m.guard.check("force deployments"); err != nil {
+						m.status = blocked(err)
+						break
+					}
+					taskDefinition := path.Base(aws.StringValue(selectedService.TaskDefinition))
+					m.target, m.targetClients = selectedService, m.regionClients
+					m.confirm = m.guard.open(confirmDialog{
+						title:    "Force deployment",
+						resource: aws.StringValue(selectedService.ServiceName),
+						name:     aws.StringValue(selectedService.ServiceName),
+						region:   m.regionClients.Region,
+						changes:  []change{{"Tasks", "running " + taskDefinition, "replaced with new tasks of " + taskDefinition}},
+						accept:   m.act(selectedService, true),
+						running:  fmt.Sprintf("Force deploying service %s...", aws.StringValue(selectedService.ServiceName)),
+					})
 				}
 			case key.Matches(msg, m.keys.Logs):
 				if m.serviceList.SelectedItem() != nil {
 					selectedItem := m.serviceList.SelectedItem().(ecsServiceItem)
 					m.detailService = selectedItem.service
 					m.state = ecsStateServiceLogs
+					m.nav.push(stateECS, int(ecsStateServiceLogs), aws.StringValue(selectedItem.service.ServiceName), "Logs")
 					m.status = fmt.Sprintf("Fetching logs for service %s...", aws.StringValue(selectedItem.service.ServiceName))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSServiceLogsCmd(m.ecsSvc, m.cloudwatchlogsSvc, selectedItem.service))
+					rc := m.regionClients
+					cmd = startOp(stateECS, fmt.Sprintf("Download logs of service %s", aws.StringValue(selectedItem.service.ServiceName)), false, func(ctx context.Context) tea.Cmd {
+						return commands.FetchECSServiceLogsCmd(ctx, rc.ECS, rc.Logs, selectedItem.service)
+					})
+					return m, tea.Batch(m.parent.spinner.Tick, cmd)
 				}
 			}
-		case ecsStateServiceDetails:
-			// No key handling in these states for now
+		case ecsStateServiceDetails, ecsStateTaskDetails, ecsStateTaskDefinition:
+			links := m.links()
+			var followed bool
+			if m.linkCursor, followed = m.linkCursor.update(msg, m.keys, len(links)); followed {
+				return m.followLink(links[m.linkCursor])
+			}
+			return m, nil
 		case ecsStateServiceLogs:
-			m.header = append(m.header, aws.StringValue(m.detailCluster.ClusterName), m.serviceList.SelectedItem().FilterValue(), "Logs")
 			m.paginator, cmd = m.paginator.Update(msg)
 			return m, cmd
 		}
 
+	case pageMsg:
+		if !m.clusterLoader.current(msg) && !m.serviceLoader.current(msg) {
+			return m, nil
+		}
+		return m.Update(msg.msg)
 	case messages.EcsClustersFetchedMsg:
-		listItems := make([]list.Item, len(msg))
-		for i, cluster := range msg {
-			listItems[i] = ecsClusterItem{cluster: cluster}
+		listItems := make([]list.Item, len(msg.Clusters))
+		for i, cluster := range msg.Clusters {
+			listItems[i] = ecsClusterItem{cluster: cluster, region: msg.Region}
 		}
-		m.clusterList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.clusterLoader.add(&m.clusterList, listItems, msg.Page)
+		m.status = m.clusterLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.clusterLoader.loading() {
+			var jumpCmd tea.Cmd
+			m, jumpCmd = m.jumpToCluster()
+			return m, tea.Batch(cmd, jumpCmd)
+		}
+		return m, cmd
 	case messages.EcsServicesFetchedMsg:
-		m.header = append(m.header, m.clusterList.SelectedItem().FilterValue(), "Services")
-		listItems := make([]list.Item, len(msg))
-		for i, service := range msg {
+		listItems := make([]list.Item, len(msg.Services))
+		for i, service := range msg.Services {
 			listItems[i] = ecsServiceItem{service: service}
 		}
-		m.serviceList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.serviceLoader.add(&m.serviceList, listItems, msg.Page)
+		m.serviceTable.sort(&m.serviceList)
+		m.status = m.serviceLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.serviceLoader.loading() {
+			if err := jumpSelect(&m.serviceList, "service", m.jumpTo[0]); err != nil {
+				m.err = err
+				m.status = fmt.Sprintf("Error: %v", err)
+				m.notes.failure(err)
+			}
+			m.jumpTo = nil
+		}
+		return m, cmd
 	case messages.EcsServiceDetailsMsg:
 		m.detailService = msg
 		m.state = ecsStateServiceDetails
+		m.nav.push(stateECS, int(ecsStateServiceDetails), aws.StringValue(msg.ServiceName))
+		m.status = "Ready"
+		m.err = nil
+		return m, nil
+	case messages.EcsTaskDetailsMsg:
+		m.detailTask = msg
+		m.status = "Ready"
+		m.err = nil
+		return m, nil
+	case messages.EcsTaskDefinitionMsg:
+		m.taskDefinition = msg
 		m.status = "Ready"
 		m.err = nil
 		return m, nil
 	case messages.EcsServiceActionMsg:
-		m.status = fmt.Sprintf("Service %s %s. Refreshing...", aws.StringValue(m.ecsServiceActionService.ServiceName), msg)
+		m.status = fmt.Sprintf("Service %s %s. Waiting for it to settle...", aws.StringValue(m.target.ServiceName), msg)
 		m.err = nil
-		m.action = ""
-		m.ecsServiceActionService = nil
-		m.confirming = false
-		return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSServicesCmd(m.ecsSvc, aws.StringValue(m.detailCluster.ClusterArn)))
+		return m, tea.Batch(m.parent.spinner.Tick, m.wait(m.targetClients, aws.StringValue(m.target.ClusterArn), aws.StringValue(m.target.ServiceArn)))
+	case messages.BulkActionMsg:
+		summary, err := reportBulk(m.notes, msg, "service", func(r messages.BulkResult) string {
+			return path.Base(r.ID)
+		})
+		var waits []tea.Cmd
+		for _, r := range msg.Results {
+			// The cluster may have been left in the meantime.
+			if r.Err == nil && listed(m.serviceList, r.ID) {
+				m.serviceMarks.unmark(r.ID)
+				waits = append(waits, m.wait(m.regionClients, aws.StringValue(m.detailCluster.ClusterArn), r.ID))
+			}
+		}
+		switch {
+		case err != nil:
+			m.status = "Error: " + summary
+		case len(waits) > 0:
+			m.status = fmt.Sprintf("%s. Waiting for them to settle...", summary)
+		}
+		return m, tea.Batch(m.parent.spinner.Tick, tea.Batch(waits...))
+	case messages.EcsServiceWaitMsg:
+		if msg.Service != nil {
+			m.updateService(msg.Service)
+			m.serviceTable.sort(&m.serviceList)
+		}
+		switch {
+		case msg.Err != nil:
+			m.err = fmt.Errorf("waiting for service %s: %w", msg.ServiceArn, msg.Err)
+			m.status = fmt.Sprintf("Error: %v", m.err)
+			m.notes.failure(m.err)
+		case msg.Done():
+			m.status = "Ready"
+			m.notes.success("Service %s has settled", aws.StringValue(msg.Service.ServiceName))
+		default:
+			m.status = fmt.Sprintf("Service %s is settling (%d/%d tasks running)...", aws.StringValue(msg.Service.ServiceName), aws.Int64Value(msg.Service.RunningCount), aws.Int64Value(msg.Service.DesiredCount))
+		}
+		return m, nil
 	case messages.EcsServiceLogsFetchedMsg:
-		m.header = append(m.header, aws.StringValue(m.detailCluster.ClusterName), m.serviceList.SelectedItem().FilterValue(), "Logs")
 		m.serviceLogs = string(msg)
 		m.paginator.SetTotalPages(len(strings.Split(m.serviceLogs, "\n")))
 		m.status = "Ready"
@@ -236,10 +320,11 @@ func (m ecsModel) Update(msg tea.Msg) (ecsModel, tea.Cmd) {
 	case messages.ErrMsg:
 		m.err = msg
 		m.status = "Error"
-		m.confirming = false
-		m.action = ""
+		m.confirm.visible = false
 		m.detailService = nil
 		m.serviceLogs = ""
+		m.detailTask = nil
+		m.taskDefinition = nil
 		return m, nil
 	}
 
@@ -251,12 +336,209 @@ func (m ecsModel) Update(msg tea.Msg) (ecsModel, tea.Cmd) {
 	return m, cmd
 }
 
+// openCluster shows the services of a cluster.
+func (m ecsModel) openCluster(selectedItem ecsClusterItem) (ecsModel, tea.Cmd) {
+	m.detailCluster = selectedItem.cluster
+	m.serviceMarks.clear()
+	m.regionClients = m.pool.Region(selectedItem.region)
+	m.state = ecsStateServiceList
+	m.nav.push(stateECS, int(ecsStateServiceList), aws.StringValue(m.detailCluster.ClusterName), "Services")
+	m.status = fmt.Sprintf("Loading services for cluster %s...", aws.StringValue(m.detailCluster.ClusterName))
+	return m.loadServices()
+}
+
+// openTask shows a task of a cluster in region, such as one a Step Functions
+// execution started.
+func (m ecsModel) openTask(region, cluster, taskArn string) (ecsModel, tea.Cmd) {
+	m.regionClients = m.pool.Region(region)
+	m.taskCluster = cluster
+	m.detailTask = nil
+	m.linkCursor = 0
+	m.confirm.visible = false
+	m.state = ecsStateTaskDetails
+	m.nav.push(stateECS, int(ecsStateTaskDetails), viewTitles[stateECS], path.Base(cluster), "Tasks", path.Base(taskArn))
+	m.status = fmt.Sprintf("Loading task %s...", path.Base(taskArn))
+	return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSTaskCmd(m.scope.context(), m.regionClients.ECS, cluster, taskArn))
+}
+
+// openTaskDefinition shows a task definition in place of the details it is
+// linked from.
+func (m ecsModel) openTaskDefinition(arn string) (ecsModel, tea.Cmd) {
+	m.taskDefinition = nil
+	m.linkCursor = 0
+	m.state = ecsStateTaskDefinition
+	m.nav.push(stateECS, int(ecsStateTaskDefinition), path.Base(arn))
+	m.status = fmt.Sprintf("Loading task definition %s...", path.Base(arn))
+	return m, tea.Batch(m.parent.spinner.Tick, commands.FetchECSTaskDefinitionCmd(m.scope.context(), m.regionClients.ECS, arn))
+}
+
+// links lists the resources the details on show link to.
+func (m ecsModel) links() []link {
+	var links []link
+	switch {
+	case m.state == ecsStateServiceDetails && m.detailService != nil:
+		links = append(links, taskDefinitionLink(m.regionClients.Region, aws.StringValue(m.detailService.TaskDefinition)))
+	case m.state == ecsStateTaskDetails && m.detailTask != nil:
+		links = append(links, taskDefinitionLink(m.regionClients.Region, aws.StringValue(m.detailTask.TaskDefinitionArn)))
+	case m.state == ecsStateTaskDefinition && m.taskDefinition != nil:
+		for _, container := range m.taskDefinition.ContainerDefinitions {
+			if l, ok := imageLink(aws.StringValue(container.Image)); ok {
+				links = append(links, l)
+			}
+		}
+	}
+	return links
+}
+
+// followLink opens task definitions in place and leaves other links to the
+// root model, since they lead into other views.
+func (m ecsModel) followLink(l link) (ecsModel, tea.Cmd) {
+	if l.kind == linkTaskDefinition {
+		return m.openTaskDefinition(l.path[0])
+	}
+	return m, follow(l)
+}
+
+// show returns to a screen opened before, dropping what the screens above it
+// loaded.
+func (m ecsModel) show(state ecsState) ecsModel {
+	switch state {
+	case ecsStateClusterList:
+		m.serviceList.SetItems([]list.Item{})
+		m.serviceMarks.clear()
+		fallthrough
+	case ecsStateServiceList:
+		m.detailService = nil
+		m.serviceLogs = ""
+		m.detailTask = nil
+		fallthrough
+	case ecsStateServiceDetails, ecsStateTaskDetails:
+		m.taskDefinition = nil
+	}
+	m.state = state
+	m.status = "Ready"
+	m.err = nil
+	m.linkCursor = 0
+	return m
+}
+
+// jump shows the cluster list and, once it has loaded, opens the cluster and
+// selects the service named by path.
+func (m ecsModel) jump(path []string) (ecsModel, tea.Cmd) {
+	m.state = ecsStateClusterList
+	m.confirm.visible = false
+	m.jumpTo = path
+	if m.clusterLoader.loading() {
+		return m, nil
+	}
+	m.status = "Loading clusters..."
+	return m.loadClusters()
+}
+
+// jumpToCluster opens the cluster a jump is headed for.
+func (m ecsModel) jumpToCluster() (ecsModel, tea.Cmd) {
+	if err := jumpSelect(&m.clusterList, "cluster", m.jumpTo[0]); err != nil {
+		m.jumpTo = nil
+		m.err = err
+		m.status = fmt.Sprintf("Error: %v", err)
+		m.notes.failure(err)
+		return m, nil
+	}
+	m.jumpTo = m.jumpTo[1:]
+	return m.openCluster(m.clusterList.SelectedItem().(ecsClusterItem))
+}
+
+// resume reloads the current list if leaving another screen cut its load
+// short.
+func (m ecsModel) resume() (ecsModel, tea.Cmd) {
+	if m.state == ecsStateClusterList && m.clusterLoader.interrupted || m.state == ecsStateServiceList && m.serviceLoader.interrupted {
+		return m.autoRefresh()
+	}
+	return m, nil
+}
+
+// autoRefresh reloads the current list in the background, unless the user
+// is busy with it.
+func (m ecsModel) autoRefresh() (ecsModel, tea.Cmd) {
+	if m.confirm.visible {
+		return m, nil
+	}
+	switch {
+	case m.state == ecsStateClusterList && !m.clusterLoader.loading():
+		return m, m.clusterLoader.reload(commands.FetchECSClustersCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	case m.state == ecsStateServiceList && !m.serviceLoader.loading():
+		return m, m.serviceLoader.reload(commands.FetchECSServicesCmd(m.scope.context(), m.regionClients.ECS, aws.StringValue(m.detailCluster.ClusterArn)))
+	}
+	return m, nil
+}
+
+// wait starts a background operation waiting for a service of clusterArn,
+// reached through rc, to settle.
+func (m ecsModel) wait(rc *clients.Clients, clusterArn, serviceArn string) tea.Cmd {
+	return startOp(stateECS, fmt.Sprintf("Wait for service %s to settle", path.Base(serviceArn)), true, func(ctx context.Context) tea.Cmd {
+		return commands.WaitForECSServiceCmd(ctx, rc.ECS, clusterArn, serviceArn)
+	})
+}
+
+// act returns the command stopping service, or forcing a new deployment of
+// it, in the cluster open now. It runs as an operation, so its result and
+// the waiter following it are not lost when the user leaves the cluster.
+func (m ecsModel) act(service *ecs.Service, forceDeploy bool) tea.Cmd {
+	title, params, run := "Stop", map[string]string{"desiredCount": "0"}, commands.StopECSServiceCmd
+	if forceDeploy {
+		title, params, run = "Force deploy", map[string]string{"forceNewDeployment": "true"}, commands.ForceDeployECSServiceCmd
+	}
+	rc, guard := m.regionClients, m.guard
+	clusterArn, serviceArn := aws.StringValue(m.detailCluster.ClusterArn), aws.StringValue(service.ServiceArn)
+	return startOp(stateECS, fmt.Sprintf("%s service %s", title, aws.StringValue(service.ServiceName)), true, func(ctx context.Context) tea.Cmd {
+		return guard.audited("ecs:UpdateService", rc.Region, serviceArn, params, run(ctx, rc.ECS, clusterArn, serviceArn))
+	})
+}
+
+// confirmBulk asks to force a new deployment of the marked services.
+func (m ecsModel) confirmBulk(marked []list.Item) ecsModel {
+	arns := make([]string, len(marked))
+	rows := make([]change, len(marked))
+	for i, listItem := range marked {
+		service := listItem.(ecsServiceItem).service
+		arns[i] = aws.StringValue(service.ServiceArn)
+		taskDefinition := path.Base(aws.StringValue(service.TaskDefinition))
+		rows[i] = change{aws.StringValue(service.ServiceName), "running " + taskDefinition, "new tasks of " + taskDefinition}
+	}
+	n := count(len(marked), "service")
+	rc, clusterArn, guard := m.regionClients, aws.StringValue(m.detailCluster.ClusterArn), m.guard
+	m.confirm = m.guard.open(confirmDialog{
+		title:    "Force deployment of " + n,
+		resource: fmt.Sprintf("%s of cluster %s", n, aws.StringValue(m.detailCluster.ClusterName)),
+		name:     n,
+		region:   rc.Region,
+		changes:  bulkChanges(rows),
+		accept: startOp(stateECS, "Force deploy "+n, true, func(ctx context.Context) tea.Cmd {
+			return guard.auditedBulk("ecs:UpdateService", map[string]string{"forceNewDeployment": "true"}, func(r messages.BulkResult) string {
+				return r.ID
+			}, commands.ForceDeployECSServicesCmd(ctx, rc, clusterArn, arns))
+		}),
+		running: fmt.Sprintf("Force deploying %s...", n),
+	})
+	return m
+}
+
+// updateService replaces the row of a service with fresh data.
+func (m *ecsModel) updateService(service *ecs.Service) {
+	for i, listItem := range m.serviceList.Items() {
+		if aws.StringValue(listItem.(ecsServiceItem).service.ServiceArn) == aws.StringValue(service.ServiceArn) {
+			m.serviceList.SetItem(i, ecsServiceItem{service: service})
+			return
+		}
+	}
+}
+
 func (m ecsModel) View() string {
 	var s string
 	switch m.state {
 	case ecsStateClusterList:
 		if len(m.clusterList.Items()) == 0 && m.status == "Ready" {
-			s = "No ECS clusters found in this region.\n"
+			s = "No ECS clusters found in the selected regions.\n"
 		} else {
 			s = m.clusterList.View()
 		}
@@ -278,13 +560,59 @@ func (m ecsModel) View() string {
 					fmt.Sprintf("Launch Type:   %s\n", aws.StringValue(m.detailService.LaunchType))+
 					fmt.Sprintf("Task Definition: %s\n", aws.StringValue(m.detailService.TaskDefinition))+
 					fmt.Sprintf("Created At:    %s\n", aws.TimeValue(m.detailService.CreatedAt).Format(time.RFC822))+
-					"\nPress 'esc' or 'backspace' to go back.",
+					linksView(m.links(), m.linkCursor, m.keys)+
+					fmt.Sprintf("\nPress '%s' to go back.", m.keys.Back.Help().Key),
 			)
 		} else {
 			s = styles.StatusStyle.Render("No service details available.\n")
 		}
-	case ecsStateServiceConfirmAction:
-		// s = styles.ConfirmStyle.Render(fmt.Sprintf("\n%s", m.status))
+	case ecsStateTaskDetails:
+		if m.detailTask != nil {
+			var containers strings.Builder
+			for _, c := range m.detailTask.Containers {
+				fmt.Fprintf(&containers, "  %s: %s, %s", aws.StringValue(c.Name), aws.StringValue(c.LastStatus), aws.StringValue(c.Image))
+				if c.ExitCode != nil {
+					fmt.Fprintf(&containers, ", exit code %d", aws.Int64Value(c.ExitCode))
+				}
+				containers.WriteString("\n")
+			}
+			s += "\n" + styles.DetailStyle.Render(
+				fmt.Sprintf("Task ARN:        %s\n", aws.StringValue(m.detailTask.TaskArn))+
+					fmt.Sprintf("Cluster:         %s\n", path.Base(aws.StringValue(m.detailTask.ClusterArn)))+
+					fmt.Sprintf("Last Status:     %s\n", aws.StringValue(m.detailTask.LastStatus))+
+					fmt.Sprintf("Desired Status:  %s\n", aws.StringValue(m.detailTask.DesiredStatus))+
+					fmt.Sprintf("Launch Type:     %s\n", aws.StringValue(m.detailTask.LaunchType))+
+					fmt.Sprintf("Task Definition: %s\n", aws.StringValue(m.detailTask.TaskDefinitionArn))+
+					fmt.Sprintf("Started At:      %s\n", formatTime(aws.TimeValue(m.detailTask.StartedAt)))+
+					fmt.Sprintf("Stopped At:      %s\n", formatTime(aws.TimeValue(m.detailTask.StoppedAt)))+
+					fmt.Sprintf("Stopped Reason:  %s\n", aws.StringValue(m.detailTask.StoppedReason))+
+					"Containers:\n"+containers.String()+
+					linksView(m.links(), m.linkCursor, m.keys)+
+					fmt.Sprintf("\nPress '%s' to go back.", m.keys.Back.Help().Key),
+			)
+		} else if m.status == "Ready" || m.err != nil {
+			s = styles.StatusStyle.Render("No task details available.\n")
+		}
+	case ecsStateTaskDefinition:
+		if m.taskDefinition != nil {
+			var containers strings.Builder
+			for _, c := range m.taskDefinition.ContainerDefinitions {
+				fmt.Fprintf(&containers, "  %s: %s\n", aws.StringValue(c.Name), aws.StringValue(c.Image))
+			}
+			s += "\n" + styles.DetailStyle.Render(
+				fmt.Sprintf("Family:       %s\n", aws.StringValue(m.taskDefinition.Family))+
+					fmt.Sprintf("Revision:     %d\n", aws.Int64Value(m.taskDefinition.Revision))+
+					fmt.Sprintf("ARN:          %s\n", aws.StringValue(m.taskDefinition.TaskDefinitionArn))+
+					fmt.Sprintf("CPU:          %s\n", aws.StringValue(m.taskDefinition.Cpu))+
+					fmt.Sprintf("Memory:       %s\n", aws.StringValue(m.taskDefinition.Memory))+
+					fmt.Sprintf("Network Mode: %s\n", aws.StringValue(m.taskDefinition.NetworkMode))+
+					"Containers:\n"+containers.String()+
+					linksView(m.links(), m.linkCursor, m.keys)+
+					fmt.Sprintf("\nPress '%s' to go back.", m.keys.Back.Help().Key),
+			)
+		} else if m.status == "Ready" || m.err != nil {
+			s = styles.StatusStyle.Render("No task definition available.\n")
+		}
 	case ecsStateServiceLogs:
 		if m.serviceLogs == "" && m.status == "Ready" {
 			s += styles.StatusStyle.Render("No logs found for this service.\n")
@@ -296,7 +624,7 @@ func (m ecsModel) View() string {
 			}
 		}
 		s += m.paginator.View()
-		s += "\n" + styles.HelpStyle.Render("Press 'esc' or 'backspace' to go back.")
+		s += "\n" + styles.HelpStyle.Render(fmt.Sprintf("Press '%s' to go back.", m.keys.Back.Help().Key))
 	}
 
 	return s
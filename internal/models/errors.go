@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theoreticallyjosh/awstui/internal/awserrors"
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxErrors caps how many errors are listed above the header; the rest are
+// counted.
+const maxErrors = 5
+
+// errorView lists the errors joined in err, such as those of every region
+// of a listing, one per line and labelled with their kind.
+func errorView(err error, width int) string {
+	errs := awserrors.Split(err)
+	lines := make([]string, 0, min(len(errs), maxErrors+1))
+	for i, e := range errs {
+		if i == maxErrors {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(errs)-maxErrors))
+			break
+		}
+		line := fmt.Sprintf("%s: %s", awserrors.Classify(e), strings.Join(strings.Fields(e.Error()), " "))
+		if r := []rune(line); width > 1 && len(r) > width {
+			line = string(r[:width-1]) + "…"
+		}
+		lines = append(lines, line)
+	}
+	return styles.ErrorStyle.Render(strings.Join(lines, "\n"))
+}
+
+// handleError shows err and, when it comes from expired credentials, offers
+// to log in again.
+func (m Model) handleError(err error) Model {
+	m.err = err
+	m.status = "Error"
+	m.notes.record(err)
+	if awserrors.Has(err, awserrors.ExpiredCredentials) && !m.confirming() {
+		m.login = m.loginDialog()
+	}
+	return m
+}
+
+// loginDialog asks to renew the credentials of the active profile with the
+// configured login command. Without one it stays closed and the error is
+// all the user gets.
+func (m Model) loginDialog() confirmDialog {
+	profile := clients.DisplayProfile(m.pool.Profile)
+	args := m.guard.conf.LoginCommand(profile, clients.UsesSSO(profile))
+	if len(args) == 0 {
+		return confirmDialog{}
+	}
+	return m.guard.open(confirmDialog{
+		title:    "Credentials expired — re-login",
+		resource: "profile " + profile,
+		changes:  []change{{field: "Command", to: strings.Join(args, " ")}},
+		accept:   commands.LoginCmd(m.pool.Profile, args),
+		local:    true,
+	})
+}
+
+// loggedIn reloads the current view with the renewed credentials. A new
+// session is needed, since the old one has cached the expired ones.
+func (m Model) loggedIn(msg messages.LoginDoneMsg) (Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.err = fmt.Errorf("login failed: %w", msg.Err)
+		m.notes.record(m.err)
+		return m, nil
+	}
+	if msg.Profile != m.pool.Profile {
+		return m, nil
+	}
+	if m.state != stateProfile && m.state != stateRegion {
+		m.prevState = m.state
+	}
+	m, cmd := m.switchProfile(msg.Profile)
+	m.status = fmt.Sprintf("Logged in to profile %s.", clients.DisplayProfile(msg.Profile))
+	m.notes.success("Logged in to profile %s", clients.DisplayProfile(msg.Profile))
+	return m, cmd
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/theoreticallyjosh/awstui/internal/awserrors"
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/favorites"
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stars tells the lists resources can be starred in which of their items
+// are favorites of the active profile. Like the selection it is shared with
+// the list's delegate, which renders the stars.
+type stars struct {
+	store   *favorites.Store
+	profile string
+}
+
+// has reports whether item is a favorite. A nil stars has none.
+func (s *stars) has(item list.Item) bool {
+	return s != nil && s.store.Has(s.profile, itemKey(item))
+}
+
+// Favorite item
+type favoriteItem struct {
+	favorite favorites.Favorite
+	// status is the live status of the resource, or the kind of error
+	// looking it up; empty until it has been fetched.
+	status string
+}
+
+// favoriteServices names the services of favorites for the user.
+var favoriteServices = map[string]string{
+	favorites.ServiceEC2:   "EC2 instance",
+	favorites.ServiceECS:   "ECS service",
+	favorites.ServiceECR:   "ECR repository",
+	favorites.ServiceSFN:   "State machine",
+	favorites.ServiceBatch: "Batch job queue",
+}
+
+func (i favoriteItem) Title() string {
+	return i.favorite.Name
+}
+
+func (i favoriteItem) Description() string {
+	status := i.status
+	if status == "" {
+		status = "…"
+	}
+	return fmt.Sprintf("%s | Region: %s | Status: %s", favoriteServices[i.favorite.Service], i.favorite.Region, status)
+}
+
+func (i favoriteItem) FilterValue() string {
+	return fields(
+		field{"name", i.favorite.Name},
+		field{"service", i.favorite.Service},
+		field{"region", i.favorite.Region},
+		field{"status", i.status},
+	)
+}
+
+func (i favoriteItem) key() string {
+	return i.favorite.Key
+}
+
+// favoritesModel lists the resources the active profile has starred, in
+// every service and region, with their live status. Choosing one opens it in
+// its service's view; see Model.openFavorite.
+type favoritesModel struct {
+	store        *favorites.Store
+	pool         *clients.Pool
+	scope        *scope
+	favoriteList list.Model
+	// pending counts the statuses still being fetched.
+	pending int
+	status  string
+	keys    *keys.ListKeyMap
+}
+
+func (m favoritesModel) Init() tea.Cmd {
+	return commands.FetchFavoritesCmd(m.store, clients.DisplayProfile(m.pool.Profile))
+}
+
+func (m favoritesModel) Update(msg tea.Msg) (favoritesModel, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.favoriteList.SetSize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		if m.favoriteList.FilterState() == list.Filtering {
+			break
+		}
+		if key.Matches(msg, m.keys.Refresh) {
+			m.status = "Loading favorites..."
+			return m, m.Init()
+		}
+	case messages.FavoritesFetchedMsg:
+		// Keep the statuses already known while they are fetched again.
+		known := map[string]string{}
+		for _, listItem := range m.favoriteList.Items() {
+			known[itemKey(listItem)] = listItem.(favoriteItem).status
+		}
+		items := make([]list.Item, len(msg))
+		cmds := make([]tea.Cmd, len(msg))
+		for i, f := range msg {
+			items[i] = favoriteItem{favorite: f, status: known[f.Key]}
+			cmds[i] = commands.FetchFavoriteStatusCmd(m.scope.context(), m.pool.Region(f.Region), f)
+		}
+		setItems(&m.favoriteList, items)
+		m.pending = len(msg)
+		m.status = "Ready"
+		if m.pending > 0 {
+			m.status = fmt.Sprintf("Checking %s...", count(m.pending, "favorite"))
+		}
+		return m, tea.Batch(cmds...)
+	case messages.FavoriteStatusMsg:
+		status := msg.Status
+		if msg.Err != nil {
+			status = awserrors.Classify(msg.Err).String()
+		}
+		for i, listItem := range m.favoriteList.Items() {
+			if item := listItem.(favoriteItem); item.favorite.Key == msg.Key {
+				item.status = status
+				m.favoriteList.SetItem(i, item)
+			}
+		}
+		m.pending = max(m.pending-1, 0)
+		m.status = "Ready"
+		if m.pending > 0 {
+			m.status = fmt.Sprintf("Checking %s...", count(m.pending, "favorite"))
+		}
+		return m, nil
+	}
+	m.favoriteList, cmd = m.favoriteList.Update(msg)
+	return m, cmd
+}
+
+// remove drops the favorite with key k from the list, once it is unstarred.
+func (m *favoritesModel) remove(k string) {
+	items := slices.DeleteFunc(slices.Clone(m.favoriteList.Items()), func(listItem list.Item) bool {
+		return itemKey(listItem) == k
+	})
+	setItems(&m.favoriteList, items)
+}
+
+func (m favoritesModel) View() string {
+	if len(m.favoriteList.Items()) == 0 && m.status == "Ready" {
+		return styles.StatusStyle.Render(fmt.Sprintf("No favorites yet. Press '%s' on an instance, service, repository, state machine or job queue to star it.\n", m.keys.Favorite.Help().Key))
+	}
+	return m.favoriteList.View()
+}
+
+// openFavorites shows the favorites of the active profile and checks on
+// each of them.
+func (m Model) openFavorites() (Model, tea.Cmd) {
+	m.state = stateFavorites
+	m.nav.push(stateFavorites, screenList, viewTitles[stateFavorites])
+	m.err = nil
+	m.favoritesModel.status = "Loading favorites..."
+	return m, m.favoritesModel.Init()
+}
+
+// openFavorite opens a favorite in the view of its service, the way the
+// command palette does, on top of the favorites. Unless the lists already
+// include its region it switches to it, which starts from the menu.
+func (m Model) openFavorite(f favorites.Favorite) (Model, tea.Cmd) {
+	m.leave()
+	if !m.queried(f.Region) {
+		m.prevState = stateMenu
+		m, _ = m.switchRegion(f.Region)
+	}
+	return m.jump(views[f.Service], f.Path)
+}
+
+// queried reports whether the lists include the resources of region.
+func (m Model) queried(region string) bool {
+	if len(m.regions) == 0 {
+		return region == m.pool.DefaultRegion
+	}
+	return slices.Contains(m.regions, region)
+}
+
+// star stars the resource selected in the list on screen, or unstars it if
+// it is a favorite already.
+func (m Model) star() (Model, tea.Cmd) {
+	f, ok := m.selectedFavorite()
+	if !ok {
+		return m, nil
+	}
+	added, err := m.favs.Toggle(f)
+	if err != nil {
+		return m.handleError(err), nil
+	}
+	if added {
+		m.notes.success("Starred %s", f.Name)
+	} else {
+		m.notes.success("Unstarred %s", f.Name)
+		if m.state == stateFavorites {
+			m.favoritesModel.remove(f.Key)
+		}
+	}
+	return m, nil
+}
+
+// selectedFavorite returns the resource selected in the list on screen as a
+// favorite, if it can be starred.
+func (m Model) selectedFavorite() (favorites.Favorite, bool) {
+	f := favorites.Favorite{Profile: clients.DisplayProfile(m.pool.Profile)}
+	switch {
+	case m.state == stateEC2:
+		item, ok := m.ec2Model.instanceList.SelectedItem().(ec2InstanceItem)
+		if !ok {
+			return f, false
+		}
+		f.Service, f.Region, f.Name = favorites.ServiceEC2, item.region, getInstanceName(item.instance)
+		f.Path = []string{aws.StringValue(item.instance.InstanceId)}
+		f.Key = item.key()
+	case m.state == stateECS && m.ecsModel.state == ecsStateServiceList:
+		item, ok := m.ecsModel.serviceList.SelectedItem().(ecsServiceItem)
+		if !ok {
+			return f, false
+		}
+		f.Service, f.Region, f.Name = favorites.ServiceECS, m.ecsModel.regionClients.Region, item.Title()
+		f.Path = []string{aws.StringValue(m.ecsModel.detailCluster.ClusterArn), item.key()}
+		f.Key = item.key()
+	case m.state == stateECR && m.ecrModel.state == ecrStateRepositoryList:
+		item, ok := m.ecrModel.repositoryList.SelectedItem().(ecrRepositoryItem)
+		if !ok {
+			return f, false
+		}
+		f.Service, f.Region, f.Name = favorites.ServiceECR, item.region, item.Title()
+		f.Path = []string{item.key()}
+		f.Key = item.key()
+	case m.state == stateSFN && m.sfnModel.state == sfnStateList:
+		item, ok := m.sfnModel.sfnList.SelectedItem().(sfnStateMachineItem)
+		if !ok {
+			return f, false
+		}
+		f.Service, f.Region, f.Name = favorites.ServiceSFN, item.region, item.Title()
+		f.Path = []string{item.key()}
+		f.Key = item.key()
+	case m.state == stateBatch && m.batchModel.state == batchStateJobQueueList:
+		item, ok := m.batchModel.jobQueueList.SelectedItem().(batchJobQueueItem)
+		if !ok {
+			return f, false
+		}
+		f.Service, f.Region, f.Name = favorites.ServiceBatch, item.region, item.Title()
+		f.Path = []string{item.key()}
+		f.Key = item.key()
+	case m.state == stateFavorites:
+		item, ok := m.favoritesModel.favoriteList.SelectedItem().(favoriteItem)
+		return item.favorite, ok
+	default:
+		return f, false
+	}
+	return f, true
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// guard applies the policy of the active profile and account to the mutating
+// actions of every view, and records them in the audit log. Like the key map
+// it is shared by all models, so the root model can update it when the
+// identity changes.
+type guard struct {
+	conf    *config.Config
+	keys    *keys.ListKeyMap
+	log     *audit.Log
+	profile string
+	account string
+	caller  string
+	policy  config.Policy
+	reason  string
+}
+
+func newGuard(conf *config.Config, listkeys *keys.ListKeyMap, log *audit.Log) *guard {
+	return &guard{conf: conf, keys: listkeys, log: log}
+}
+
+// setIdentity applies the policy of profile and account. The account and the
+// caller ARN are empty until the caller identity has been fetched.
+func (g *guard) setIdentity(profile, account, caller string) {
+	g.profile = profile
+	g.account = account
+	g.caller = caller
+	g.policy, g.reason = g.conf.Policy(profile, account)
+	g.keys.HideMutating = g.policy == config.PolicyReadOnly
+}
+
+// check returns why action may not run, or nil if it may.
+func (g *guard) check(action string) error {
+	if g.policy == config.PolicyReadOnly {
+		return fmt.Errorf("cannot %s: %s", action, g.reason)
+	}
+	if g.account == "" && g.conf.HasAccountPolicies() {
+		return fmt.Errorf("cannot %s: the account policy is not known yet", action)
+	}
+	return nil
+}
+
+// typed reports whether confirmations must be typed out.
+func (g *guard) typed() bool {
+	return g.policy == config.PolicyTypedConfirmation
+}
+
+// blocked is the status shown when an action is refused.
+func blocked(err error) string {
+	return fmt.Sprintf("Blocked: %v", err)
+}
+
+// open shows d for the active identity, asking for the name to be typed if
+// the policy requires it.
+func (g *guard) open(d confirmDialog) confirmDialog {
+	d.account = g.account
+	d.typed = d.highRisk || (g.typed() && !d.local)
+	d.visible = true
+	d.input = ""
+	return d
+}
+
+// audited records cmd in the audit log under the active identity when it
+// runs. action is named after the API call, e.g. "ec2:StopInstances".
+func (g *guard) audited(action, region, resource string, params map[string]string, cmd tea.Cmd) tea.Cmd {
+	return commands.Audited(g.log, audit.Entry{
+		Caller:   g.caller,
+		Account:  g.account,
+		Profile:  g.profile,
+		Region:   region,
+		Action:   action,
+		Resource: resource,
+		Params:   params,
+	}, cmd)
+}
+
+// auditedBulk is audited for a bulk action: each resource the action
+// reports on is recorded on its own, in its region and named by resource.
+func (g *guard) auditedBulk(action string, params map[string]string, resource func(messages.BulkResult) string, cmd tea.Cmd) tea.Cmd {
+	return commands.AuditedBulk(g.log, audit.Entry{
+		Caller:  g.caller,
+		Account: g.account,
+		Profile: g.profile,
+		Action:  action,
+		Params:  params,
+	}, resource, cmd)
+}
+
+// arn builds the ARN of a resource in the active account, for services whose
+// API does not return one.
+func (g *guard) arn(service, region, resource string) string {
+	partition := "aws"
+	if parts := strings.Split(g.caller, ":"); len(parts) > 1 {
+		partition = parts[1]
+	}
+	return fmt.Sprintf("arn:%s:%s:%s:%s:%s", partition, service, region, g.account, resource)
+}
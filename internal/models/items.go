@@ -2,8 +2,11 @@ package models
 
 import (
 	"fmt"
+	"path"
 	"time"
 
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/audit"
 	"github.com/theoreticallyjosh/awstui/internal/utils"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -31,19 +34,42 @@ func (i resourceItem) FilterValue() string { return i.title }
 // EC2 Instance Item
 type ec2InstanceItem struct {
 	instance *ec2.Instance
+	region   string
 }
 
 func (i ec2InstanceItem) Title() string {
 	return getInstanceName(i.instance)
 }
 func (i ec2InstanceItem) Description() string {
-	return fmt.Sprintf("ID: %s | State: %s | Type: %s",
+	return fmt.Sprintf("ID: %s | State: %s | Type: %s | Region: %s",
 		aws.StringValue(i.instance.InstanceId),
 		aws.StringValue(i.instance.State.Name),
 		aws.StringValue(i.instance.InstanceType),
+		i.region,
 	)
 }
-func (i ec2InstanceItem) FilterValue() string { return getInstanceName(i.instance) }
+func (i ec2InstanceItem) FilterValue() string {
+	fs := []field{
+		{"name", getInstanceName(i.instance)},
+		{"id", aws.StringValue(i.instance.InstanceId)},
+		{"type", aws.StringValue(i.instance.InstanceType)},
+		{"region", i.region},
+		{"ip", aws.StringValue(i.instance.PrivateIpAddress)},
+		{"ip", aws.StringValue(i.instance.PublicIpAddress)},
+		{"vpc", aws.StringValue(i.instance.VpcId)},
+	}
+	if i.instance.State != nil {
+		fs = append(fs, field{"state", aws.StringValue(i.instance.State.Name)})
+	}
+	if i.instance.Placement != nil {
+		fs = append(fs, field{"az", aws.StringValue(i.instance.Placement.AvailabilityZone)})
+	}
+	for _, tag := range i.instance.Tags {
+		fs = append(fs, field{"tag", aws.StringValue(tag.Key) + "=" + aws.StringValue(tag.Value)})
+	}
+	return fields(fs...)
+}
+func (i ec2InstanceItem) key() string { return i.region + "/" + aws.StringValue(i.instance.InstanceId) }
 
 func getInstanceName(instance *ec2.Instance) string {
 	for _, tag := range instance.Tags {
@@ -57,16 +83,25 @@ func getInstanceName(instance *ec2.Instance) string {
 // ECS Cluster Item
 type ecsClusterItem struct {
 	cluster *ecs.Cluster
+	region  string
 }
 
 func (i ecsClusterItem) Title() string {
 	return aws.StringValue(i.cluster.ClusterName)
 }
 func (i ecsClusterItem) Description() string {
-	return fmt.Sprintf("ARN: %s", aws.StringValue(i.cluster.ClusterArn))
+	return fmt.Sprintf("Region: %s | ARN: %s", i.region, aws.StringValue(i.cluster.ClusterArn))
 }
 func (i ecsClusterItem) FilterValue() string {
-	return aws.StringValue(i.cluster.ClusterName)
+	return fields(
+		field{"name", aws.StringValue(i.cluster.ClusterName)},
+		field{"status", aws.StringValue(i.cluster.Status)},
+		field{"region", i.region},
+		field{"arn", aws.StringValue(i.cluster.ClusterArn)},
+	)
+}
+func (i ecsClusterItem) key() string {
+	return aws.StringValue(i.cluster.ClusterArn)
 }
 
 // ECS Service Item
@@ -85,12 +120,22 @@ func (i ecsServiceItem) Description() string {
 	)
 }
 func (i ecsServiceItem) FilterValue() string {
-	return aws.StringValue(i.service.ServiceName)
+	return fields(
+		field{"name", aws.StringValue(i.service.ServiceName)},
+		field{"status", aws.StringValue(i.service.Status)},
+		field{"launch_type", aws.StringValue(i.service.LaunchType)},
+		field{"task_definition", path.Base(aws.StringValue(i.service.TaskDefinition))},
+		field{"arn", aws.StringValue(i.service.ServiceArn)},
+	)
+}
+func (i ecsServiceItem) key() string {
+	return aws.StringValue(i.service.ServiceArn)
 }
 
 // ECR Repository Item
 type ecrRepositoryItem struct {
 	repository *ecr.Repository
+	region     string
 }
 
 func (i ecrRepositoryItem) Title() string {
@@ -98,11 +143,19 @@ func (i ecrRepositoryItem) Title() string {
 }
 
 func (i ecrRepositoryItem) Description() string {
-	return fmt.Sprintf("URI: %s", aws.StringValue(i.repository.RepositoryUri))
+	return fmt.Sprintf("Region: %s | URI: %s", i.region, aws.StringValue(i.repository.RepositoryUri))
 }
 
 func (i ecrRepositoryItem) FilterValue() string {
-	return aws.StringValue(i.repository.RepositoryName)
+	return fields(
+		field{"name", aws.StringValue(i.repository.RepositoryName)},
+		field{"region", i.region},
+		field{"uri", aws.StringValue(i.repository.RepositoryUri)},
+	)
+}
+
+func (i ecrRepositoryItem) key() string {
+	return aws.StringValue(i.repository.RepositoryArn)
 }
 
 // ECR Image Item
@@ -117,6 +170,15 @@ func (i ecrImageItem) Title() string {
 	return aws.StringValue(i.image.ImageDigest)
 }
 
+// ref names the image to docker: by its first tag, or by its digest if it
+// has none.
+func (i ecrImageItem) ref() string {
+	if len(i.image.ImageTags) > 0 {
+		return aws.StringValue(i.image.ImageTags[0])
+	}
+	return aws.StringValue(i.image.ImageDigest)
+}
+
 func (i ecrImageItem) Description() string {
 	return fmt.Sprintf("Digest: %s | Pushed: %s",
 		aws.StringValue(i.image.ImageDigest),
@@ -125,15 +187,25 @@ func (i ecrImageItem) Description() string {
 }
 
 func (i ecrImageItem) FilterValue() string {
-	if len(i.image.ImageTags) > 0 {
-		return utils.ArrayToCSV(i.image.ImageTags)
+	fs := []field{{"digest", aws.StringValue(i.image.ImageDigest)}}
+	for _, tag := range i.image.ImageTags {
+		fs = append(fs, field{"tag", aws.StringValue(tag)})
 	}
+	return fields(fs...)
+}
+
+func (i ecrImageItem) key() string {
 	return aws.StringValue(i.image.ImageDigest)
 }
 
+func (i ecrImageItem) aliases() []string {
+	return aws.StringValueSlice(i.image.ImageTags)
+}
+
 // SFN State Machine Item
 type sfnStateMachineItem struct {
 	stateMachine *sfn.StateMachineListItem
+	region       string
 }
 
 func (i sfnStateMachineItem) Title() string {
@@ -141,11 +213,20 @@ func (i sfnStateMachineItem) Title() string {
 }
 
 func (i sfnStateMachineItem) Description() string {
-	return fmt.Sprintf("ARN: %s", aws.StringValue(i.stateMachine.StateMachineArn))
+	return fmt.Sprintf("Region: %s | ARN: %s", i.region, aws.StringValue(i.stateMachine.StateMachineArn))
 }
 
 func (i sfnStateMachineItem) FilterValue() string {
-	return aws.StringValue(i.stateMachine.Name)
+	return fields(
+		field{"name", aws.StringValue(i.stateMachine.Name)},
+		field{"type", aws.StringValue(i.stateMachine.Type)},
+		field{"region", i.region},
+		field{"arn", aws.StringValue(i.stateMachine.StateMachineArn)},
+	)
+}
+
+func (i sfnStateMachineItem) key() string {
+	return aws.StringValue(i.stateMachine.StateMachineArn)
 }
 
 // SFN Execution Item
@@ -165,5 +246,69 @@ func (i sfnExecutionItem) Description() string {
 }
 
 func (i sfnExecutionItem) FilterValue() string {
-	return aws.StringValue(i.execution.Name)
+	return fields(
+		field{"name", aws.StringValue(i.execution.Name)},
+		field{"status", aws.StringValue(i.execution.Status)},
+		field{"arn", aws.StringValue(i.execution.ExecutionArn)},
+	)
+}
+
+func (i sfnExecutionItem) key() string {
+	return aws.StringValue(i.execution.ExecutionArn)
+}
+
+// Audit log entry item
+type auditItem struct {
+	entry audit.Entry
+}
+
+func (i auditItem) Title() string {
+	return fmt.Sprintf("%s  %s  %s", i.entry.Time.Local().Format(time.DateTime), i.entry.Action, i.entry.Resource)
+}
+func (i auditItem) Description() string {
+	result := i.entry.Result
+	if i.entry.Error != "" {
+		result += ": " + i.entry.Error
+	}
+	return fmt.Sprintf("Result: %s | Profile: %s | Region: %s | Caller: %s", result, i.entry.Profile, i.entry.Region, i.entry.Caller)
+}
+func (i auditItem) FilterValue() string {
+	e := i.entry
+	return fields(
+		field{"action", e.Action},
+		field{"resource", e.Resource},
+		field{"profile", e.Profile},
+		field{"region", e.Region},
+		field{"account", e.Account},
+		field{"caller", e.Caller},
+		field{"result", e.Result},
+		field{"error", e.Error},
+	)
+}
+
+// AWS request item
+type apiCallItem struct {
+	call apilog.Call
+}
+
+func (i apiCallItem) Title() string {
+	title := fmt.Sprintf("%s  %s %s  %s", i.call.Time.Local().Format(time.TimeOnly), i.call.Service, i.call.Operation, i.call.Latency.Round(time.Millisecond))
+	if i.call.ErrorCode != "" {
+		title += "  " + i.call.ErrorCode
+	}
+	return title
+}
+func (i apiCallItem) Description() string {
+	return fmt.Sprintf("Region: %s | Request ID: %s | %s", i.call.Region, i.call.RequestID, i.call.Params)
+}
+func (i apiCallItem) FilterValue() string {
+	c := i.call
+	return fields(
+		field{"service", c.Service},
+		field{"operation", c.Operation},
+		field{"region", c.Region},
+		field{"params", c.Params},
+		field{"request_id", c.RequestID},
+		field{"error", c.ErrorCode},
+	)
 }
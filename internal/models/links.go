@@ -0,0 +1,249 @@
+package models
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// linkKind is the kind of resource a link leads to.
+type linkKind int
+
+const (
+	linkTaskDefinition linkKind = iota
+	linkTask
+	linkImage
+	linkJob
+)
+
+// linkKinds names the kinds of resources links lead to for the user.
+var linkKinds = map[linkKind]string{
+	linkTaskDefinition: "Task definition",
+	linkTask:           "ECS task",
+	linkImage:          "Image",
+	linkJob:            "Batch job",
+}
+
+// linkViews are the views links lead to, other than the one they are
+// followed from.
+var linkViews = map[linkKind]appState{
+	linkTask:  stateECS,
+	linkImage: stateECR,
+	linkJob:   stateBatch,
+}
+
+// link leads from the details of a resource to a related one, such as from
+// a service to its task definition or from an execution to the job it
+// submitted.
+type link struct {
+	kind  linkKind
+	label string
+	// region is the region of the resource, or empty if it is the region of
+	// the resource the link is followed from.
+	region string
+	// path heads for the resource the way the command palette does: the
+	// task definition; the cluster and task; the repository ARN and image
+	// tag or digest; or the job queue and job ID.
+	path []string
+}
+
+func (l link) String() string {
+	return linkKinds[l.kind] + " " + l.label
+}
+
+// followMsg asks the root model to follow a link into another view.
+type followMsg struct {
+	link link
+}
+
+func follow(l link) tea.Cmd {
+	return func() tea.Msg {
+		return followMsg{link: l}
+	}
+}
+
+// linkCursor selects one of the links of a detail view.
+type linkCursor int
+
+// update moves the cursor over n links, and reports whether the link it is
+// on is to be followed.
+func (c linkCursor) update(msg tea.KeyMsg, k *keys.ListKeyMap, n int) (linkCursor, bool) {
+	switch {
+	case key.Matches(msg, k.GoTo):
+		return c, int(c) < n
+	case key.Matches(msg, k.Up):
+		c = max(c-1, 0)
+	case key.Matches(msg, k.Down):
+		c = min(c+1, linkCursor(max(n-1, 0)))
+	}
+	return c, false
+}
+
+// linksView lists the links of a detail view, marking the one the cursor is
+// on, with how to follow it. It is empty if there are none.
+func linksView(links []link, c linkCursor, k *keys.ListKeyMap) string {
+	if len(links) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nGo to:\n")
+	for i, l := range links {
+		mark := "  "
+		if i == int(c) {
+			mark = "▸ "
+		}
+		b.WriteString(mark + l.String() + "\n")
+	}
+	b.WriteString(fmt.Sprintf("Press '%s' to go to the marked resource", k.GoTo.Help().Key))
+	if len(links) > 1 {
+		b.WriteString(fmt.Sprintf(", '%s' or '%s' to mark another", k.Up.Help().Key, k.Down.Help().Key))
+	}
+	return b.String() + ".\n"
+}
+
+// taskDefinitionLink links to a task definition by ARN.
+func taskDefinitionLink(region, arn string) link {
+	return link{kind: linkTaskDefinition, label: path.Base(arn), region: region, path: []string{arn}}
+}
+
+// imageLink links to the image of a container if it is in ECR, as in
+// 123456789012.dkr.ecr.eu-west-1.amazonaws.com/api:v1.2.0. Images without a
+// tag or digest are the latest tag.
+func imageLink(image string) (link, bool) {
+	host, name, ok := strings.Cut(image, "/")
+	if !ok {
+		return link{}, false
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 6 || labels[1] != "dkr" || labels[2] != "ecr" {
+		return link{}, false
+	}
+	account, region := labels[0], labels[3]
+	partition := "aws"
+	if strings.HasSuffix(host, ".cn") {
+		partition = "aws-cn"
+	}
+	repository, ref := name, "latest"
+	if i := strings.Index(name, "@"); i >= 0 {
+		repository, ref = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i >= 0 {
+		repository, ref = name[:i], name[i+1:]
+	}
+	label := repository + ":" + ref
+	if strings.Contains(ref, ":") {
+		label = repository + "@" + ref
+	}
+	return link{
+		kind:   linkImage,
+		label:  label,
+		region: region,
+		path:   []string{fmt.Sprintf("arn:%s:ecr:%s:%s:repository/%s", partition, region, account, repository), ref},
+	}, true
+}
+
+// launchedResource holds the fields of the parameters, output or error cause
+// of a Batch or ECS task state that tell what it launched. Field names are
+// matched regardless of case.
+type launchedResource struct {
+	JobId      string
+	JobName    string
+	JobQueue   string
+	TaskArn    string
+	ClusterArn string
+	Cluster    string
+	Tasks      []struct {
+		TaskArn    string
+		ClusterArn string
+	}
+}
+
+// launched links to the Batch job or ECS task a task event of an execution
+// reports on, if any. The job or task comes from what the task returned, or
+// the cause it failed with, and the region and queue or cluster from the
+// parameters it was scheduled with; region is the fallback.
+func launched(event *sfn.HistoryEvent, events map[int64]*sfn.HistoryEvent, region string) *link {
+	var resourceType, payload string
+	switch {
+	case event.TaskSubmittedEventDetails != nil:
+		resourceType, payload = aws.StringValue(event.TaskSubmittedEventDetails.ResourceType), aws.StringValue(event.TaskSubmittedEventDetails.Output)
+	case event.TaskSucceededEventDetails != nil:
+		resourceType, payload = aws.StringValue(event.TaskSucceededEventDetails.ResourceType), aws.StringValue(event.TaskSucceededEventDetails.Output)
+	case event.TaskFailedEventDetails != nil:
+		resourceType, payload = aws.StringValue(event.TaskFailedEventDetails.ResourceType), aws.StringValue(event.TaskFailedEventDetails.Cause)
+	default:
+		return nil
+	}
+	var out, in launchedResource
+	if json.Unmarshal([]byte(payload), &out) != nil {
+		return nil
+	}
+	if scheduled := scheduledTask(event, events); scheduled != nil {
+		region = cmp.Or(aws.StringValue(scheduled.Region), region)
+		// Parameters that are not JSON leave the output to go by.
+		_ = json.Unmarshal([]byte(aws.StringValue(scheduled.Parameters)), &in)
+	}
+	switch resourceType {
+	case "batch":
+		queue := cmp.Or(in.JobQueue, out.JobQueue)
+		if out.JobId == "" || queue == "" {
+			return nil
+		}
+		return &link{kind: linkJob, label: cmp.Or(out.JobName, out.JobId), region: region, path: []string{queue, out.JobId}}
+	case "ecs":
+		task, cluster := out.TaskArn, out.ClusterArn
+		if task == "" && len(out.Tasks) > 0 {
+			task, cluster = out.Tasks[0].TaskArn, out.Tasks[0].ClusterArn
+		}
+		if task == "" {
+			return nil
+		}
+		// Tasks run on the default cluster unless told otherwise.
+		cluster = cmp.Or(cluster, in.Cluster, "default")
+		return &link{kind: linkTask, label: path.Base(task), region: region, path: []string{cluster, task}}
+	}
+	return nil
+}
+
+// scheduledTask walks back from a task event to the details of the task
+// being scheduled.
+func scheduledTask(event *sfn.HistoryEvent, events map[int64]*sfn.HistoryEvent) *sfn.TaskScheduledEventDetails {
+	for i := aws.Int64Value(event.PreviousEventId); i != 0; {
+		cur, ok := events[i]
+		if !ok {
+			return nil
+		}
+		if cur.TaskScheduledEventDetails != nil {
+			return cur.TaskScheduledEventDetails
+		}
+		i = aws.Int64Value(cur.PreviousEventId)
+	}
+	return nil
+}
+
+// follow opens the resource l leads to on top of the screen it is followed
+// from, which going back returns to. Jobs and images are looked up in the
+// lists, so they can only be followed into the regions listed.
+func (m Model) follow(l link) (Model, tea.Cmd) {
+	if l.kind != linkTask && l.region != "" && !m.queried(l.region) {
+		m.err = fmt.Errorf("%s is in %s, which is not being listed; switch region to follow the link", l, l.region)
+		return m, nil
+	}
+	m.leave()
+	if l.kind == linkTask {
+		var cmd tea.Cmd
+		m.state = stateECS
+		m.err = nil
+		m.ecsModel, cmd = m.ecsModel.openTask(l.region, l.path[0], l.path[1])
+		return m, tea.Batch(cmd, m.scheduleRefresh())
+	}
+	return m.jump(linkViews[l.kind], l.path)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+)
+
+func TestLinkCursorFollowsTheKeys(t *testing.T) {
+	k := keys.NewListKeyMap()
+	if err := k.Remap(map[string][]string{"up": {"w"}, "down": {"z"}, "go_to": {"O"}}); err != nil {
+		t.Fatal(err)
+	}
+	var c linkCursor
+	var followed bool
+	for _, press := range []string{"z", "z", "j", "w"} {
+		c, followed = c.update(keyMsg(press), k, 3)
+		if followed {
+			t.Fatalf("%s followed a link", press)
+		}
+	}
+	if c != 1 {
+		t.Fatalf("cursor = %d, want it moved by the remapped keys alone", c)
+	}
+	if _, followed = c.update(keyMsg("O"), k, 3); !followed {
+		t.Fatal("go-to key did not follow the link")
+	}
+
+	links := []link{taskDefinitionLink("", "arn:aws:ecs:us-east-1:123456789012:task-definition/api:3"), {kind: linkImage, label: "api:v1.2.0"}}
+	hint := linksView(links, c, k)
+	if !strings.Contains(hint, "Press 'O' to go to the marked resource, 'w' or 'z' to mark another.") {
+		t.Fatalf("hint does not show the remapped keys:\n%s", hint)
+	}
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listLoader streams the pages of a list fetch into a list.Model. A fetch
+// runs a fixed number of page chains side by side; the first page to arrive
+// replaces the list's items, later pages are appended, and the load is done
+// once every chain has delivered its last page. Until then the previous items
+// stay on screen.
+//
+// A quiet load, used by auto-refresh, collects every page first and swaps
+// the result in at once, so the list does not shrink and regrow.
+//
+// A load cancelled by leaving the screen is marked interrupted until the
+// next load starts, so the list can be reloaded when the user comes back.
+//
+// Every load is numbered, and its pages come back tagged with the loader's
+// noun and the number, as a pageMsg. Models hand a pageMsg on only if it
+// belongs to the current load of one of their loaders, so starting a load
+// while another is in progress, such as by refreshing twice, drops the pages
+// still to come from the first instead of mixing them into the list.
+type listLoader struct {
+	noun        string
+	chains      int
+	gen         int
+	pending     int
+	fresh       bool
+	loaded      int
+	errs        []error
+	quiet       bool
+	buffer      []list.Item
+	interrupted bool
+}
+
+func newListLoader(noun string, chains int) listLoader {
+	return listLoader{noun: noun, chains: max(chains, 1)}
+}
+
+// regionChains is the number of page chains a fetch across regions runs.
+func regionChains(regions []string) int {
+	return max(len(regions), 1)
+}
+
+// loading reports whether a load is in progress.
+func (ld listLoader) loading() bool {
+	return ld.pending > 0
+}
+
+// load starts a load that fetches the first pages with cmd, abandoning the
+// load in progress, if any.
+func (ld *listLoader) load(cmd tea.Cmd) tea.Cmd {
+	ld.cancel()
+	ld.gen++
+	ld.pending = ld.chains
+	ld.fresh = true
+	ld.loaded = 0
+	ld.errs = nil
+	ld.interrupted = false
+	return tagPages(ld.noun, ld.gen, cmd)
+}
+
+// reload starts a quiet load; see load.
+func (ld *listLoader) reload(cmd tea.Cmd) tea.Cmd {
+	cmd = ld.load(cmd)
+	ld.quiet = true
+	return cmd
+}
+
+// current reports whether msg is a page of the load in progress.
+func (ld listLoader) current(msg pageMsg) bool {
+	return msg.noun == ld.noun && msg.gen == ld.gen && ld.pending > 0
+}
+
+// add merges a page of the current load into l and returns the command that
+// fetches the next page. Once the last chain finishes, any errors are
+// reported as an ErrMsg.
+func (ld *listLoader) add(l *list.Model, items []list.Item, page messages.Page) tea.Cmd {
+	if ld.pending == 0 {
+		return nil
+	}
+
+	switch {
+	case page.Err != nil:
+		ld.errs = append(ld.errs, page.Err)
+	case ld.quiet:
+		ld.buffer = append(ld.buffer, items...)
+	case ld.fresh:
+		setItems(l, items)
+		ld.fresh = false
+	default:
+		setItems(l, append(slices.Clone(l.Items()), items...))
+	}
+	ld.loaded += len(items)
+
+	if !page.Last() {
+		return tagPages(ld.noun, ld.gen, page.Next)
+	}
+	ld.pending--
+	if ld.pending > 0 {
+		return nil
+	}
+	if ld.quiet {
+		if len(ld.errs) == 0 {
+			setItems(l, ld.buffer)
+		}
+		ld.quiet = false
+		ld.buffer = nil
+	}
+	if len(ld.errs) == 0 {
+		return nil
+	}
+	err := errors.Join(ld.errs...)
+	return func() tea.Msg { return messages.ErrMsg(err) }
+}
+
+// cancel abandons the load in progress, whose remaining pages will not
+// arrive.
+func (ld *listLoader) cancel() {
+	if ld.pending > 0 {
+		ld.interrupted = true
+	}
+	ld.pending = 0
+	ld.quiet = false
+	ld.buffer = nil
+}
+
+// pageMsg is a page of the load numbered gen of the loader named noun.
+type pageMsg struct {
+	noun string
+	gen  int
+	msg  tea.Msg
+}
+
+// tagPages wraps the pages cmd fetches, and those of the batches it returns,
+// as pages of a load. Other messages, such as errors, are passed through.
+func tagPages(noun string, gen int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = tagPages(noun, gen, c)
+			}
+			return cmds
+		case messages.Paged:
+			return pageMsg{noun: noun, gen: gen, msg: msg}
+		default:
+			return msg
+		}
+	}
+}
+
+// status describes the progress of the load, or "Ready" once it is done.
+// Quiet loads are not reported.
+func (ld listLoader) status() string {
+	if ld.pending > 0 && !ld.quiet {
+		return fmt.Sprintf("Loading %s... (%d loaded so far)", ld.noun, ld.loaded)
+	}
+	return "Ready"
+}
+
+// keyedItem is implemented by items that identify a resource, so that the
+// selection can follow the resource when the list is reloaded.
+type keyedItem interface {
+	key() string
+}
+
+func itemKey(item list.Item) string {
+	switch item := item.(type) {
+	case nil:
+		return ""
+	case keyedItem:
+		return item.key()
+	default:
+		return item.FilterValue()
+	}
+}
+
+// setItems replaces the items of l and keeps the selected resource selected.
+// An applied filter is re-run straight away rather than asynchronously, so
+// the selection can be restored among the filtered items.
+func setItems(l *list.Model, items []list.Item) {
+	selected := itemKey(l.SelectedItem())
+	if cmd := l.SetItems(items); cmd != nil {
+		*l, _ = l.Update(cmd())
+	}
+	if selected == "" {
+		return
+	}
+	for i, item := range l.VisibleItems() {
+		if itemKey(item) == selected {
+			l.Select(i)
+			return
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type testItem string
+
+func (i testItem) FilterValue() string { return string(i) }
+
+func items(names ...string) []list.Item {
+	var items []list.Item
+	for _, n := range names {
+		items = append(items, testItem(n))
+	}
+	return items
+}
+
+func titles(l list.Model) []string {
+	var titles []string
+	for _, item := range l.Items() {
+		titles = append(titles, item.FilterValue())
+	}
+	return titles
+}
+
+var more = messages.Page{Next: func() tea.Msg { return nil }}
+
+func TestListLoaderStreamsPages(t *testing.T) {
+	l := list.New(items("old"), list.NewDefaultDelegate(), 0, 0)
+	ld := newListLoader("items", 2)
+	ld.load(nil)
+
+	if cmd := ld.add(&l, items("a"), more); cmd == nil {
+		t.Fatal("no command for the next page")
+	}
+	if got := titles(l); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("first page left %q, want it to replace the list", got)
+	}
+	if ld.status() != "Loading items... (1 loaded so far)" {
+		t.Fatalf("status = %q", ld.status())
+	}
+	ld.add(&l, items("b"), messages.Page{})
+	if !ld.loading() {
+		t.Fatal("load done before the second chain finished")
+	}
+	ld.add(&l, items("c"), messages.Page{})
+	if got := titles(l); len(got) != 3 {
+		t.Fatalf("items = %q, want every page appended", got)
+	}
+	if ld.loading() || ld.status() != "Ready" {
+		t.Fatalf("load not done: %q", ld.status())
+	}
+}
+
+func TestListLoaderQuietLoadSwapsAtOnce(t *testing.T) {
+	l := list.New(items("old"), list.NewDefaultDelegate(), 0, 0)
+	ld := newListLoader("items", 1)
+	ld.reload(nil)
+
+	ld.add(&l, items("a"), more)
+	if got := titles(l); len(got) != 1 || got[0] != "old" {
+		t.Fatalf("quiet load showed a partial page: %q", got)
+	}
+	ld.add(&l, items("b"), messages.Page{})
+	if got := titles(l); len(got) != 2 || got[0] != "a" {
+		t.Fatalf("items = %q, want the pages swapped in", got)
+	}
+}
+
+func TestListLoaderReportsErrorsOnceDone(t *testing.T) {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	ld := newListLoader("items", 2)
+	ld.load(nil)
+
+	if cmd := ld.add(&l, nil, messages.Page{Err: errors.New("denied")}); cmd != nil {
+		t.Fatal("error reported before the other chain finished")
+	}
+	cmd := ld.add(&l, items("a"), messages.Page{})
+	if cmd == nil {
+		t.Fatal("error not reported")
+	}
+	if _, ok := cmd().(messages.ErrMsg); !ok {
+		t.Fatal("error not reported as an ErrMsg")
+	}
+}
+
+func TestListLoaderCancelMarksInterrupted(t *testing.T) {
+	ld := newListLoader("items", 1)
+
+	ld.load(nil)
+	ld.cancel()
+	if ld.loading() || !ld.interrupted {
+		t.Fatal("cancelled load not marked interrupted")
+	}
+	ld.load(nil)
+	if ld.interrupted {
+		t.Fatal("next load still marked interrupted")
+	}
+}
+
+func TestListLoaderDropsPagesOfAbandonedLoads(t *testing.T) {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	ld := newListLoader("items", 1)
+	page := func() tea.Msg { return messages.InstancesFetchedMsg{} }
+
+	first := ld.load(page)().(pageMsg)
+	second := ld.load(page)().(pageMsg)
+	if ld.current(first) || !ld.current(second) {
+		t.Fatal("page of the abandoned load taken for the current one")
+	}
+	if other := (listLoader{noun: "others", gen: ld.gen, pending: 1}); other.current(second) {
+		t.Fatal("page taken for another loader's")
+	}
+	ld.add(&l, items("a"), messages.Page{})
+	if ld.current(second) {
+		t.Fatal("page taken for a finished load")
+	}
+}
+
+// instanceIDs lists the instances on screen, failing on duplicates.
+func (tm *testModel) instanceIDs() []string {
+	tm.t.Helper()
+	seen := map[string]bool{}
+	var ids []string
+	for _, item := range tm.m.ec2Model.instanceList.Items() {
+		id := item.(ec2InstanceItem).region + "/" + aws.StringValue(item.(ec2InstanceItem).instance.InstanceId)
+		if seen[id] {
+			tm.t.Fatalf("instance %s listed twice", id)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestLoadingPagesAcrossRegions(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ec2")
+	if ids := tm.instanceIDs(); len(ids) != 3 {
+		t.Fatalf("instances = %q, want the 3 of us-east-1 over 2 pages", ids)
+	}
+
+	tm.command("region all")
+	tm.command("ec2")
+	if ids := tm.instanceIDs(); len(ids) != 6 {
+		t.Fatalf("instances = %q, want the 3 of each region", ids)
+	}
+	tm.wantView("all regions (", "eu-west-1", "Ready")
+}
+
+// pages runs cmd, and the batches it returns, for the messages it results
+// in, without delivering them.
+func (tm *testModel) pages(cmd tea.Cmd) []tea.Msg {
+	tm.t.Helper()
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		switch msg := msg.(type) {
+		case nil, spinner.TickMsg:
+			return nil
+		case tea.BatchMsg:
+			var msgs []tea.Msg
+			for _, c := range msg {
+				msgs = append(msgs, tm.pages(c)...)
+			}
+			return msgs
+		}
+		return []tea.Msg{msg}
+	case <-time.After(settle):
+		return nil
+	}
+}
+
+func TestRefreshDuringLoadRestartsIt(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("region all")
+	tm.command("ec2")
+
+	// Refresh again before the pages of the first refresh arrive, and let
+	// the pages of both arrive in turn.
+	var loads [2][]tea.Msg
+	for i := range loads {
+		next, cmd := tm.m.Update(keyMsg("r"))
+		tm.m = next.(Model)
+		loads[i] = tm.pages(cmd)
+	}
+	for i := range max(len(loads[0]), len(loads[1])) {
+		for _, msgs := range loads {
+			if i < len(msgs) {
+				tm.send(msgs[i])
+			}
+		}
+	}
+	if ids := tm.instanceIDs(); len(ids) != 6 {
+		t.Fatalf("instances = %q, want the 3 of each region", ids)
+	}
+	tm.wantView("Ready")
+}
@@ -1,26 +1,31 @@
 package models
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/favorites"
 	"github.com/theoreticallyjosh/awstui/internal/keys"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
 	"github.com/theoreticallyjosh/awstui/internal/styles"
 
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/batch"
-	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
-	"github.com/aws/aws-sdk-go/service/ec2"
-	"github.com/aws/aws-sdk-go/service/ecr"
-	"github.com/aws/aws-sdk-go/service/ecs"
-	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/paginator"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -35,25 +40,81 @@ const (
 	stateECR
 	stateSFN
 	stateBatch
+	stateProfile
+	stateRegion
+	stateAudit
+	stateAPI
+	stateFavorites
 )
 
+// allRegions is the region picker entry that fans every list out across all
+// enabled regions.
+const allRegions = "all"
+
+// defaultRefreshInterval is used when auto-refresh is switched on for a view
+// that has no interval configured.
+const defaultRefreshInterval = 30 * time.Second
+
+// views maps the view names used by the config file and the command palette
+// to their states.
+var views = map[string]appState{
+	"ec2":   stateEC2,
+	"ecs":   stateECS,
+	"ecr":   stateECR,
+	"sfn":   stateSFN,
+	"batch": stateBatch,
+}
+
+// autoRefreshMsg is delivered when the view in state is due for a refresh.
+// Ticks of an earlier schedule carry an outdated gen and are dropped.
+type autoRefreshMsg struct {
+	state appState
+	gen   int
+}
+
 // Model represents the state of our TUI application.
 type Model struct {
-	ec2Model    ec2Model
-	ecsModel    ecsModel
-	ecrModel    ecrModel
-	sfnModel    sfnModel
-	batchModel  batchModel
-	spinner     spinner.Model
-	status      string
-	err         error
-	state       appState
-	menuCursor  int
-	menuChoices list.Model
-	keys        *keys.ListKeyMap
-	width       int
-	height      int
-	statusStyle lipgloss.Style
+	ec2Model       ec2Model
+	ecsModel       ecsModel
+	ecrModel       ecrModel
+	sfnModel       sfnModel
+	batchModel     batchModel
+	auditModel     auditModel
+	apiModel       apiModel
+	favoritesModel favoritesModel
+	spinner        spinner.Model
+	status         string
+	err            error
+	state          appState
+	menuCursor     int
+	menuChoices    list.Model
+	keys           *keys.ListKeyMap
+	width          int
+	height         int
+	statusStyle    lipgloss.Style
+	pool           *clients.Pool
+	regions        []string
+	profileList    list.Model
+	regionList     list.Model
+	prevState      appState
+	palette        textinput.Model
+	guard          *guard
+	scope          *scope
+	favs           *favorites.Store
+	login          confirmDialog
+	notes          *notifier
+	drawer         drawer
+	ops            *operations
+	tray           tray
+
+	// nav holds the screens opened on the way to the one on show.
+	nav *navigation
+
+	enabledRegions []string
+
+	refreshIntervals map[appState]time.Duration
+	refreshEnabled   map[appState]bool
+	refreshGen       int
 }
 
 func setListStyle(l *list.Model) {
@@ -72,7 +133,8 @@ func setListStyle(l *list.Model) {
 	l.Paginator.ActiveDot = styles.ActivePager.Render("•")
 	l.Paginator.InactiveDot = styles.InactivePager.Render("•")
 	l.Styles = st
-
+	// Every list takes field terms as well as free text; see queryFilter.
+	l.Filter = queryFilter
 }
 
 func newSpinner() spinner.Model {
@@ -82,31 +144,19 @@ func newSpinner() spinner.Model {
 	return s
 }
 
-func newAWSClients() (*ec2.EC2, *ecs.ECS, *ecr.ECR, *cloudwatchlogs.CloudWatchLogs, *sfn.SFN, *batch.Batch) {
-	sess, err := session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	})
-	if err != nil {
-		log.Fatalf("Failed to create AWS session: %v", err)
-	}
-
-	// Create AWS service clients
-	ec2Svc := ec2.New(sess)
-	ecsSvc := ecs.New(sess)
-	ecrSvc := ecr.New(sess)
-	cloudwatchlogsSvc := cloudwatchlogs.New(sess)
-	sfnSvc := sfn.New(sess)
-	batchSvc := batch.New(sess)
-	return ec2Svc, ecsSvc, ecrSvc, cloudwatchlogsSvc, sfnSvc, batchSvc
-}
-
-func newMainMenu(listkeys *keys.ListKeyMap) list.Model {
+func newMainMenu(listkeys *keys.ListKeyMap, views []config.View) list.Model {
 	items := []list.Item{
 		resourceItem{title: "EC2", desc: "Elastic Compute Cloud"},
 		resourceItem{title: "ECS", desc: "Elastic Container Service"},
 		resourceItem{title: "ECR", desc: "Elastic Container Registry"},
 		resourceItem{title: "Step Functions", desc: "Step Functions"},
 		resourceItem{title: "Batch", desc: "Batch Jobs"},
+		resourceItem{title: "Favorites", desc: "Starred resources of every service"},
+		resourceItem{title: "Audit Log", desc: "Actions taken from awstui"},
+		resourceItem{title: "API Calls", desc: "AWS requests made in this session"},
+	}
+	for _, v := range views {
+		items = append(items, viewItem{view: v})
 	}
 
 	mainList := list.New(items, ItemDelegate{}, 0, 0)
@@ -117,13 +167,87 @@ func newMainMenu(listkeys *keys.ListKeyMap) list.Model {
 	mainList.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			listkeys.Choose,
+			listkeys.Command,
+			listkeys.Profile,
+			listkeys.Region,
 		}
 	}
 	mainList.AdditionalFullHelpKeys = mainList.AdditionalShortHelpKeys
 	return mainList
 }
 
-func newEC2List(listkeys *keys.ListKeyMap) list.Model {
+func newProfileList(listkeys *keys.ListKeyMap, active string) list.Model {
+	var items []list.Item
+	for _, p := range clients.ListProfiles() {
+		desc := "Sources: " + strings.Join(p.Sources, ", ")
+		if p.Name == active {
+			desc += " | Active"
+		}
+		items = append(items, resourceItem{title: p.Name, desc: desc})
+	}
+
+	profileList := list.New(items, ItemDelegate{}, 0, 0)
+	profileList.SetShowTitle(false)
+	profileList.SetShowStatusBar(false)
+	profileList.SetFilteringEnabled(true)
+	setListStyle(&profileList)
+	profileList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listkeys.Choose,
+			listkeys.Back,
+		}
+	}
+	profileList.AdditionalShortHelpKeys = profileList.AdditionalFullHelpKeys
+	return profileList
+}
+
+// partitionRegions lists the commercial AWS regions known to the SDK, used
+// until the regions enabled for the account have been fetched.
+func partitionRegions() []string {
+	var regions []string
+	for id := range endpoints.AwsPartition().Regions() {
+		regions = append(regions, id)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+func newRegionList(listkeys *keys.ListKeyMap, enabled, selected []string, defaultRegion string) list.Model {
+	if len(enabled) == 0 {
+		enabled = partitionRegions()
+	}
+	desc := "Query every enabled region at once"
+	if len(selected) > 1 {
+		desc += " | Active"
+	}
+	items := []list.Item{resourceItem{title: allRegions, desc: desc}}
+	for _, r := range enabled {
+		var notes []string
+		if r == defaultRegion {
+			notes = append(notes, "Profile default")
+		}
+		if (len(selected) == 0 && r == defaultRegion) || (len(selected) == 1 && selected[0] == r) {
+			notes = append(notes, "Active")
+		}
+		items = append(items, resourceItem{title: r, desc: strings.Join(append([]string{"Region"}, notes...), " | ")})
+	}
+
+	regionList := list.New(items, ItemDelegate{}, 0, 0)
+	regionList.SetShowTitle(false)
+	regionList.SetShowStatusBar(false)
+	regionList.SetFilteringEnabled(true)
+	setListStyle(&regionList)
+	regionList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listkeys.Choose,
+			listkeys.Back,
+		}
+	}
+	regionList.AdditionalShortHelpKeys = regionList.AdditionalFullHelpKeys
+	return regionList
+}
+
+func newEC2List(listkeys *keys.ListKeyMap, t *table) list.Model {
 	ec2List := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
 	ec2List.SetShowTitle(false)
 	ec2List.SetShowStatusBar(false)
@@ -131,15 +255,23 @@ func newEC2List(listkeys *keys.ListKeyMap) list.Model {
 	setListStyle(&ec2List)
 
 	ec2List.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Details,
 			listkeys.Start,
 			listkeys.Stop,
 			listkeys.Ssh,
+			listkeys.Favorite,
+			listkeys.Mark,
+			listkeys.MarkAll,
+			listkeys.Table,
+			listkeys.Sort,
 			listkeys.Refresh,
-		}
+			listkeys.AutoRefresh,
+			listkeys.Back,
+		)
 	}
 	ec2List.AdditionalShortHelpKeys = ec2List.AdditionalFullHelpKeys
+	t.apply(&ec2List)
 	return ec2List
 }
 
@@ -150,78 +282,100 @@ func newECSClusterList(listkeys *keys.ListKeyMap) list.Model {
 	ecsClusterList.SetFilteringEnabled(true)
 	setListStyle(&ecsClusterList)
 	ecsClusterList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Choose,
 			listkeys.Refresh,
-		}
+			listkeys.AutoRefresh,
+			listkeys.Back,
+		)
 	}
 	ecsClusterList.AdditionalShortHelpKeys = ecsClusterList.AdditionalFullHelpKeys
 	return ecsClusterList
 }
 
-func newECSServiceList(listkeys *keys.ListKeyMap) list.Model {
+func newECSServiceList(listkeys *keys.ListKeyMap, t *table) list.Model {
 	ecsServiceList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
 	ecsServiceList.SetShowTitle(false)
 	ecsServiceList.SetShowStatusBar(false)
 	ecsServiceList.SetFilteringEnabled(true)
 	setListStyle(&ecsServiceList)
 	ecsServiceList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Details,
 			listkeys.Stop,
+			listkeys.ForceDeploy,
+			listkeys.Favorite,
+			listkeys.Mark,
+			listkeys.MarkAll,
+			listkeys.Table,
+			listkeys.Sort,
 			listkeys.Refresh,
+			listkeys.AutoRefresh,
 			listkeys.Logs,
-		}
+			listkeys.Back,
+		)
 	}
 	ecsServiceList.AdditionalShortHelpKeys = ecsServiceList.AdditionalFullHelpKeys
+	t.apply(&ecsServiceList)
 	return ecsServiceList
 }
 
-func newECRRepositoryList(listkeys *keys.ListKeyMap) list.Model {
-	ecrRepositoryList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+func newECRRepositoryList(listkeys *keys.ListKeyMap, stars *stars) list.Model {
+	ecrRepositoryList := list.New([]list.Item{}, ItemDelegate{stars: stars}, 0, 0)
 	ecrRepositoryList.SetShowTitle(false)
 	ecrRepositoryList.SetShowStatusBar(false)
 	ecrRepositoryList.SetFilteringEnabled(true)
 	setListStyle(&ecrRepositoryList)
 	ecrRepositoryList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Choose,
+			listkeys.Favorite,
 			listkeys.Refresh,
-		}
+			listkeys.AutoRefresh,
+			listkeys.Back,
+		)
 	}
 	ecrRepositoryList.AdditionalShortHelpKeys = ecrRepositoryList.AdditionalFullHelpKeys
 	return ecrRepositoryList
 }
 
-func newECRImageList(listkeys *keys.ListKeyMap) list.Model {
-	ecrImageList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+func newECRImageList(listkeys *keys.ListKeyMap, marks *selection) list.Model {
+	ecrImageList := list.New([]list.Item{}, ItemDelegate{marks: marks}, 0, 0)
 	ecrImageList.SetShowTitle(false)
 	ecrImageList.SetShowStatusBar(false)
 	ecrImageList.SetFilteringEnabled(true)
 	setListStyle(&ecrImageList)
 	ecrImageList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Refresh,
+			listkeys.AutoRefresh,
 			listkeys.Pull,
 			listkeys.Push,
-		}
+			listkeys.Delete,
+			listkeys.Mark,
+			listkeys.MarkAll,
+			listkeys.Back,
+		)
 	}
 	ecrImageList.AdditionalShortHelpKeys = ecrImageList.AdditionalFullHelpKeys
 	return ecrImageList
 }
 
-func newSFNList(listkeys *keys.ListKeyMap) list.Model {
-	sfnList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+func newSFNList(listkeys *keys.ListKeyMap, stars *stars) list.Model {
+	sfnList := list.New([]list.Item{}, ItemDelegate{stars: stars}, 0, 0)
 	sfnList.SetShowTitle(false)
 	sfnList.SetShowStatusBar(false)
 	sfnList.SetFilteringEnabled(true)
 	setListStyle(&sfnList)
 	sfnList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Choose,
+			listkeys.Favorite,
 			listkeys.Refresh,
+			listkeys.AutoRefresh,
 			listkeys.StartExecution,
-		}
+			listkeys.Back,
+		)
 	}
 	sfnList.AdditionalShortHelpKeys = sfnList.AdditionalFullHelpKeys
 	return sfnList
@@ -234,10 +388,12 @@ func newSFNExecutionList(listkeys *keys.ListKeyMap) list.Model {
 	sfnExecutionList.SetFilteringEnabled(true)
 	setListStyle(&sfnExecutionList)
 	sfnExecutionList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Choose,
 			listkeys.Refresh,
-		}
+			listkeys.AutoRefresh,
+			listkeys.Back,
+		)
 	}
 	sfnExecutionList.AdditionalShortHelpKeys = sfnExecutionList.AdditionalFullHelpKeys
 	return sfnExecutionList
@@ -250,48 +406,116 @@ func newSFNExecutionHistoryList(listkeys *keys.ListKeyMap) list.Model {
 	sfnExecutionList.SetFilteringEnabled(true)
 	setListStyle(&sfnExecutionList)
 	sfnExecutionList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
+			listkeys.GoTo,
 			listkeys.Refresh,
-		}
+			listkeys.AutoRefresh,
+			listkeys.Back,
+		)
 	}
 	sfnExecutionList.AdditionalShortHelpKeys = sfnExecutionList.AdditionalFullHelpKeys
 	return sfnExecutionList
 }
 
-func newBatchJobQueueList(listkeys *keys.ListKeyMap) list.Model {
-	batchJobQueueList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+func newBatchJobQueueList(listkeys *keys.ListKeyMap, stars *stars) list.Model {
+	batchJobQueueList := list.New([]list.Item{}, ItemDelegate{stars: stars}, 0, 0)
 	batchJobQueueList.SetShowTitle(false)
 	batchJobQueueList.SetShowStatusBar(false)
 	batchJobQueueList.SetFilteringEnabled(true)
 	setListStyle(&batchJobQueueList)
 	batchJobQueueList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Choose,
+			listkeys.Favorite,
 			listkeys.Refresh,
-		}
+			listkeys.AutoRefresh,
+			listkeys.Back,
+		)
 	}
 	batchJobQueueList.AdditionalShortHelpKeys = batchJobQueueList.AdditionalFullHelpKeys
 	return batchJobQueueList
 }
 
-func newBatchJobList(listkeys *keys.ListKeyMap) list.Model {
+func newBatchJobList(listkeys *keys.ListKeyMap, t *table) list.Model {
 	batchJobList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
 	batchJobList.SetShowTitle(false)
 	batchJobList.SetShowStatusBar(false)
 	batchJobList.SetFilteringEnabled(true)
 	setListStyle(&batchJobList)
 	batchJobList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		return listkeys.Help(
 			listkeys.Details,
 			listkeys.Stop,
+			listkeys.Mark,
+			listkeys.MarkAll,
+			listkeys.Table,
+			listkeys.Sort,
 			listkeys.Refresh,
+			listkeys.AutoRefresh,
 			listkeys.Logs,
-		}
+			listkeys.Back,
+		)
 	}
 	batchJobList.AdditionalShortHelpKeys = batchJobList.AdditionalFullHelpKeys
+	t.apply(&batchJobList)
 	return batchJobList
 }
 
+func newAuditList(listkeys *keys.ListKeyMap) list.Model {
+	auditList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+	auditList.SetShowTitle(false)
+	auditList.SetShowStatusBar(false)
+	auditList.SetFilteringEnabled(true)
+	setListStyle(&auditList)
+
+	auditList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listkeys.Details,
+			listkeys.Refresh,
+			listkeys.Back,
+		}
+	}
+	auditList.AdditionalShortHelpKeys = auditList.AdditionalFullHelpKeys
+	return auditList
+}
+
+func newAPICallList(listkeys *keys.ListKeyMap) list.Model {
+	callList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+	callList.SetShowTitle(false)
+	callList.SetShowStatusBar(false)
+	callList.SetFilteringEnabled(true)
+	setListStyle(&callList)
+
+	callList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listkeys.Details,
+			listkeys.Refresh,
+			listkeys.Back,
+		}
+	}
+	callList.AdditionalShortHelpKeys = callList.AdditionalFullHelpKeys
+	return callList
+}
+
+func newFavoriteList(listkeys *keys.ListKeyMap) list.Model {
+	favoriteList := list.New([]list.Item{}, ItemDelegate{}, 0, 0)
+	favoriteList.SetShowTitle(false)
+	favoriteList.SetShowStatusBar(false)
+	favoriteList.SetFilteringEnabled(true)
+	setListStyle(&favoriteList)
+
+	favoriteList.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listkeys.Choose,
+			listkeys.Favorite,
+			listkeys.Refresh,
+			listkeys.Back,
+		}
+	}
+	favoriteList.AdditionalShortHelpKeys = favoriteList.AdditionalFullHelpKeys
+	return favoriteList
+}
+
 func newPaginator() paginator.Model {
 	pager := paginator.New()
 	pager.Type = paginator.Dots
@@ -300,22 +524,16 @@ func newPaginator() paginator.Model {
 	return pager
 }
 
-func NewModel() Model {
-	ec2Svc, ecsSvc, ecrSvc, cloudwatchlogsSvc, sfnSvc, batchSvc := newAWSClients()
+// NewModel creates the root model. Every AWS call is made through the clients
+// handed out by pool, every change is recorded in log, the API call
+// inspector lists the requests recorded by calls and starred resources are
+// kept in favs.
+func NewModel(pool *clients.Pool, conf *config.Config, log *audit.Log, calls *apilog.Recorder, favs *favorites.Store) Model {
 	s := newSpinner()
 	listkeys := keys.NewListKeyMap()
-	mainList := newMainMenu(listkeys)
-	ec2List := newEC2List(listkeys)
-	ecsClusterList := newECSClusterList(listkeys)
-	ecsServiceList := newECSServiceList(listkeys)
-	ecrRepositoryList := newECRRepositoryList(listkeys)
-	ecrImageList := newECRImageList(listkeys)
-	sfnList := newSFNList(listkeys)
-	sfnExecutionList := newSFNExecutionList(listkeys)
-	sfnExecutionHistoryList := newSFNExecutionHistoryList(listkeys)
-	batchJobQueueList := newBatchJobQueueList(listkeys)
-	batchJobList := newBatchJobList(listkeys)
-	pager := newPaginator()
+	remapErr := listkeys.Remap(conf.KeyBindings())
+	mainList := newMainMenu(listkeys, conf.Views)
+	nav := &navigation{}
 
 	m := Model{
 		status:      "Select an option.",
@@ -325,166 +543,675 @@ func NewModel() Model {
 		menuCursor:  0,
 		spinner:     s,
 		statusStyle: styles.StatusStyle,
+		profileList: newProfileList(listkeys, clients.DisplayProfile(pool.Profile)),
+		regionList:  newRegionList(listkeys, nil, nil, ""),
+		palette:     newPalette(),
+		favs:        favs,
+		nav:         nav,
+		auditModel: auditModel{
+			log:       log,
+			entryList: newAuditList(listkeys),
+			keys:      listkeys,
+			status:    "Loading audit log...",
+			nav:       nav,
+		},
+		apiModel: apiModel{
+			calls:    calls,
+			callList: newAPICallList(listkeys),
+			keys:     listkeys,
+			status:   "Loading API calls...",
+			nav:      nav,
+		},
+
+		refreshIntervals: map[appState]time.Duration{},
+		refreshEnabled:   map[appState]bool{},
+	}
+	for view, state := range views {
+		m.refreshIntervals[state] = conf.RefreshInterval(view)
+		m.refreshEnabled[state] = m.refreshIntervals[state] > 0
 	}
+	m.scope = newScope()
+	m.notes = &notifier{}
+	m.ops = &operations{}
+	m.guard = newGuard(conf, listkeys, log)
+	m.guard.setIdentity(clients.DisplayProfile(pool.Profile), "", "")
+	m.resetSubModels(pool, nil)
+	m.err = remapErr
+
+	return m
+}
+
+// resetSubModels rebuilds every service model around the given clients and
+// regions, discarding any resources loaded under the previous identity.
+//
+// The key map, guard, scope, notifier and navigation stack are held by the
+// root model and handed to every view as pointers, so they outlive the
+// rebuild and the root sees what any view changes: the screens it opens,
+// the notices it raises and the requests it starts.
+func (m *Model) resetSubModels(pool *clients.Pool, regions []string) {
+	listkeys := m.keys
+	pager := newPaginator()
+	ec2Marks, serviceMarks, imageMarks, jobMarks := newSelection(), newSelection(), newSelection(), newSelection()
+	starred := &stars{store: m.favs, profile: clients.DisplayProfile(pool.Profile)}
+	ec2Table := newTable("ec2", m.guard.conf, ec2Marks, starred)
+	serviceTable := newTable("ecs", m.guard.conf, serviceMarks, starred)
+	jobTable := newTable("batch", m.guard.conf, jobMarks, nil)
+	m.pool = pool
+	m.regions = regions
 
 	m.ec2Model = ec2Model{
-		parent:       &m,
+		parent:       m,
 		status:       "Loading instances...",
-		ec2Svc:       ec2Svc,
-		instanceList: ec2List,
+		pool:         pool,
+		regions:      regions,
+		instanceList: newEC2List(listkeys, ec2Table),
+		marks:        ec2Marks,
+		table:        ec2Table,
+		loader:       newListLoader("instances", regionChains(regions)),
 		keys:         listkeys,
+		guard:        m.guard,
+		scope:        m.scope,
+		notes:        m.notes,
+		nav:          m.nav,
 	}
 
 	m.ecsModel = ecsModel{
-		parent:            &m,
-		ecsSvc:            ecsSvc,
-		status:            "Loading clusters...",
-		cloudwatchlogsSvc: cloudwatchlogsSvc,
-		clusterList:       ecsClusterList,
-		serviceList:       ecsServiceList,
-		paginator:         pager,
-		keys:              listkeys,
-		state:             ecsStateClusterList,
+		parent:        m,
+		pool:          pool,
+		regions:       regions,
+		status:        "Loading clusters...",
+		clusterList:   newECSClusterList(listkeys),
+		serviceList:   newECSServiceList(listkeys, serviceTable),
+		serviceMarks:  serviceMarks,
+		serviceTable:  serviceTable,
+		clusterLoader: newListLoader("clusters", regionChains(regions)),
+		serviceLoader: newListLoader("services", 1),
+		paginator:     pager,
+		keys:          listkeys,
+		guard:         m.guard,
+		scope:         m.scope,
+		notes:         m.notes,
+		nav:           m.nav,
+		state:         ecsStateClusterList,
 	}
 
 	m.ecrModel = ecrModel{
-		parent:         &m,
-		ecrSvc:         ecrSvc,
-		status:         "Loading repositories...",
-		repositoryList: ecrRepositoryList,
-		imageList:      ecrImageList,
-		keys:           listkeys,
-		state:          ecrStateRepositoryList,
+		parent:           m,
+		pool:             pool,
+		regions:          regions,
+		status:           "Loading repositories...",
+		repositoryList:   newECRRepositoryList(listkeys, starred),
+		imageList:        newECRImageList(listkeys, imageMarks),
+		imageMarks:       imageMarks,
+		repositoryLoader: newListLoader("repositories", regionChains(regions)),
+		imageLoader:      newListLoader("images", 1),
+		keys:             listkeys,
+		guard:            m.guard,
+		scope:            m.scope,
+		notes:            m.notes,
+		nav:              m.nav,
+		state:            ecrStateRepositoryList,
 	}
 
 	m.sfnModel = sfnModel{
-		parent:               &m,
-		sfnSvc:               sfnSvc,
+		parent:               m,
+		pool:                 pool,
+		regions:              regions,
 		status:               "Loading state machines...",
-		sfnList:              sfnList,
-		executionList:        sfnExecutionList,
-		executionHistoryList: sfnExecutionHistoryList,
+		sfnList:              newSFNList(listkeys, starred),
+		executionList:        newSFNExecutionList(listkeys),
+		executionHistoryList: newSFNExecutionHistoryList(listkeys),
+		stateMachineLoader:   newListLoader("state machines", regionChains(regions)),
+		executionLoader:      newListLoader("executions", 1),
 		keys:                 listkeys,
+		guard:                m.guard,
+		scope:                m.scope,
+		notes:                m.notes,
+		nav:                  m.nav,
 		state:                sfnStateList,
 		inputArea:            textarea.New(),
 	}
 
 	m.batchModel = batchModel{
-		parent:            &m,
-		batchSvc:          batchSvc,
-		status:            "Loading job queues...",
-		cloudwatchlogsSvc: cloudwatchlogsSvc,
-		jobQueueList:      batchJobQueueList,
-		jobList:           batchJobList,
-		paginator:         pager,
-		keys:              listkeys,
-		state:             batchStateJobQueueList,
+		parent:         m,
+		pool:           pool,
+		regions:        regions,
+		status:         "Loading job queues...",
+		jobQueueList:   newBatchJobQueueList(listkeys, starred),
+		jobList:        newBatchJobList(listkeys, jobTable),
+		jobMarks:       jobMarks,
+		jobTable:       jobTable,
+		jobQueueLoader: newListLoader("job queues", regionChains(regions)),
+		jobLoader:      newListLoader("jobs", len(commands.BatchJobStatuses)),
+		paginator:      pager,
+		keys:           listkeys,
+		guard:          m.guard,
+		scope:          m.scope,
+		notes:          m.notes,
+		nav:            m.nav,
+		state:          batchStateJobQueueList,
 	}
 
-	return m
+	m.favoritesModel = favoritesModel{
+		store:        m.favs,
+		pool:         pool,
+		scope:        m.scope,
+		favoriteList: newFavoriteList(listkeys),
+		keys:         listkeys,
+		status:       "Loading favorites...",
+	}
+
+	m.resizeSubModels()
+}
+
+// resizeSubModels propagates the current window size to every sub-model.
+func (m *Model) resizeSubModels() {
+	msg := tea.WindowSizeMsg{Width: m.width, Height: m.height - 3}
+
+	m.menuChoices.SetSize(msg.Width, msg.Height)
+	m.profileList.SetSize(msg.Width, msg.Height)
+	m.regionList.SetSize(msg.Width, msg.Height)
+
+	m.ec2Model, _ = m.ec2Model.Update(msg)
+	m.ecsModel, _ = m.ecsModel.Update(msg)
+	m.ecrModel, _ = m.ecrModel.Update(msg)
+	m.sfnModel, _ = m.sfnModel.Update(msg)
+	m.batchModel, _ = m.batchModel.Update(msg)
+	m.auditModel, _ = m.auditModel.Update(msg)
+	m.apiModel, _ = m.apiModel.Update(msg)
+	m.favoritesModel, _ = m.favoritesModel.Update(msg)
+}
+
+// reload returns to the view the user was in before opening a picker and
+// starts loading it again from its first screen.
+func (m Model) reload() (Model, tea.Cmd) {
+	m.nav.reset()
+	switch m.prevState {
+	case stateEC2, stateECS, stateECR, stateSFN, stateBatch:
+		return m.jump(m.prevState, nil)
+	case stateFavorites:
+		return m.openFavorites()
+	case stateAudit:
+		return m.openAudit()
+	case stateAPI:
+		return m.openAPICalls()
+	}
+	m.state = m.prevState
+	return m, nil
+}
+
+// refreshInterval returns the auto-refresh interval of the current view, or
+// zero if auto-refresh is off.
+func (m Model) refreshInterval() time.Duration {
+	if !m.refreshEnabled[m.state] {
+		return 0
+	}
+	if d := m.refreshIntervals[m.state]; d > 0 {
+		return d
+	}
+	return defaultRefreshInterval
+}
+
+// scheduleRefresh restarts the auto-refresh timer for the current view,
+// cancelling any earlier one.
+func (m *Model) scheduleRefresh() tea.Cmd {
+	m.refreshGen++
+	d := m.refreshInterval()
+	if d == 0 {
+		return nil
+	}
+	msg := autoRefreshMsg{state: m.state, gen: m.refreshGen}
+	return tea.Tick(d, func(time.Time) tea.Msg { return msg })
+}
+
+// autoRefresh refreshes the current view in the background and schedules
+// the next refresh. Views the user is typing into are skipped this time.
+func (m Model) autoRefresh() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if !m.inputActive() {
+		switch m.state {
+		case stateEC2:
+			m.ec2Model, cmd = m.ec2Model.autoRefresh()
+		case stateECS:
+			m.ecsModel, cmd = m.ecsModel.autoRefresh()
+		case stateECR:
+			m.ecrModel, cmd = m.ecrModel.autoRefresh()
+		case stateSFN:
+			m.sfnModel, cmd = m.sfnModel.autoRefresh()
+		case stateBatch:
+			m.batchModel, cmd = m.batchModel.autoRefresh()
+		}
+	}
+	return m, tea.Batch(cmd, m.scheduleRefresh())
+}
+
+// leave cancels the work of the screen the user is navigating away from.
+func (m *Model) leave() {
+	m.scope.leave()
+	for _, ld := range []*listLoader{
+		&m.ec2Model.loader,
+		&m.ecsModel.clusterLoader,
+		&m.ecsModel.serviceLoader,
+		&m.ecrModel.repositoryLoader,
+		&m.ecrModel.imageLoader,
+		&m.sfnModel.stateMachineLoader,
+		&m.sfnModel.executionLoader,
+		&m.batchModel.jobQueueLoader,
+		&m.batchModel.jobLoader,
+	} {
+		ld.cancel()
+	}
+}
+
+// resume reloads the list the user went back to if leaving the screen above
+// it interrupted its load.
+func (m Model) resume() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.state {
+	case stateEC2:
+		m.ec2Model, cmd = m.ec2Model.resume()
+	case stateECS:
+		m.ecsModel, cmd = m.ecsModel.resume()
+	case stateECR:
+		m.ecrModel, cmd = m.ecrModel.resume()
+	case stateSFN:
+		m.sfnModel, cmd = m.sfnModel.resume()
+	case stateBatch:
+		m.batchModel, cmd = m.batchModel.resume()
+	}
+	return m, cmd
+}
+
+// openAudit shows the audit log, reloading it to include the latest actions.
+func (m Model) openAudit() (Model, tea.Cmd) {
+	m.state = stateAudit
+	m.nav.push(stateAudit, screenList, viewTitles[stateAudit])
+	m.err = nil
+	m.auditModel.detail = nil
+	m.auditModel.status = "Loading audit log..."
+	return m, m.auditModel.Init()
+}
+
+// openAPICalls shows the API call inspector with the requests made so far.
+func (m Model) openAPICalls() (Model, tea.Cmd) {
+	m.state = stateAPI
+	m.nav.push(stateAPI, screenList, viewTitles[stateAPI])
+	m.err = nil
+	m.apiModel.detail = nil
+	m.apiModel.status = "Loading API calls..."
+	return m, m.apiModel.Init()
+}
+
+// openProfilePicker shows the profile picker.
+func (m Model) openProfilePicker() (Model, tea.Cmd) {
+	if m.state != stateProfile && m.state != stateRegion {
+		m.prevState = m.state
+	}
+	m.state = stateProfile
+	m.profileList = newProfileList(m.keys, clients.DisplayProfile(m.pool.Profile))
+	m.profileList.SetSize(m.width, m.height-3)
+	return m, nil
+}
+
+// openRegionPicker shows the region picker, fetching the regions enabled for
+// the account the first time.
+func (m Model) openRegionPicker() (Model, tea.Cmd) {
+	if m.state != stateProfile && m.state != stateRegion {
+		m.prevState = m.state
+	}
+	m.state = stateRegion
+	m.regionList = newRegionList(m.keys, m.enabledRegions, m.regions, m.pool.DefaultRegion)
+	m.regionList.SetSize(m.width, m.height-3)
+	if m.enabledRegions == nil {
+		return m, commands.FetchRegionsCmd(context.Background(), m.pool.Region("").EC2)
+	}
+	return m, nil
+}
+
+// switchProfile rebuilds the AWS clients for profile and reloads the service
+// the user was looking at under the new identity.
+func (m Model) switchProfile(profile string) (Model, tea.Cmd) {
+	pool, err := m.pool.WithProfile(profile)
+	if err != nil {
+		m.state = m.prevState
+		m.err = err
+		return m, nil
+	}
+	m.leave()
+	m.enabledRegions = nil
+	m.guard.setIdentity(clients.DisplayProfile(profile), "", "")
+	m.resetSubModels(pool, nil)
+	m.err = nil
+	m.status = fmt.Sprintf("Switched to profile %s.", clients.DisplayProfile(profile))
+	m, cmd := m.reload()
+	return m, tea.Batch(cmd, m.fetchIdentity())
+}
+
+// switchRegion points every service at the chosen region, or at every enabled
+// region when all is selected, and reloads the current service.
+func (m Model) switchRegion(region string) (Model, tea.Cmd) {
+	regions := []string{region}
+	if region == allRegions {
+		regions = m.everyRegion()
+	}
+	m.leave()
+	m.resetSubModels(m.pool, regions)
+	m.err = nil
+	m.status = fmt.Sprintf("Switched to %s.", m.regionLabel())
+	return m.reload()
+}
+
+// everyRegion lists the regions "all" stands for: the regions enabled for
+// the account, or those of the partition until they are known.
+func (m Model) everyRegion() []string {
+	if len(m.enabledRegions) == 0 {
+		return partitionRegions()
+	}
+	return m.enabledRegions
+}
+
+// querying reports whether the regions being queried are region, which may
+// be "all", and nothing else.
+func (m Model) querying(region string) bool {
+	switch {
+	case region == allRegions:
+		return len(m.regions) > 1 && slices.Equal(m.regions, m.everyRegion())
+	case len(m.regions) == 0:
+		return region == m.pool.DefaultRegion
+	}
+	return len(m.regions) == 1 && m.regions[0] == region
+}
+
+// regionLabel describes the regions currently being queried.
+func (m Model) regionLabel() string {
+	switch len(m.regions) {
+	case 0:
+		return m.pool.DefaultRegion
+	case 1:
+		return m.regions[0]
+	}
+	return fmt.Sprintf("all regions (%d)", len(m.regions))
+}
+
+// inputActive reports whether the user is currently typing into a filter or
+// text input, in which case global key bindings must not fire.
+func (m Model) inputActive() bool {
+	if m.palette.Focused() || m.drawer.open || m.tray.open || m.filtering() {
+		return true
+	}
+	return m.state == stateSFN && m.sfnModel.state == sfnStateStartExecution || m.confirming()
+}
+
+// editing reports whether msg edits the text typed into an input, which then
+// takes it even if it is bound to an action, as backspace is to going back.
+func editing(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace || msg.Type == tea.KeyBackspace
+}
+
+// filtering reports whether the user is typing a filter into one of the
+// lists, which takes the keys that would otherwise navigate.
+func (m Model) filtering() bool {
+	lists := []list.Model{
+		m.menuChoices,
+		m.profileList,
+		m.regionList,
+		m.ec2Model.instanceList,
+		m.ecsModel.clusterList,
+		m.ecsModel.serviceList,
+		m.ecrModel.repositoryList,
+		m.ecrModel.imageList,
+		m.sfnModel.sfnList,
+		m.sfnModel.executionList,
+		m.sfnModel.executionHistoryList,
+		m.batchModel.jobQueueList,
+		m.batchModel.jobList,
+		m.auditModel.entryList,
+		m.apiModel.callList,
+		m.favoritesModel.favoriteList,
+	}
+	for _, l := range lists {
+		if l.FilterState() == list.Filtering {
+			return true
+		}
+	}
+	return false
+}
+
+// dialog returns the confirmation dialog of the current view, or the login
+// prompt, which is shown over any view.
+func (m Model) dialog() confirmDialog {
+	if m.login.visible {
+		return m.login
+	}
+	switch m.state {
+	case stateEC2:
+		return m.ec2Model.confirm
+	case stateECS:
+		return m.ecsModel.confirm
+	case stateECR:
+		return m.ecrModel.confirm
+	case stateSFN:
+		return m.sfnModel.confirm
+	case stateBatch:
+		return m.batchModel.confirm
+	}
+	return confirmDialog{}
+}
+
+// confirming reports whether the current view is waiting for an action to be
+// confirmed, in which case every key goes to it.
+func (m Model) confirming() bool {
+	return m.dialog().visible
 }
 
 // Init initializes the model and starts fetching data based on the initial state.
 func (m Model) Init() tea.Cmd {
-	return m.spinner.Tick
+	return tea.Batch(m.spinner.Tick, m.fetchIdentity())
 }
 
-// Update handles incoming messages and updates the model's state.
+// fetchIdentity looks up the account of the active profile, which account
+// policies depend on.
+func (m Model) fetchIdentity() tea.Cmd {
+	return commands.FetchCallerIdentityCmd(context.Background(), m.pool.Region("").STS, m.pool.Profile)
+}
+
+// Update handles incoming messages and updates the model's state. Results
+// of work started on a screen the user has since left are dropped; see
+// scope. Toasts are timed here, since any view may raise one.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(scopedMsg); ok {
+		if !m.scope.current(msg) {
+			return m, nil
+		}
+		return m.Update(msg.msg)
+	}
+	m, cmd := m.update(msg)
+	return m, tea.Batch(m.scope.tag(cmd), m.notes.schedule())
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		h, v := styles.AppStyle.GetFrameSize()
 		m.width = msg.Width - h
 		m.height = msg.Height - v
-		msg.Height = m.height - 3
-		msg.Width = m.width
-
-		m.menuChoices.SetSize(msg.Width, msg.Height)
-
-		m.ec2Model, cmd = m.ec2Model.Update(msg)
-		m.ecsModel, cmd = m.ecsModel.Update(msg)
-		m.ecrModel, cmd = m.ecrModel.Update(msg)
-		m.sfnModel, cmd = m.sfnModel.Update(msg)
-		m.batchModel, cmd = m.batchModel.Update(msg)
+		m.resizeSubModels()
+		if m.drawer.open {
+			m.drawer = m.drawer.show(m.notes, m.width, m.height-3)
+		}
 	case tea.KeyMsg:
+		if m.login.visible {
+			var result confirmResult
+			m.login, result = m.login.update(msg, m.keys)
+			if result == confirmAccepted {
+				return m, m.login.accept
+			}
+			return m, nil
+		}
+		if m.drawer.open {
+			m.drawer = m.drawer.update(msg, m.keys)
+			return m, nil
+		}
+		if m.tray.open {
+			m.tray = m.tray.update(msg, m.keys, m.ops)
+			return m, nil
+		}
+		if m.palette.Focused() {
+			switch {
+			case key.Matches(msg, m.keys.Choose):
+				line := m.palette.Value()
+				return m.closePalette().runCommand(line)
+			case key.Matches(msg, m.keys.Back) && !editing(msg):
+				return m.closePalette(), nil
+			}
+			m.palette, cmd = m.palette.Update(msg)
+			return m, cmd
+		}
+		if !m.inputActive() && key.Matches(msg, m.keys.Command) {
+			return m.openPalette()
+		}
+		if !m.inputActive() && key.Matches(msg, m.keys.Notifications) {
+			m.drawer = m.drawer.show(m.notes, m.width, m.height-3)
+			return m, nil
+		}
+		if !m.inputActive() && key.Matches(msg, m.keys.Operations) {
+			m.tray = tray{open: true}
+			return m, nil
+		}
+		if !m.inputActive() && m.state != stateProfile && key.Matches(msg, m.keys.Profile) {
+			return m.openProfilePicker()
+		}
+		if !m.inputActive() && m.state != stateRegion && key.Matches(msg, m.keys.Region) {
+			return m.openRegionPicker()
+		}
+		if !m.inputActive() && key.Matches(msg, m.keys.Favorite) {
+			return m.star()
+		}
+		if _, ok := m.refreshIntervals[m.state]; ok && !m.inputActive() && key.Matches(msg, m.keys.AutoRefresh) {
+			m.refreshEnabled[m.state] = !m.refreshEnabled[m.state]
+			return m, m.scheduleRefresh()
+		}
+		if f, ok := m.nav.top(); ok && f.state == m.state && key.Matches(msg, m.keys.Back) && !m.filtering() && !m.confirming() && !(m.inputActive() && editing(msg)) {
+			return m.back()
+		}
 		switch m.state {
+		case stateProfile:
+			if m.profileList.FilterState() == list.Filtering {
+				break
+			}
+			switch {
+			case key.Matches(msg, m.keys.Choose):
+				if m.profileList.SelectedItem() != nil {
+					return m.switchProfile(m.profileList.SelectedItem().FilterValue())
+				}
+			case key.Matches(msg, m.keys.Back):
+				m.state = m.prevState
+				return m, m.scheduleRefresh()
+			}
+		case stateRegion:
+			if m.regionList.FilterState() == list.Filtering {
+				break
+			}
+			switch {
+			case key.Matches(msg, m.keys.Choose):
+				if m.regionList.SelectedItem() != nil {
+					return m.switchRegion(m.regionList.SelectedItem().FilterValue())
+				}
+			case key.Matches(msg, m.keys.Back):
+				m.state = m.prevState
+				return m, m.scheduleRefresh()
+			}
 		case stateMenu:
 			switch {
 			case key.Matches(msg, m.keys.Choose):
+				m.leave()
+				m.nav.reset()
+				if v, ok := m.menuChoices.SelectedItem().(viewItem); ok {
+					return m.openView(v.view)
+				}
 				selectedChoice := m.menuChoices.SelectedItem().FilterValue()
 				switch selectedChoice {
 				case "EC2":
-					m.state = stateEC2
-					return m, m.ec2Model.Init()
+					return m.jump(stateEC2, nil)
 				case "ECS":
-					m.state = stateECS
-					return m, m.ecsModel.Init()
+					return m.jump(stateECS, nil)
 				case "ECR":
-					m.state = stateECR
-					return m, m.ecrModel.Init()
+					return m.jump(stateECR, nil)
 				case "Step Functions":
-					m.state = stateSFN
-					return m, m.sfnModel.Init()
+					return m.jump(stateSFN, nil)
 				case "Batch":
-					m.state = stateBatch
-					return m, m.batchModel.Init()
+					return m.jump(stateBatch, nil)
+				case "Favorites":
+					return m.openFavorites()
+				case "Audit Log":
+					return m.openAudit()
+				case "API Calls":
+					return m.openAPICalls()
 				}
 			}
 			m.menuChoices, cmd = m.menuChoices.Update(msg)
 
 			return m, cmd
-		case stateEC2, stateECS, stateECR, stateSFN, stateBatch:
-			if m.ec2Model.instanceList.FilterState() == list.Filtering || m.ecsModel.serviceList.FilterState() == list.Filtering || m.ecsModel.clusterList.FilterState() == list.Filtering || m.ecrModel.repositoryList.FilterState() == list.Filtering || m.ecrModel.imageList.FilterState() == list.Filtering {
+		case stateFavorites:
+			if m.favoritesModel.favoriteList.FilterState() == list.Filtering {
 				break
 			}
-			if key.Matches(msg, key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back"))) {
-				if m.state == stateEC2 {
-					if m.ec2Model.showDetails {
-						m.ec2Model, cmd = m.ec2Model.Update(msg)
-						return m, cmd
-					}
-				}
-				if m.state == stateECS {
-					if m.ecsModel.state != ecsStateClusterList {
-						m.ecsModel, cmd = m.ecsModel.Update(msg)
-						return m, cmd
-					}
-				}
-				if m.state == stateECR {
-					if m.ecrModel.state != ecrStateRepositoryList {
-						m.ecrModel, cmd = m.ecrModel.Update(msg)
-						return m, cmd
-					}
-				}
-				if m.state == stateBatch {
-					if m.batchModel.state != batchStateJobQueueList {
-						m.batchModel, cmd = m.batchModel.Update(msg)
-						return m, cmd
-					}
-				}
-				if m.state == stateSFN {
-					if m.sfnModel.state != sfnStateList {
-						m.sfnModel, cmd = m.sfnModel.Update(msg)
-						return m, cmd
-					}
+			switch {
+			case key.Matches(msg, m.keys.Choose):
+				if item, ok := m.favoritesModel.favoriteList.SelectedItem().(favoriteItem); ok {
+					return m.openFavorite(item.favorite)
 				}
-				m.state = stateMenu
-				m.status = "Select an option."
-				m.err = nil
 				return m, nil
 			}
 		}
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
-	case messages.ErrMsg:
-		m.err = msg
-		m.status = "Error"
+	case toastMsg:
+		m.notes.expire()
+		return m, nil
+	case startOpMsg:
+		parent := context.Background()
+		if msg.foreground {
+			parent = m.scope.context()
+		}
+		return m, m.ops.start(parent, msg)
+	case opMsg:
+		return m.operationUpdate(msg)
+	case autoRefreshMsg:
+		if msg.gen != m.refreshGen || msg.state != m.state {
+			return m, nil
+		}
+		return m.autoRefresh()
+	case messages.RegionsFetchedMsg:
+		m.enabledRegions = msg
+		if m.state == stateRegion {
+			m.regionList.SetItems(newRegionList(m.keys, m.enabledRegions, m.regions, m.pool.DefaultRegion).Items())
+		}
+		return m, nil
+	case messages.CallerIdentityMsg:
+		// Drop the answer for a profile the user has since switched away from.
+		if msg.Profile == m.pool.Profile {
+			m.guard.setIdentity(clients.DisplayProfile(msg.Profile), msg.Account, msg.Arn)
+		}
 		return m, nil
+	case messages.LoginDoneMsg:
+		return m.loggedIn(msg)
+	case followMsg:
+		return m.follow(msg.link)
+	case messages.ErrMsg:
+		slog.Debug("error", "err", msg)
+		return m.handleError(msg), nil
 	}
 
-	switch m.state {
+	return m.dispatch(m.state, msg)
+}
+
+// dispatch hands msg to the model of the view in state.
+func (m Model) dispatch(state appState, msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch state {
 	case stateEC2:
 		m.ec2Model, cmd = m.ec2Model.Update(msg)
 	case stateECS:
@@ -497,10 +1224,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.batchModel, cmd = m.batchModel.Update(msg)
 	case stateMenu:
 		m.menuChoices, cmd = m.menuChoices.Update(msg)
+	case stateProfile:
+		m.profileList, cmd = m.profileList.Update(msg)
+	case stateRegion:
+		m.regionList, cmd = m.regionList.Update(msg)
+	case stateAudit:
+		m.auditModel, cmd = m.auditModel.Update(msg)
+	case stateAPI:
+		m.apiModel, cmd = m.apiModel.Update(msg)
+	case stateFavorites:
+		m.favoritesModel, cmd = m.favoritesModel.Update(msg)
 	}
 
 	return m, cmd
 }
+
+// busy reports whether a sub-model status describes work in progress, in
+// which case it is shown next to the spinner.
+func busy(status string) bool {
+	return status != "Ready" && !strings.HasPrefix(status, "Error") && !strings.HasPrefix(status, "Blocked")
+}
+
 func (m Model) Header(items []string) string {
 	ret := styles.HeaderStyle.Render(" 󰸏  AWS TUI ")
 	for i, h := range items {
@@ -512,9 +1256,30 @@ func (m Model) Header(items []string) string {
 		}
 		ret += styles.SubHeaderStyle.Render(h)
 	}
-	remainingWidth := m.width - lipgloss.Width(ret)
-	padding := styles.HeaderBarStyle.Width(remainingWidth).Render("") + "\n\n"
-	return ret + padding
+	identity := clients.DisplayProfile(m.pool.Profile)
+	if m.guard.account != "" {
+		identity += " (" + m.guard.account + ")"
+	}
+	profile := styles.ProfileStyle.Render(" " + identity + " | " + m.regionLabel() + " ")
+	remainingWidth := m.width - lipgloss.Width(ret) - lipgloss.Width(profile)
+	padding := styles.HeaderBarStyle.Width(remainingWidth).Render("")
+	return ret + padding + profile + "\n\n"
+}
+
+// body is what the current view shows under the header: its own view, or the
+// confirmation dialog it has open, the login prompt, the notifications
+// drawer or the operations tray.
+func (m Model) body(view string) string {
+	if d := m.dialog(); d.visible {
+		return d.View(m.keys, m.width, m.height-3)
+	}
+	if m.drawer.open {
+		return m.drawer.View(m.notes)
+	}
+	if m.tray.open {
+		return m.tray.View(m.ops, m.width, m.height-3)
+	}
+	return view
 }
 
 // View renders the TUI.
@@ -522,75 +1287,117 @@ func (m Model) View() string {
 	var s strings.Builder
 
 	if m.err != nil {
-		s.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)) + "\n")
+		s.WriteString(errorView(m.err, m.width) + "\n")
 	}
 	var status, spinner string
 	switch m.state {
 	case stateMenu:
 		s.WriteString(m.Header(nil))
-		s.WriteString(m.menuChoices.View())
+		s.WriteString(m.body(m.menuChoices.View()))
 		status = "Status: Ready"
+	case stateProfile:
+		s.WriteString(m.Header([]string{"Profiles"}))
+		s.WriteString(m.body(m.profileList.View()))
+		status = "Select a profile."
+	case stateRegion:
+		s.WriteString(m.Header([]string{"Regions"}))
+		s.WriteString(m.body(m.regionList.View()))
+		status = "Select a region."
 	case stateEC2:
-		s.WriteString(m.Header(m.ec2Model.Header))
-		s.WriteString(m.ec2Model.View())
-		if m.ec2Model.status != "Ready" && m.ec2Model.status != "Error" {
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.ec2Model.View()))
+		if busy(m.ec2Model.status) {
 			status = m.ec2Model.status
 			spinner = m.spinner.View()
-		} else if m.ec2Model.confirming {
-			status = m.ec2Model.status
 		} else {
 			status = fmt.Sprintf("Status: %s", m.ec2Model.status)
 		}
 
 	case stateECS:
-		s.WriteString(m.Header(m.ecsModel.header))
-		s.WriteString(m.ecsModel.View())
-		if m.ecsModel.status != "Ready" && m.ecsModel.status != "Error" {
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.ecsModel.View()))
+		if busy(m.ecsModel.status) {
 			status = m.ecsModel.status
 			spinner = m.spinner.View()
-		} else if m.ec2Model.confirming {
-			status = fmt.Sprintf("%s", m.ecsModel.status)
 		} else {
 			status = fmt.Sprintf("Status: %s", m.ecsModel.status)
 		}
 	case stateECR:
-		s.WriteString(m.Header(m.ecrModel.header))
-		s.WriteString(m.ecrModel.View())
-		if m.ecrModel.status != "Ready" && m.ecrModel.status != "Error" {
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.ecrModel.View()))
+		if busy(m.ecrModel.status) {
 			status = m.ecrModel.status
 			spinner = m.spinner.View()
 		} else {
 			status = fmt.Sprintf("Status: %s", m.ecrModel.status)
 		}
 	case stateSFN:
-		s.WriteString(m.Header(m.sfnModel.header))
-		s.WriteString(m.sfnModel.View())
-		if m.sfnModel.status != "Ready" && m.sfnModel.status != "Error" {
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.sfnModel.View()))
+		if busy(m.sfnModel.status) {
 			status = m.sfnModel.status
 			spinner = m.spinner.View()
 		} else {
 			status = fmt.Sprintf("Status: %s", m.sfnModel.status)
 		}
 	case stateBatch:
-		s.WriteString(m.Header(m.batchModel.header))
-		s.WriteString(m.batchModel.View())
-		if m.batchModel.status != "Ready" && m.batchModel.status != "Error" {
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.batchModel.View()))
+		if busy(m.batchModel.status) {
 			status = m.batchModel.status
 			spinner = m.spinner.View()
 		} else {
 			status = fmt.Sprintf("Status: %s", m.batchModel.status)
 		}
+	case stateAudit:
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.auditModel.View()))
+		status = fmt.Sprintf("Status: %s", m.auditModel.status)
+	case stateAPI:
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.apiModel.View()))
+		status = fmt.Sprintf("Status: %s", m.apiModel.status)
+	case stateFavorites:
+		s.WriteString(m.Header(m.nav.header()))
+		s.WriteString(m.body(m.favoritesModel.View()))
+		if busy(m.favoritesModel.status) {
+			status = m.favoritesModel.status
+			spinner = m.spinner.View()
+		} else {
+			status = fmt.Sprintf("Status: %s", m.favoritesModel.status)
+		}
+	}
+
+	if d := m.dialog(); d.visible {
+		status, spinner = d.hint(m.keys), ""
+	} else if m.drawer.open {
+		status, spinner = m.drawer.hint(m.keys), ""
+	} else if m.tray.open {
+		status, spinner = m.tray.hint(m.keys), ""
+	}
+	if n := m.marked(); n > 0 {
+		status += fmt.Sprintf(" | Marked: %d", n)
+	}
+	if n := m.ops.running(); n > 0 {
+		status += fmt.Sprintf(" | Operations: %d running", n)
+	}
+	if d := m.refreshInterval(); d > 0 {
+		status += fmt.Sprintf(" | Auto-refresh: %s", d)
 	}
 
 	st := m.statusStyle.Render(spinner) + m.statusStyle.Render(status)
+	if m.palette.Focused() {
+		st = m.statusStyle.Render(m.paletteView())
+	}
 
-	remainingWidth := m.width - lipgloss.Width(st)
+	toast := m.notes.toast(m.width - lipgloss.Width(st))
+	remainingWidth := m.width - lipgloss.Width(st) - lipgloss.Width(toast)
 	remainingHeight := m.height - lipgloss.Height(s.String())
 	padding := m.statusStyle.Width(remainingWidth).Render("")
 
 	s.WriteString(lipgloss.NewStyle().Height(remainingHeight).Render(""))
 
-	s.WriteString("\n" + st + padding)
+	s.WriteString("\n" + st + padding + toast)
 
 	return styles.AppStyle.Render(s.String())
 }
@@ -0,0 +1,328 @@
+package models
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/commands"
+	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/fake"
+	"github.com/theoreticallyjosh/awstui/internal/favorites"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// settle is how long a command may take before it is taken for a timer,
+// such as a toast or auto-refresh tick, and dropped.
+const settle = 200 * time.Millisecond
+
+func init() {
+	commands.WaitInterval = time.Millisecond
+}
+
+// testModel drives a Model on the demo backend the way Bubble Tea does,
+// running the commands each update returns until they settle.
+type testModel struct {
+	t       *testing.T
+	backend *fake.Backend
+	log     *audit.Log
+	m       Model
+}
+
+func newTestModel(t *testing.T, conf *config.Config) *testModel {
+	t.Helper()
+	if conf == nil {
+		conf = &config.Config{}
+	}
+	b := fake.NewDemoBackend()
+	tm := &testModel{t: t, backend: b, log: audit.Open("")}
+	tm.m = NewModel(b.Pool("demo", "us-east-1"), conf, tm.log, apilog.NewRecorder(10), favorites.Open(""))
+	tm.send(tea.WindowSizeMsg{Width: 160, Height: 40})
+	tm.run(tm.m.Init())
+	return tm
+}
+
+// send updates the model with msg and runs the commands it returns.
+func (tm *testModel) send(msg tea.Msg) {
+	tm.t.Helper()
+	next, cmd := tm.m.Update(msg)
+	tm.m = next.(Model)
+	tm.run(cmd)
+}
+
+// run runs cmd and feeds its messages back into the model, one at a time.
+func (tm *testModel) run(cmd tea.Cmd) {
+	tm.t.Helper()
+	if cmd == nil {
+		return
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(settle):
+		return
+	}
+	switch msg := msg.(type) {
+	case nil, spinner.TickMsg:
+		return
+	case tea.BatchMsg:
+		for _, c := range msg {
+			tm.run(c)
+		}
+		return
+	}
+	// tea.Sequence returns an unexported slice of commands.
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeOf(tea.Cmd(nil)) {
+		for i := 0; i < v.Len(); i++ {
+			tm.run(v.Index(i).Interface().(tea.Cmd))
+		}
+		return
+	}
+	tm.send(msg)
+}
+
+// press sends each key in turn, named as in key bindings ("enter", "esc",
+// "ctrl+a") or typed as is.
+func (tm *testModel) press(keys ...string) {
+	tm.t.Helper()
+	for _, k := range keys {
+		tm.send(keyMsg(k))
+	}
+}
+
+// command runs a line of the command palette.
+func (tm *testModel) command(line string) {
+	tm.t.Helper()
+	tm.press(":")
+	for _, r := range line {
+		tm.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	tm.press("enter")
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "ctrl+a":
+		return tea.KeyMsg{Type: tea.KeyCtrlA}
+	case "space", " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// wantView fails unless the screen shows every one of want.
+func (tm *testModel) wantView(want ...string) {
+	tm.t.Helper()
+	view := tm.m.View()
+	for _, w := range want {
+		if !strings.Contains(view, w) {
+			tm.t.Fatalf("view does not show %q:\n%s", w, view)
+		}
+	}
+}
+
+// wantNoView fails if the screen shows any of unwanted.
+func (tm *testModel) wantNoView(unwanted ...string) {
+	tm.t.Helper()
+	view := tm.m.View()
+	for _, u := range unwanted {
+		if strings.Contains(view, u) {
+			tm.t.Fatalf("view shows %q:\n%s", u, view)
+		}
+	}
+}
+
+// wantHeader fails unless the breadcrumb is crumbs.
+func (tm *testModel) wantHeader(crumbs ...string) {
+	tm.t.Helper()
+	tm.wantView(" " + strings.Join(crumbs, " > ") + " ")
+	if got := tm.m.nav.header(); !reflect.DeepEqual(got, crumbs) {
+		tm.t.Fatalf("header = %q, want %q", got, crumbs)
+	}
+}
+
+func TestMenuOpensViews(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.wantView("EC2", "ECS", "Step Functions", "Favorites")
+
+	tm.press("enter")
+	tm.wantHeader("EC2 Instances")
+	tm.wantView("web-1", "web-2", "bastion")
+
+	tm.press("esc", "down", "enter")
+	tm.wantHeader("ECS Clusters")
+	tm.wantView("demo")
+}
+
+func TestNavigationDrillsDownAndBack(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ecs")
+	tm.wantHeader("ECS Clusters")
+
+	tm.press("enter")
+	tm.wantHeader("ECS Clusters", "demo", "Services")
+	tm.wantView("api", "worker")
+
+	tm.press("d")
+	tm.wantHeader("ECS Clusters", "demo", "Services", "api")
+	tm.wantView("Task Definition")
+
+	tm.press("esc")
+	tm.wantHeader("ECS Clusters", "demo", "Services")
+	tm.wantView("worker")
+
+	tm.press("esc")
+	tm.wantHeader("ECS Clusters")
+	if n := len(tm.m.ecsModel.serviceList.Items()); n != 0 {
+		t.Fatalf("services of the cluster left are still listed: %d", n)
+	}
+
+	tm.press("esc")
+	if tm.m.state != stateMenu {
+		t.Fatalf("state = %v, want the menu", tm.m.state)
+	}
+}
+
+func TestPaletteJumpsToResources(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ecs demo")
+	tm.wantHeader("ECS Clusters", "demo", "Services")
+	tm.wantView("api", "worker")
+	if tm.m.ecsModel.state != ecsStateServiceList {
+		t.Fatalf("ecs state = %v, want the services of the cluster", tm.m.ecsModel.state)
+	}
+
+	tm.command("ecs nosuch")
+	tm.wantView(`cluster "nosuch" not found`)
+	tm.command("halt")
+	tm.wantView(`unknown command "halt"`)
+}
+
+func TestNavigationFromStartExecutionReturnsToList(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("sfn")
+	tm.press("e")
+	tm.wantHeader("Step Functions", "nightly-etl", "Start execution")
+
+	tm.press("esc")
+	tm.wantHeader("Step Functions")
+	tm.wantView("nightly-etl", "deploy-migrations")
+	if tm.m.sfnModel.state != sfnStateList {
+		t.Fatalf("sfn state = %v, want the state machine list", tm.m.sfnModel.state)
+	}
+}
+
+func TestStartExecutionWithoutStateMachines(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.backend.Region("us-east-1").StateMachines = nil
+	tm.command("sfn")
+	tm.press("e")
+	tm.wantHeader("Step Functions")
+}
+
+func TestNavigationFollowsLinksAndBack(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("sfn deploy-migrations")
+	tm.wantHeader("Step Functions", "deploy-migrations", "Executions")
+
+	tm.press("enter")
+	tm.wantHeader("Step Functions", "deploy-migrations", "Executions", "run-1", "History")
+	for i := 0; i < 10 && tm.selectedHistoryLink() == nil; i++ {
+		tm.press("down")
+	}
+	if tm.selectedHistoryLink() == nil {
+		t.Fatal("no history event links to the task the execution started")
+	}
+
+	tm.press("L")
+	tm.wantHeader("ECS Clusters", "demo", "Tasks", "0123456789abcdef")
+	tm.wantView("STOPPED", "api:3")
+
+	tm.press("esc")
+	tm.wantHeader("Step Functions", "deploy-migrations", "Executions", "run-1", "History")
+}
+
+func (tm *testModel) selectedHistoryLink() *link {
+	item, _ := tm.m.sfnModel.executionHistoryList.SelectedItem().(sfnExecutionHistoryItem)
+	return item.link
+}
+
+func TestBackspaceGoesBack(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ecs")
+	tm.press("enter")
+	tm.press("backspace")
+	tm.wantHeader("ECS Clusters")
+}
+
+func TestInvalidKeysAreReported(t *testing.T) {
+	tm := newTestModel(t, &config.Config{Keys: map[string]config.KeyList{"back": {"esc", "h"}}})
+	tm.wantView(`back: key "h" is taken by the lists`)
+}
+
+func TestBackHintFollowsTheKeys(t *testing.T) {
+	tm := newTestModel(t, &config.Config{Keys: map[string]config.KeyList{"back": {"H"}}})
+	tm.command("ec2")
+	tm.press("d")
+	tm.wantView("Press 'H' to go back.")
+
+	tm.press("H")
+	tm.wantHeader("EC2 Instances")
+}
+
+func TestBackspaceEditsInputs(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("sfn")
+	tm.press("e", "x", "backspace")
+	tm.wantHeader("Step Functions", "nightly-etl", "Start execution")
+
+	tm.press("esc", ":", "e", "c", "2", "x", "backspace", "enter")
+	tm.wantHeader("EC2 Instances")
+}
+
+func TestPaletteOpensAfterTheOpenClusterIsGone(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ecs")
+	tm.press("enter", "esc")
+	tm.backend.Region("us-east-1").Clusters = nil
+	tm.press("r")
+	if n := len(tm.m.ecsModel.clusterList.Items()); n != 0 {
+		t.Fatalf("%d clusters listed after they were removed", n)
+	}
+
+	tm.press(":")
+	if !tm.m.palette.Focused() || !slices.Contains(tm.m.palette.AvailableSuggestions(), "ecs") {
+		t.Fatal("palette did not open")
+	}
+}
+
+func TestFilteringKeepsEscForTheFilter(t *testing.T) {
+	tm := newTestModel(t, nil)
+	tm.command("ec2")
+	tm.press("/", "w", "e", "b")
+	tm.press("esc")
+	tm.wantHeader("EC2 Instances")
+	if tm.m.state != stateEC2 {
+		t.Fatalf("esc while filtering left the view: state = %v", tm.m.state)
+	}
+}
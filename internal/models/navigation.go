@@ -0,0 +1,130 @@
+package models
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The screens of the views whose models have no states of their own.
+const (
+	screenList = iota
+	screenDetails
+)
+
+// viewTitles head the first screen of each view.
+var viewTitles = map[appState]string{
+	stateEC2:       "EC2 Instances",
+	stateECS:       "ECS Clusters",
+	stateECR:       "ECR Repositories",
+	stateSFN:       "Step Functions",
+	stateBatch:     "Batch Job Queues",
+	stateAudit:     "Audit Log",
+	stateAPI:       "API Calls",
+	stateFavorites: "Favorites",
+}
+
+// frame is a screen the user has opened: the view it is in, the state of
+// the view's model it shows and what it adds to the header.
+type frame struct {
+	state  appState
+	screen int
+	crumbs []string
+}
+
+// navigation is the stack of screens the user has drilled down through to
+// the one on show, which is last. Opening a view from the menu or the
+// command palette starts a new stack, and every screen opened from there,
+// within the view or by following a link into another one, is pushed onto
+// it; going back pops it.
+type navigation struct {
+	frames []frame
+}
+
+// push opens a screen on top of the one on show. Opening the screen on show
+// again, such as when a jump lands on the cluster the user has just opened,
+// replaces it instead.
+func (n *navigation) push(state appState, screen int, crumbs ...string) {
+	if f, ok := n.top(); ok && f.state == state && f.screen == screen {
+		n.frames = n.frames[:len(n.frames)-1]
+	}
+	n.frames = append(n.frames, frame{state: state, screen: screen, crumbs: crumbs})
+}
+
+// replace swaps the screen on show for another, which going back skips.
+func (n *navigation) replace(state appState, screen int, crumbs ...string) {
+	if len(n.frames) > 0 {
+		n.frames = n.frames[:len(n.frames)-1]
+	}
+	n.frames = append(n.frames, frame{state: state, screen: screen, crumbs: crumbs})
+}
+
+// pop closes the screen on show and returns the one below it, if any.
+func (n *navigation) pop() (frame, bool) {
+	if len(n.frames) > 0 {
+		n.frames = n.frames[:len(n.frames)-1]
+	}
+	return n.top()
+}
+
+// top returns the screen on show, if any.
+func (n *navigation) top() (frame, bool) {
+	if len(n.frames) == 0 {
+		return frame{}, false
+	}
+	return n.frames[len(n.frames)-1], true
+}
+
+// reset empties the stack, as on the main menu.
+func (n *navigation) reset() {
+	n.frames = nil
+}
+
+// header is the breadcrumb of the screen on show: the crumbs of the screens
+// opened in its view, back to the first. Views a link was followed from are
+// left out.
+func (n *navigation) header() []string {
+	f, ok := n.top()
+	if !ok {
+		return nil
+	}
+	first := len(n.frames) - 1
+	for first > 0 && n.frames[first-1].state == f.state {
+		first--
+	}
+	var header []string
+	for _, f := range n.frames[first:] {
+		header = append(header, f.crumbs...)
+	}
+	return header
+}
+
+// back closes the screen on show and returns to the one below it as it was
+// left, or to the main menu from the first screen of a view.
+func (m Model) back() (Model, tea.Cmd) {
+	m.leave()
+	m.err = nil
+	f, ok := m.nav.pop()
+	if !ok {
+		m.state = stateMenu
+		m.status = "Select an option."
+		return m, nil
+	}
+	m.state = f.state
+	switch f.state {
+	case stateEC2:
+		m.ec2Model = m.ec2Model.show(f.screen)
+	case stateECS:
+		m.ecsModel = m.ecsModel.show(ecsState(f.screen))
+	case stateECR:
+		m.ecrModel = m.ecrModel.show(ecrState(f.screen))
+	case stateSFN:
+		m.sfnModel = m.sfnModel.show(sfnState(f.screen))
+	case stateBatch:
+		m.batchModel = m.batchModel.show(batchState(f.screen))
+	case stateAudit:
+		m.auditModel = m.auditModel.show(f.screen)
+	case stateAPI:
+		m.apiModel = m.apiModel.show(f.screen)
+	}
+	m, cmd := m.resume()
+	return m, tea.Batch(cmd, m.scheduleRefresh())
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// toastDuration is how long each toast stays in the status bar.
+const toastDuration = 4 * time.Second
+
+// maxToasts caps the toasts waiting to be shown. Older ones are only kept in
+// the history.
+const maxToasts = 3
+
+// notice is a success or failure reported by a view.
+type notice struct {
+	time   time.Time
+	failed bool
+	text   string
+}
+
+// notifier collects the outcome of actions and the errors of every view for
+// the session. Each notice is shown as a toast in the status bar, one after
+// the other, and kept for the history drawer.
+type notifier struct {
+	history []notice
+	toasts  []notice
+	// timing is set while the first toast is on screen.
+	timing bool
+}
+
+// toastMsg is delivered when the toast on screen has been shown long enough.
+type toastMsg struct{}
+
+// success records that an action succeeded.
+func (n *notifier) success(format string, a ...any) {
+	n.notify(notice{time: time.Now(), text: fmt.Sprintf(format, a...)}, true)
+}
+
+// failure records err.
+func (n *notifier) failure(err error) {
+	n.notify(notice{time: time.Now(), failed: true, text: err.Error()}, true)
+}
+
+// record keeps err in the history without a toast, for errors already shown
+// above the header.
+func (n *notifier) record(err error) {
+	n.notify(notice{time: time.Now(), failed: true, text: err.Error()}, false)
+}
+
+func (n *notifier) notify(no notice, toast bool) {
+	n.history = append(n.history, no)
+	if !toast {
+		return
+	}
+	n.toasts = append(n.toasts, no)
+	if over := len(n.toasts) - maxToasts; over > 0 {
+		// Keep the toast on screen and drop the oldest waiting ones.
+		n.toasts = append(n.toasts[:1], n.toasts[1+over:]...)
+	}
+}
+
+// schedule starts the timer of the first toast, if it is not running yet.
+func (n *notifier) schedule() tea.Cmd {
+	if n.timing || len(n.toasts) == 0 {
+		return nil
+	}
+	n.timing = true
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastMsg{} })
+}
+
+// expire removes the toast on screen.
+func (n *notifier) expire() {
+	n.timing = false
+	if len(n.toasts) > 0 {
+		n.toasts = n.toasts[1:]
+	}
+}
+
+// toast renders the toast on screen, at most width wide, or "".
+func (n *notifier) toast(width int) string {
+	if len(n.toasts) == 0 || width < 4 {
+		return ""
+	}
+	t := n.toasts[0]
+	text := "✓ " + t.text
+	style := styles.SuccessStyle
+	if t.failed {
+		text = "✗ " + t.text
+		style = styles.FailureStyle
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if waiting := len(n.toasts) - 1; waiting > 0 {
+		text += fmt.Sprintf(" (+%d)", waiting)
+	}
+	if r := []rune(text); len(r) > width-2 {
+		text = string(r[:width-3]) + "…"
+	}
+	return style.Render(" " + text + " ")
+}
+
+// drawer lists the notices of the session, newest first, in a scrollable
+// panel over the current view.
+type drawer struct {
+	open     bool
+	viewport viewport.Model
+}
+
+// show opens the drawer on the latest notices.
+func (d drawer) show(n *notifier, width, height int) drawer {
+	d.open = true
+	d.viewport = viewport.New(width, max(height-4, 1))
+	d.viewport.SetContent(n.historyView(width))
+	return d
+}
+
+// update scrolls the drawer, or closes it on back or the notifications key.
+func (d drawer) update(msg tea.KeyMsg, k *keys.ListKeyMap) drawer {
+	if key.Matches(msg, k.Back) || key.Matches(msg, k.Notifications) {
+		d.open = false
+		return d
+	}
+	d.viewport, _ = d.viewport.Update(msg)
+	return d
+}
+
+// View renders the drawer with the number of notices in its title.
+func (d drawer) View(n *notifier) string {
+	title := styles.TitleStyle.Render(fmt.Sprintf("Notifications (%d)", len(n.history)))
+	return title + "\n\n" + d.viewport.View()
+}
+
+// hint is the status line shown while the drawer is open.
+func (d drawer) hint(k *keys.ListKeyMap) string {
+	return fmt.Sprintf("↑/↓ scroll • %s close • %d%%", k.Back.Help().Key, int(d.viewport.ScrollPercent()*100))
+}
+
+// historyView renders every notice with its time, wrapped to width so the
+// full AWS error can be read.
+func (n *notifier) historyView(width int) string {
+	if len(n.history) == 0 {
+		return "Nothing happened yet."
+	}
+	wrap := lipgloss.NewStyle().Width(max(width-11, 10))
+	var b strings.Builder
+	for i := len(n.history) - 1; i >= 0; i-- {
+		no := n.history[i]
+		mark := styles.SuccessStyle.Render("✓")
+		if no.failed {
+			mark = styles.FailureStyle.Render("✗")
+		}
+		text := lipgloss.JoinHorizontal(lipgloss.Top, no.time.Format(time.TimeOnly)+" "+mark+" ", wrap.Render(no.text))
+		b.WriteString(text + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
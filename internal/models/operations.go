@@ -0,0 +1,351 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxFinished caps how many finished operations the tray keeps.
+const maxFinished = 20
+
+// operation is a long-running command tracked in the operations tray: a
+// docker pull or push, a waiter, a log download or a bulk action.
+type operation struct {
+	id       int
+	title    string
+	owner    appState
+	started  time.Time
+	ended    time.Time
+	progress string
+	err      error
+	canceled bool
+	cancel   context.CancelFunc
+}
+
+func (o *operation) running() bool {
+	return o.ended.IsZero()
+}
+
+// elapsed is how long the operation has been running, or ran.
+func (o *operation) elapsed() time.Duration {
+	end := o.ended
+	if o.running() {
+		end = time.Now()
+	}
+	return end.Sub(o.started).Round(time.Second)
+}
+
+// operations tracks the long-running commands of every view, so they keep
+// running while the user navigates elsewhere and can be cancelled from the
+// tray.
+type operations struct {
+	next int
+	list []*operation
+}
+
+// startOpMsg asks the root model to start an operation; see startOp.
+type startOpMsg struct {
+	owner      appState
+	title      string
+	foreground bool
+	run        func(context.Context) tea.Cmd
+}
+
+// opMsg is an update or the result of operation id.
+type opMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// startOp returns a command starting the command built by run as an
+// operation of the view in state owner. run gets the context cancelling it.
+// Operations are registered when the command runs, so it can be handed to a
+// confirmation dialog. Background operations outlive the screen they were
+// started from and their result is delivered to owner wherever the user is;
+// foreground ones, such as log downloads, are cancelled when the user leaves
+// the screen, like any other request.
+func startOp(owner appState, title string, background bool, run func(context.Context) tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return startOpMsg{owner: owner, title: title, foreground: !background, run: run}
+	}
+}
+
+// start registers the operation asked for by msg and starts it under parent.
+func (o *operations) start(parent context.Context, msg startOpMsg) tea.Cmd {
+	o.next++
+	ctx, cancel := context.WithCancel(parent)
+	op := &operation{id: o.next, title: msg.title, owner: msg.owner, started: time.Now(), cancel: cancel}
+	o.list = append(o.list, op)
+	return trackCmd(op.id, msg.run(ctx))
+}
+
+// get returns the running operation id, or nil.
+func (o *operations) get(id int) *operation {
+	for _, op := range o.list {
+		if op.id == id && op.running() {
+			return op
+		}
+	}
+	return nil
+}
+
+// finish records the result of op and forgets the oldest finished
+// operations.
+func (o *operations) finish(op *operation, err error) {
+	op.cancel()
+	op.ended = time.Now()
+	op.err = err
+	finished := 0
+	for i := len(o.list) - 1; i >= 0; i-- {
+		if o.list[i].running() {
+			continue
+		}
+		if finished++; finished > maxFinished {
+			o.list = append(o.list[:i], o.list[i+1:]...)
+		}
+	}
+}
+
+// running counts the operations still running.
+func (o *operations) running() int {
+	n := 0
+	for _, op := range o.list {
+		if op.running() {
+			n++
+		}
+	}
+	return n
+}
+
+// opsOnly keeps the operations cmd starts, such as the waiter following an
+// action, and drops its other results, which belong to a screen the user
+// has left.
+func opsOnly(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case startOpMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = opsOnly(c)
+			}
+			return cmds
+		}
+		return nil
+	}
+}
+
+// trackCmd wraps the results of cmd with the operation they belong to.
+func trackCmd(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = trackCmd(id, c)
+			}
+			return cmds
+		default:
+			if strings.HasPrefix(reflect.TypeOf(msg).PkgPath(), "github.com/charmbracelet/") {
+				return msg
+			}
+			return opMsg{id: id, msg: msg}
+		}
+	}
+}
+
+// progress returns what an update of an operation says about its progress
+// and the command delivering the next update, or a nil command if msg is the
+// operation's result.
+func progress(msg tea.Msg) (string, tea.Cmd) {
+	switch msg := msg.(type) {
+	case messages.ProgressMsg:
+		return msg.Text, msg.Next
+	case messages.InstanceWaitMsg:
+		if msg.Instance != nil && msg.Instance.State != nil {
+			return aws.StringValue(msg.Instance.State.Name), msg.Next
+		}
+		return "", msg.Next
+	case messages.EcsServiceWaitMsg:
+		if msg.Service != nil {
+			return fmt.Sprintf("%d/%d tasks running", aws.Int64Value(msg.Service.RunningCount), aws.Int64Value(msg.Service.DesiredCount)), msg.Next
+		}
+		return "", msg.Next
+	case messages.BatchJobWaitMsg:
+		if msg.Job != nil {
+			return strings.ToLower(aws.StringValue(msg.Job.Status)), msg.Next
+		}
+		return "", msg.Next
+	}
+	return "", nil
+}
+
+// result returns the error an operation's result reports, if any.
+func result(msg tea.Msg) error {
+	switch msg := msg.(type) {
+	case messages.ErrMsg:
+		return msg
+	case messages.InstanceWaitMsg:
+		return msg.Err
+	case messages.EcsServiceWaitMsg:
+		return msg.Err
+	case messages.BatchJobWaitMsg:
+		return msg.Err
+	case messages.BulkActionMsg:
+		failed := 0
+		for _, r := range msg.Results {
+			if r.Err != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed for %d of %d", failed, len(msg.Results))
+		}
+	}
+	return nil
+}
+
+// operationUpdate handles an update of an operation. Updates go to the view
+// owning the operation, whichever view is shown, so it can keep its
+// resources current; what the view does in response to the result only
+// happens if it is still shown. Errors are shown like any other, except
+// that of an operation the user cancelled, which only gets a toast.
+func (m Model) operationUpdate(msg opMsg) (Model, tea.Cmd) {
+	op := m.ops.get(msg.id)
+	if op == nil {
+		return m, nil
+	}
+	if text, next := progress(msg.msg); next != nil {
+		if text != "" {
+			op.progress = text
+		}
+		m, _ = m.dispatch(op.owner, msg.msg)
+		return m, trackCmd(op.id, next)
+	}
+
+	err := result(msg.msg)
+	m.ops.finish(op, err)
+	if err, ok := msg.msg.(messages.ErrMsg); ok {
+		switch {
+		case op.canceled:
+			m.notes.failure(fmt.Errorf("%s: cancelled", op.title))
+			return m, nil
+		case isCanceled(err):
+			// A foreground operation of a screen the user has left.
+			return m, nil
+		}
+		return m.update(err)
+	}
+	m, cmd := m.dispatch(op.owner, msg.msg)
+	if m.state != op.owner {
+		cmd = opsOnly(cmd)
+	}
+	return m, cmd
+}
+
+// tray lists the operations of the session, running ones first, and cancels
+// the selected one.
+type tray struct {
+	open   bool
+	cursor int
+}
+
+// sorted returns the operations in the order the tray lists them: running
+// ones first, newest first.
+func (o *operations) sorted() []*operation {
+	var running, finished []*operation
+	for i := len(o.list) - 1; i >= 0; i-- {
+		if o.list[i].running() {
+			running = append(running, o.list[i])
+		} else {
+			finished = append(finished, o.list[i])
+		}
+	}
+	return append(running, finished...)
+}
+
+// update moves the cursor, cancels the selected operation on stop, or closes
+// the tray on back or the operations key.
+func (t tray) update(msg tea.KeyMsg, k *keys.ListKeyMap, ops *operations) tray {
+	list := ops.sorted()
+	switch {
+	case key.Matches(msg, k.Back), key.Matches(msg, k.Operations):
+		t.open = false
+	case msg.String() == "up" || msg.String() == "k":
+		t.cursor = max(t.cursor-1, 0)
+	case msg.String() == "down" || msg.String() == "j":
+		t.cursor = min(t.cursor+1, max(len(list)-1, 0))
+	case key.Matches(msg, k.Stop):
+		if t.cursor < len(list) && list[t.cursor].running() {
+			list[t.cursor].canceled = true
+			list[t.cursor].cancel()
+		}
+	}
+	return t
+}
+
+// View renders the operations, at most height lines.
+func (t tray) View(ops *operations, width, height int) string {
+	list := ops.sorted()
+	var b strings.Builder
+	b.WriteString(styles.TitleStyle.Render(fmt.Sprintf("Operations (%d running)", ops.running())) + "\n\n")
+	if len(list) == 0 {
+		b.WriteString("No operations yet.")
+		return b.String()
+	}
+	first := max(0, min(t.cursor-(height-3), len(list)-(height-2)))
+	for i := first; i < len(list) && i-first < height-2; i++ {
+		op := list[i]
+		var mark, state string
+		switch {
+		case op.running():
+			mark, state = "⟳", op.progress
+		case op.canceled:
+			mark, state = styles.FailureStyle.Render("✗"), "cancelled"
+		case op.err != nil:
+			mark, state = styles.FailureStyle.Render("✗"), op.err.Error()
+		default:
+			mark, state = styles.SuccessStyle.Render("✓"), "done"
+		}
+		line := fmt.Sprintf("%-8s %s", op.elapsed(), op.title)
+		if state != "" {
+			line += " — " + strings.Join(strings.Fields(state), " ")
+		}
+		if r := []rune(line); width > 4 && len(r) > width-4 {
+			line = string(r[:width-5]) + "…"
+		}
+		line = mark + " " + line
+		if i == t.cursor {
+			line = styles.SelectedItemStyle.Render(line)
+		} else {
+			line = styles.UnselectedItemStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// hint is the status line shown while the tray is open.
+func (t tray) hint(k *keys.ListKeyMap) string {
+	return fmt.Sprintf("%s cancel operation • %s close", k.Stop.Help().Key, k.Back.Help().Key)
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteCommands are the commands of the command palette besides the
+// resource views.
+var paletteCommands = []string{"region", "profile", "favorites", "audit", "api", "notifications", "menu", "quit"}
+
+// paletteMatches is how many matching commands the palette lists.
+const paletteMatches = 5
+
+func newPalette() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ":"
+	ti.ShowSuggestions = true
+	return ti
+}
+
+// openPalette focuses the command palette and offers every command,
+// region, profile and loaded resource as a completion.
+func (m Model) openPalette() (Model, tea.Cmd) {
+	m.palette.Reset()
+	m.palette.SetSuggestions(m.paletteSuggestions())
+	return m, m.palette.Focus()
+}
+
+// closePalette hides the command palette.
+func (m Model) closePalette() Model {
+	m.palette.Blur()
+	m.palette.Reset()
+	return m
+}
+
+// paletteSuggestions lists the completions of the command palette.
+func (m Model) paletteSuggestions() []string {
+	var s []string
+	for view := range views {
+		s = append(s, view)
+	}
+	s = append(s, paletteCommands...)
+
+	for _, r := range append([]string{allRegions}, m.everyRegion()...) {
+		s = append(s, "region "+r)
+	}
+	for _, p := range clients.ListProfiles() {
+		s = append(s, "profile "+p.Name)
+	}
+
+	add := func(prefix string, l list.Model) {
+		for _, listItem := range l.Items() {
+			s = append(s, prefix+listItem.(item).Title())
+		}
+	}
+	add("ec2 ", m.ec2Model.instanceList)
+	add("ecs ", m.ecsModel.clusterList)
+	if m.ecsModel.detailCluster != nil {
+		add("ecs "+aws.StringValue(m.ecsModel.detailCluster.ClusterName)+"/", m.ecsModel.serviceList)
+	}
+	add("ecr ", m.ecrModel.repositoryList)
+	if m.ecrModel.selectedRepository != nil {
+		add("ecr "+aws.StringValue(m.ecrModel.selectedRepository.RepositoryName)+"/", m.ecrModel.imageList)
+	}
+	add("sfn ", m.sfnModel.sfnList)
+	if m.sfnModel.selectedStateMachine != nil {
+		add("sfn "+aws.StringValue(m.sfnModel.selectedStateMachine.Name)+"/", m.sfnModel.executionList)
+	}
+	add("batch ", m.batchModel.jobQueueList)
+	if m.batchModel.detailJobQueue != nil {
+		add("batch "+aws.StringValue(m.batchModel.detailJobQueue.JobQueueName)+"/", m.batchModel.jobList)
+	}
+
+	slices.Sort(s)
+	return slices.Compact(s)
+}
+
+// runCommand executes a line of the command palette, such as "ec2",
+// "ecs prod-cluster/api" or "region eu-west-1".
+func (m Model) runCommand(line string) (Model, tea.Cmd) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+
+	if state, ok := views[name]; ok {
+		m.leave()
+		m.nav.reset()
+		var path []string
+		if arg != "" {
+			path = strings.FieldsFunc(arg, func(r rune) bool { return r == '/' })
+		}
+		return m.jump(state, path)
+	}
+
+	switch name {
+	case "":
+		return m, nil
+	case "favorites":
+		m.leave()
+		m.nav.reset()
+		return m.openFavorites()
+	case "audit":
+		m.leave()
+		m.nav.reset()
+		return m.openAudit()
+	case "api":
+		m.leave()
+		m.nav.reset()
+		return m.openAPICalls()
+	case "notifications":
+		m.drawer = m.drawer.show(m.notes, m.width, m.height-3)
+		return m, nil
+	case "menu":
+		m.leave()
+		m.nav.reset()
+		m.state = stateMenu
+		m.status = "Select an option."
+		m.err = nil
+		return m, nil
+	case "quit":
+		return m, tea.Quit
+	case "profile":
+		if arg == "" {
+			return m.openProfilePicker()
+		}
+		if m.state != stateProfile && m.state != stateRegion {
+			m.prevState = m.state
+		}
+		return m.switchProfile(arg)
+	case "region":
+		if arg == "" {
+			return m.openRegionPicker()
+		}
+		if arg != allRegions && !slices.Contains(m.everyRegion(), arg) {
+			m.err = fmt.Errorf("unknown region %q", arg)
+			return m, nil
+		}
+		if m.state != stateProfile && m.state != stateRegion {
+			m.prevState = m.state
+		}
+		return m.switchRegion(arg)
+	}
+	m.err = fmt.Errorf("unknown command %q", name)
+	return m, nil
+}
+
+// jump shows the view in state on top of the screen on show and heads for
+// the resource named by path, such as a cluster and one of its services.
+func (m Model) jump(state appState, path []string) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.state = state
+	m.nav.push(state, screenList, viewTitles[state])
+	m.err = nil
+	switch state {
+	case stateEC2:
+		m.ec2Model, cmd = m.ec2Model.jump(path)
+	case stateECS:
+		m.ecsModel, cmd = m.ecsModel.jump(path)
+	case stateECR:
+		m.ecrModel, cmd = m.ecrModel.jump(path)
+	case stateSFN:
+		m.sfnModel, cmd = m.sfnModel.jump(path)
+	case stateBatch:
+		m.batchModel, cmd = m.batchModel.jump(path)
+	}
+	return m, tea.Batch(cmd, m.scheduleRefresh())
+}
+
+// paletteView renders the command palette in place of the status bar,
+// followed by the commands matching what has been typed so far.
+func (m Model) paletteView() string {
+	s := m.palette.View()
+	if m.palette.Value() == "" {
+		return s
+	}
+	matches := m.palette.MatchedSuggestions()
+	if len(matches) > paletteMatches {
+		matches = append(slices.Clone(matches[:paletteMatches]), "…")
+	}
+	return s + "  " + strings.Join(matches, "  ")
+}
+
+// aliasedItem is implemented by items that go by other names than their
+// display name, such as images by each of their tags.
+type aliasedItem interface {
+	aliases() []string
+}
+
+// jumpSelect selects the item of l called name, matching either its display
+// name, one of its aliases or its identifier, and clears any filter hiding it.
+func jumpSelect(l *list.Model, noun, name string) error {
+	for i, listItem := range l.Items() {
+		key := itemKey(listItem)
+		aliased, ok := listItem.(aliasedItem)
+		if listItem.(item).Title() == name || key == name || strings.HasSuffix(key, "/"+name) || ok && slices.Contains(aliased.aliases(), name) {
+			l.ResetFilter()
+			l.Select(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %q not found", noun, name)
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Filter values encode the fields an item can be searched by, each as its
+// name and value, so the filter can tell them apart.
+const (
+	fieldSep = "\x1e"
+	valueSep = "\x1f"
+)
+
+// field is a value an item can be searched by, such as the state of an
+// instance. An item may have several fields of the same name, like tags.
+type field struct {
+	name, value string
+}
+
+// fields encodes fs as the filter value of an item; see queryFilter.
+func fields(fs ...field) string {
+	parts := make([]string, len(fs))
+	for i, f := range fs {
+		parts[i] = f.name + valueSep + strings.Join(strings.Fields(f.value), " ")
+	}
+	return strings.Join(parts, fieldSep)
+}
+
+// parseFields decodes the filter value of an item. A plain value, of an item
+// without fields, becomes a single unnamed field.
+func parseFields(s string) []field {
+	if !strings.Contains(s, valueSep) {
+		return []field{{value: s}}
+	}
+	var fs []field
+	for _, part := range strings.Split(s, fieldSep) {
+		name, value, _ := strings.Cut(part, valueSep)
+		fs = append(fs, field{name: name, value: value})
+	}
+	return fs
+}
+
+// term is a field term of a query, such as "state:running".
+type term struct {
+	field   string
+	pattern *regexp.Regexp
+}
+
+// newTerm matches the values of field that start with the glob pattern, in
+// which * matches any text and ? any character, regardless of case. So
+// state:run matches running instances, and tag:env any tag with that key.
+func newTerm(name, pattern string) term {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.NewReplacer(`\*`, `.*`, `\?`, `.`).Replace(expr)
+	return term{field: name, pattern: regexp.MustCompile(`(?is)^` + expr)}
+}
+
+// matches reports whether any field of the term's name in fs matches.
+func (t term) matches(fs []field) bool {
+	for _, f := range fs {
+		if f.name == t.field && t.pattern.MatchString(f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryFilter is the filter of every list. The query is a list of words:
+// a word such as state:running or ip:10.0.* is a field term, which items
+// only match if one of their fields of that name matches the glob after the
+// colon; any other word is free text, matched fuzzily against every field.
+// Items must match every word. They are ranked by how well they match the
+// free text, or keep their order if there is none.
+func queryFilter(query string, targets []string) []list.Rank {
+	items := make([][]field, len(targets))
+	names := map[string]bool{}
+	for i, target := range targets {
+		items[i] = parseFields(target)
+		for _, f := range items[i] {
+			names[f.name] = f.name != ""
+		}
+	}
+
+	var terms []term
+	var text []string
+	for _, word := range strings.Fields(query) {
+		// Words naming no field, such as ARNs, are free text.
+		if name, pattern, ok := strings.Cut(word, ":"); ok && names[strings.ToLower(name)] {
+			terms = append(terms, newTerm(strings.ToLower(name), pattern))
+		} else {
+			text = append(text, word)
+		}
+	}
+
+	var matches []int
+	for i, fs := range items {
+		matched := true
+		for _, t := range terms {
+			matched = matched && t.matches(fs)
+		}
+		if matched {
+			matches = append(matches, i)
+		}
+	}
+	for _, word := range text {
+		matches = fuzzyMatch(word, matches, items)
+	}
+
+	ranks := make([]list.Rank, len(matches))
+	for i, index := range matches {
+		ranks[i] = list.Rank{Index: index}
+	}
+	return ranks
+}
+
+// fuzzyMatch keeps the items among candidates that have a field word
+// fuzzily matches, best matches first.
+func fuzzyMatch(word string, candidates []int, items [][]field) []int {
+	var values []string
+	var owners []int
+	for _, i := range candidates {
+		for _, f := range items[i] {
+			values = append(values, f.value)
+			owners = append(owners, i)
+		}
+	}
+	seen := map[int]bool{}
+	var kept []int
+	for _, r := range list.DefaultFilter(word, values) {
+		if i := owners[r.Index]; !seen[i] {
+			seen[i] = true
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
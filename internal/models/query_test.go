@@ -0,0 +1,62 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQueryFilter(t *testing.T) {
+	targets := []string{
+		fields(field{"name", "web-1"}, field{"state", "running"}, field{"ip", "10.0.0.10"}, field{"tag", "env=prod"}, field{"tag", "team=web"}),
+		fields(field{"name", "web-2"}, field{"state", "stopped"}, field{"ip", "10.0.1.11"}, field{"tag", "env=dev"}),
+		fields(field{"name", "bastion"}, field{"state", "running"}, field{"ip", "192.168.0.5"}, field{"role", "arn:aws:iam::1:role/ops"}),
+		fields(field{"name", "legacy-web"}, field{"state", "Running"}, field{"ip", "10.1.0.3"}),
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"empty", "", []int{0, 1, 2, 3}},
+		{"field term", "state:running", []int{0, 2, 3}},
+		{"prefix", "state:stop", []int{1}},
+		{"case folded", "STATE:RUN", []int{0, 2, 3}},
+		{"glob star", "ip:10.*.3", []int{3}},
+		{"glob question mark", "ip:10.0.?.1?", []int{0, 1}},
+		{"dot is literal", "ip:10x0", nil},
+		{"any of repeated fields", "tag:team", []int{0}},
+		{"every term", "tag:env=prod tag:team", []int{0}},
+		{"terms and", "state:running ip:10.0", []int{0}},
+		{"unknown field is free text", "arn:aws", []int{2}},
+		{"free text", "bastion", []int{2}},
+		{"free text ranked", "web", []int{0, 1, 3}},
+		{"free text ranks best first", "legacyweb", []int{3}},
+		{"terms and free text", "state:running web", []int{0, 3}},
+		{"no match", "state:pending", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, r := range queryFilter(tt.query, targets) {
+				got = append(got, r.Index)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("queryFilter(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryFilterPlainValues(t *testing.T) {
+	targets := []string{"nightly-etl", "deploy-migrations"}
+	var got []int
+	for _, r := range queryFilter("migr", targets) {
+		got = append(got, r.Index)
+	}
+	if !slices.Equal(got, []int{1}) {
+		t.Fatalf("queryFilter() = %v, want the plain value matched as free text", got)
+	}
+	if ranks := queryFilter("state:running", targets); len(ranks) != 0 {
+		t.Fatalf("queryFilter() = %v, want no fields to match", ranks)
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scope ties the work a view starts to the screen it was started from. Views
+// pass its context to every command that fetches or waits, and the root model
+// tags the results of those commands with the scope's generation. When the
+// user navigates away the context is cancelled and the generation moves on,
+// so requests still in flight are abandoned and any late result is dropped
+// instead of landing on another screen.
+type scope struct {
+	gen    int
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newScope() *scope {
+	s := &scope{}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// context returns the context of the current screen.
+func (s *scope) context() context.Context {
+	return s.ctx
+}
+
+// leave cancels the work started so far and starts a new generation.
+func (s *scope) leave() {
+	s.cancel()
+	s.gen++
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+// scopedMsg is the result of a command started in generation gen.
+type scopedMsg struct {
+	gen int
+	msg tea.Msg
+}
+
+// tag wraps the results of cmd, and of the batches it returns, with the
+// current generation. Bubble Tea's own messages, such as spinner ticks,
+// quitting or running a process, are passed through untouched, and so are
+// operations, which outlive the screen; see operations.
+func (s *scope) tag(cmd tea.Cmd) tea.Cmd {
+	return tagCmd(s.gen, cmd)
+}
+
+func tagCmd(gen int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case scopedMsg, opMsg, startOpMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = tagCmd(gen, c)
+			}
+			return cmds
+		default:
+			if strings.HasPrefix(reflect.TypeOf(msg).PkgPath(), "github.com/charmbracelet/") {
+				return msg
+			}
+			return scopedMsg{gen: gen, msg: msg}
+		}
+	}
+}
+
+// current reports whether msg should still be delivered. Results of an
+// earlier generation are dropped, except the identity and regions of the
+// profile, which the root model checks itself, and errors, since the status
+// bar is shared by every screen. Errors caused by the cancellation itself
+// are dropped as well.
+func (s *scope) current(msg scopedMsg) bool {
+	if msg.gen == s.gen {
+		return true
+	}
+	switch m := msg.msg.(type) {
+	case messages.CallerIdentityMsg, messages.RegionsFetchedMsg:
+		return true
+	case messages.ErrMsg:
+		return !isCanceled(m)
+	}
+	return false
+}
+
+// isCanceled reports whether err is the result of a cancelled context.
+func isCanceled(err error) bool {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == request.CanceledErrorCode {
+		return true
+	}
+	return errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/messages"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxBulkRows caps how many resources a bulk confirmation lists; the rest
+// are counted.
+const maxBulkRows = 8
+
+// selection is the set of items marked in a list for a bulk action. Items
+// are marked by key, so the marks follow their resources when the list is
+// reloaded. It is shared with the list's delegate, which renders the marks.
+type selection struct {
+	keys map[string]bool
+}
+
+func newSelection() *selection {
+	return &selection{keys: map[string]bool{}}
+}
+
+// has reports whether item is marked. A nil selection has no marks.
+func (s *selection) has(item list.Item) bool {
+	return s != nil && s.keys[itemKey(item)]
+}
+
+// update handles the mark keys: mark toggles the selected item of l and moves
+// on to the next one, mark all marks every item l shows, which are the
+// filtered ones while a filter is applied, or unmarks them if they are all
+// marked already. It reports whether msg was one of those keys.
+func (s *selection) update(msg tea.KeyMsg, k *keys.ListKeyMap, l *list.Model) bool {
+	switch {
+	case key.Matches(msg, k.Mark):
+		if item := l.SelectedItem(); item != nil {
+			s.keys[itemKey(item)] = !s.has(item)
+			l.CursorDown()
+		}
+	case key.Matches(msg, k.MarkAll):
+		visible := l.VisibleItems()
+		all := true
+		for _, item := range visible {
+			all = all && s.has(item)
+		}
+		for _, item := range visible {
+			s.keys[itemKey(item)] = !all
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// marked returns the marked items of l in list order, leaving out marks of
+// resources that are gone.
+func (s *selection) marked(l list.Model) []list.Item {
+	var items []list.Item
+	for _, item := range l.Items() {
+		if s.has(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// unmark removes the mark of the item with key k.
+func (s *selection) unmark(k string) {
+	delete(s.keys, k)
+}
+
+// clear removes every mark.
+func (s *selection) clear() {
+	clear(s.keys)
+}
+
+// listed reports whether l has the item with key k.
+func listed(l list.Model, k string) bool {
+	for _, item := range l.Items() {
+		if itemKey(item) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// count is n followed by noun, in the plural unless n is 1.
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// bulkChanges lists the resources of a bulk action in its confirmation, one
+// row each, up to maxBulkRows.
+func bulkChanges(rows []change) []change {
+	if len(rows) <= maxBulkRows {
+		return rows
+	}
+	return append(rows[:maxBulkRows:maxBulkRows], change{field: fmt.Sprintf("…and %d more", len(rows)-maxBulkRows)})
+}
+
+// reportBulk records the outcome of a bulk action for each of its resources,
+// named by name, in the notification history and sums it up in a toast. It
+// returns the summary and the errors of the resources the action failed for.
+func reportBulk(n *notifier, msg messages.BulkActionMsg, noun string, name func(messages.BulkResult) string) (string, error) {
+	var errs []error
+	for _, r := range msg.Results {
+		if r.Err != nil {
+			err := fmt.Errorf("%s: %w", name(r), r.Err)
+			errs = append(errs, err)
+			n.notify(notice{time: time.Now(), failed: true, text: err.Error()}, false)
+		} else {
+			n.notify(notice{time: time.Now(), text: fmt.Sprintf("%s %s %s", strings.ToUpper(noun[:1])+noun[1:], name(r), msg.Action)}, false)
+		}
+	}
+	succeeded := len(msg.Results) - len(errs)
+	if len(errs) == 0 {
+		summary := fmt.Sprintf("%s %s", count(succeeded, noun), msg.Action)
+		n.success("%s", summary)
+		return summary, nil
+	}
+	summary := fmt.Sprintf("%d of %s %s, %d failed", succeeded, count(len(msg.Results), noun), msg.Action, len(errs))
+	n.notify(notice{time: time.Now(), failed: true, text: summary}, true)
+	return summary, errors.Join(errs...)
+}
+
+// marked counts the items marked in the list on screen.
+func (m Model) marked() int {
+	switch {
+	case m.state == stateEC2:
+		return len(m.ec2Model.marks.marked(m.ec2Model.instanceList))
+	case m.state == stateECS && m.ecsModel.state == ecsStateServiceList:
+		return len(m.ecsModel.serviceMarks.marked(m.ecsModel.serviceList))
+	case m.state == stateECR && m.ecrModel.state == ecrStateImageList:
+		return len(m.ecrModel.imageMarks.marked(m.ecrModel.imageList))
+	case m.state == stateBatch && m.batchModel.state == batchStateJobList:
+		return len(m.batchModel.jobMarks.marked(m.batchModel.jobList))
+	}
+	return 0
+}
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/commands"
 	"github.com/theoreticallyjosh/awstui/internal/keys"
 	"github.com/theoreticallyjosh/awstui/internal/messages"
@@ -28,26 +31,44 @@ const (
 
 type sfnModel struct {
 	parent               *Model
-	sfnSvc               *sfn.SFN
+	pool                 *clients.Pool
+	regions              []string
+	regionClients        *clients.Clients
 	sfnList              list.Model
 	executionList        list.Model
 	executionHistoryList list.Model
+	stateMachineLoader   listLoader
+	executionLoader      listLoader
 	inputArea            textarea.Model
 	status               string
 	err                  error
 	keys                 *keys.ListKeyMap
 	state                sfnState
-	header               []string
+	nav                  *navigation
 	selectedStateMachine *sfn.StateMachineListItem
 	selectedExecution    *sfn.ExecutionListItem
+	jumpTo               []string
+	confirm              confirmDialog
+	guard                *guard
+	scope                *scope
+	notes                *notifier
 }
 
-func (m sfnModel) Init() tea.Cmd {
-	return tea.Batch(m.parent.spinner.Tick, commands.FetchSFNStateMachinesCmd(m.sfnSvc))
+// loadStateMachines starts loading the state machines, abandoning the load
+// in progress.
+func (m sfnModel) loadStateMachines() (sfnModel, tea.Cmd) {
+	cmd := m.stateMachineLoader.load(commands.FetchSFNStateMachinesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
+}
+
+// loadExecutions starts loading the executions of the state machine on show,
+// abandoning the load in progress.
+func (m sfnModel) loadExecutions() (sfnModel, tea.Cmd) {
+	cmd := m.executionLoader.load(commands.FetchSFNExecutionsCmd(m.scope.context(), m.regionClients.SFN, m.selectedStateMachine.StateMachineArn))
+	return m, tea.Batch(m.parent.spinner.Tick, cmd)
 }
 
 func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
-	m.header = []string{"Step Functions"}
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -55,26 +76,17 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 		m.executionList.SetSize(msg.Width, msg.Height)
 		m.executionHistoryList.SetSize(msg.Width, msg.Height)
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back"))):
-			if m.state == sfnStateExecutions {
-				m.state = sfnStateList
-				m.status = "Ready"
-				m.err = nil
-				m.executionList.SetItems([]list.Item{})
-				return m, nil
-			} else if m.state == sfnStateStartExecution {
-				m.state = sfnStateExecutions
-				m.status = "Ready"
-				m.err = nil
-				m.inputArea.Reset()
-				return m, nil
-			} else {
-				m.state = sfnStateExecutions
-				m.status = "Ready"
-				m.err = nil
-				return m, nil
+		if m.confirm.visible {
+			var result confirmResult
+			m.confirm, result = m.confirm.update(msg, m.keys)
+			switch result {
+			case confirmAccepted:
+				m.status = m.confirm.running
+				return m, m.confirm.accept
+			case confirmRejected:
+				m.status = "Enter execution input (JSON)"
 			}
+			return m, nil
 		}
 		switch m.state {
 		case sfnStateList:
@@ -85,22 +97,24 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = styles.StatusStyle.Render("Refreshing state machines...")
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNStateMachinesCmd(m.sfnSvc))
+				return m.loadStateMachines()
 			case key.Matches(msg, m.keys.Choose):
 				if m.sfnList.SelectedItem() != nil {
+					return m.openStateMachine(m.sfnList.SelectedItem().(sfnStateMachineItem))
+				}
+			case key.Matches(msg, m.keys.StartExecution):
+				if err := m.guard.check("start executions"); err != nil {
+					m.status = blocked(err)
+				} else if m.sfnList.SelectedItem() != nil {
 					selectedItem := m.sfnList.SelectedItem().(sfnStateMachineItem)
 					m.selectedStateMachine = selectedItem.stateMachine
-					m.state = sfnStateExecutions
-					m.status = fmt.Sprintf("Loading executions for %s...", aws.StringValue(selectedItem.stateMachine.Name))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionsCmd(m.sfnSvc, selectedItem.stateMachine.StateMachineArn))
+					m.regionClients = m.pool.Region(selectedItem.region)
+					m.state = sfnStateStartExecution
+					m.nav.push(stateSFN, int(sfnStateStartExecution), aws.StringValue(m.selectedStateMachine.Name), "Start execution")
+					m.status = "Enter execution input (JSON)"
+					m.inputArea.Focus()
+					return m, nil
 				}
-			case key.Matches(msg, m.keys.StartExecution):
-				selectedItem := m.sfnList.SelectedItem().(sfnStateMachineItem)
-				m.selectedStateMachine = selectedItem.stateMachine
-				m.state = sfnStateStartExecution
-				m.status = "Enter execution input (JSON)"
-				m.inputArea.Focus()
-				return m, nil
 			}
 		case sfnStateExecutions:
 			if m.executionList.FilterState() == list.Filtering {
@@ -110,14 +124,15 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = styles.StatusStyle.Render("Refreshing executions...")
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionsCmd(m.sfnSvc, m.selectedStateMachine.StateMachineArn))
+				return m.loadExecutions()
 			case key.Matches(msg, m.keys.Choose):
 				if m.executionList.SelectedItem() != nil {
 					selectedItem := m.executionList.SelectedItem().(sfnExecutionItem)
 					m.selectedExecution = selectedItem.execution
 					m.state = sfnStateExecutionDetails
+					m.nav.push(stateSFN, int(sfnStateExecutionDetails), aws.StringValue(selectedItem.execution.Name), "History")
 					m.status = fmt.Sprintf("Loading execution history for %s...", aws.StringValue(selectedItem.execution.Name))
-					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionHistoryCmd(m.sfnSvc, selectedItem.execution.ExecutionArn))
+					return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionHistoryCmd(m.scope.context(), m.regionClients.SFN, selectedItem.execution.ExecutionArn))
 				}
 			}
 		case sfnStateExecutionDetails:
@@ -128,36 +143,74 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 			case key.Matches(msg, m.keys.Refresh):
 				m.status = styles.StatusStyle.Render("Refreshing execution history...")
 				m.err = nil
-				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionHistoryCmd(m.sfnSvc, m.selectedExecution.ExecutionArn))
+				return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionHistoryCmd(m.scope.context(), m.regionClients.SFN, m.selectedExecution.ExecutionArn))
+			case key.Matches(msg, m.keys.GoTo):
+				if item, ok := m.executionHistoryList.SelectedItem().(sfnExecutionHistoryItem); ok && item.link != nil {
+					return m, follow(*item.link)
+				}
 			}
 		case sfnStateStartExecution:
 			if key.Matches(msg, m.keys.Choose) {
 				input := m.inputArea.Value()
-				m.status = "Starting execution..."
-				return m, commands.StartSFNExecutionCmd(m.sfnSvc, m.selectedStateMachine.StateMachineArn, &input)
+				accept := m.guard.audited("states:StartExecution", m.regionClients.Region, aws.StringValue(m.selectedStateMachine.StateMachineArn),
+					map[string]string{"input": input},
+					commands.StartSFNExecutionCmd(context.Background(), m.regionClients.SFN, m.selectedStateMachine.StateMachineArn, &input))
+				// Submitting the input is confirmation enough, unless the
+				// policy asks for the name to be typed.
+				if !m.guard.typed() {
+					m.status = "Starting execution..."
+					return m, accept
+				}
+				name := aws.StringValue(m.selectedStateMachine.Name)
+				m.confirm = m.guard.open(confirmDialog{
+					title:    "Start execution",
+					resource: name,
+					name:     name,
+					region:   m.regionClients.Region,
+					changes:  []change{{"Input", "", strings.Join(strings.Fields(input), " ")}},
+					accept:   accept,
+					running:  "Starting execution...",
+				})
+				return m, nil
 			}
 		}
+	case pageMsg:
+		if !m.stateMachineLoader.current(msg) && !m.executionLoader.current(msg) {
+			return m, nil
+		}
+		return m.Update(msg.msg)
 	case messages.SfnStateMachinesFetchedMsg:
-		listItems := make([]list.Item, len(msg))
-		for i, sm := range msg {
-			listItems[i] = sfnStateMachineItem{stateMachine: sm}
+		listItems := make([]list.Item, len(msg.StateMachines))
+		for i, sm := range msg.StateMachines {
+			listItems[i] = sfnStateMachineItem{stateMachine: sm, region: msg.Region}
 		}
-		m.sfnList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.stateMachineLoader.add(&m.sfnList, listItems, msg.Page)
+		m.status = m.stateMachineLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.stateMachineLoader.loading() {
+			var jumpCmd tea.Cmd
+			m, jumpCmd = m.jumpToStateMachine()
+			return m, tea.Batch(cmd, jumpCmd)
+		}
+		return m, cmd
 	case messages.SfnExecutionsFetchedMsg:
-		m.header = append(m.header, aws.StringValue(m.selectedStateMachine.Name), "Executions")
-		listItems := make([]list.Item, len(msg))
-		for i, ex := range msg {
+		listItems := make([]list.Item, len(msg.Executions))
+		for i, ex := range msg.Executions {
 			listItems[i] = sfnExecutionItem{execution: ex}
 		}
-		m.executionList.SetItems(listItems)
-		m.status = "Ready"
+		cmd = m.executionLoader.add(&m.executionList, listItems, msg.Page)
+		m.status = m.executionLoader.status()
 		m.err = nil
-		return m, nil
+		if len(m.jumpTo) > 0 && !m.executionLoader.loading() {
+			if err := jumpSelect(&m.executionList, "execution", m.jumpTo[0]); err != nil {
+				m.err = err
+				m.status = fmt.Sprintf("Error: %v", err)
+				m.notes.failure(err)
+			}
+			m.jumpTo = nil
+		}
+		return m, cmd
 	case messages.SfnExecutionHistoryFetchedMsg:
-		m.header = append(m.header, aws.StringValue(m.selectedStateMachine.Name), "Executions", aws.StringValue(m.selectedExecution.Name), "History")
 		eventsMap := make(map[int64]*sfn.HistoryEvent)
 		for _, e := range msg {
 			if e.Id != nil {
@@ -168,17 +221,22 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 		for i := 1; i < len(eventsMap); i++ {
 			event := eventsMap[int64(i)]
 			stateName := GetStateName(event, msg, eventsMap)
-			listItems = append(listItems, sfnExecutionHistoryItem{event: &sfnHistoryState{ID: event.Id, Step: &stateName, Type: event.Type, Timestamp: event.Timestamp}})
+			listItems = append(listItems, sfnExecutionHistoryItem{
+				event: &sfnHistoryState{ID: event.Id, Step: &stateName, Type: event.Type, Timestamp: event.Timestamp},
+				link:  launched(event, eventsMap, m.regionClients.Region),
+			})
 		}
-		m.executionHistoryList.SetItems(listItems)
+		setItems(&m.executionHistoryList, listItems)
 		m.status = "Ready"
 		m.err = nil
 		return m, nil
 	case messages.SfnExecutionStartedMsg:
 		m.state = sfnStateExecutions
+		m.nav.replace(stateSFN, int(sfnStateExecutions), aws.StringValue(m.selectedStateMachine.Name), "Executions")
 		m.status = "Execution started successfully"
+		m.notes.success("Execution %s started", string(msg)[strings.LastIndex(string(msg), ":")+1:])
 		m.inputArea.Reset()
-		return m, tea.Batch(m.parent.spinner.Tick, commands.FetchSFNExecutionsCmd(m.sfnSvc, m.selectedStateMachine.StateMachineArn))
+		return m.loadExecutions()
 	case messages.ErrMsg:
 		m.err = msg
 		m.status = "Error"
@@ -187,10 +245,8 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 	if m.state == sfnStateList {
 		m.sfnList, cmd = m.sfnList.Update(msg)
 	} else if m.state == sfnStateExecutions {
-		m.header = append(m.header, aws.StringValue(m.selectedStateMachine.Name), "Executions")
 		m.executionList, cmd = m.executionList.Update(msg)
 	} else if m.state == sfnStateExecutionDetails {
-		m.header = append(m.header, aws.StringValue(m.selectedStateMachine.Name), "Executions", aws.StringValue(m.selectedExecution.Name), "History")
 		m.executionHistoryList, cmd = m.executionHistoryList.Update(msg)
 	} else if m.state == sfnStateStartExecution {
 		m.inputArea, cmd = m.inputArea.Update(msg)
@@ -198,12 +254,83 @@ func (m sfnModel) Update(msg tea.Msg) (sfnModel, tea.Cmd) {
 	return m, cmd
 }
 
+// openStateMachine shows the executions of a state machine.
+func (m sfnModel) openStateMachine(selectedItem sfnStateMachineItem) (sfnModel, tea.Cmd) {
+	m.selectedStateMachine = selectedItem.stateMachine
+	m.regionClients = m.pool.Region(selectedItem.region)
+	m.state = sfnStateExecutions
+	m.nav.push(stateSFN, int(sfnStateExecutions), aws.StringValue(selectedItem.stateMachine.Name), "Executions")
+	m.status = fmt.Sprintf("Loading executions for %s...", aws.StringValue(selectedItem.stateMachine.Name))
+	return m.loadExecutions()
+}
+
+// show returns to a screen opened before, dropping what the screens above it
+// loaded.
+func (m sfnModel) show(state sfnState) sfnModel {
+	if state == sfnStateList {
+		m.executionList.SetItems([]list.Item{})
+	}
+	m.inputArea.Reset()
+	m.state = state
+	m.status = "Ready"
+	m.err = nil
+	return m
+}
+
+// jump shows the state machine list and, once it has loaded, opens the state
+// machine and selects the execution named by path.
+func (m sfnModel) jump(path []string) (sfnModel, tea.Cmd) {
+	m.state = sfnStateList
+	m.jumpTo = path
+	if m.stateMachineLoader.loading() {
+		return m, nil
+	}
+	m.status = "Loading state machines..."
+	return m.loadStateMachines()
+}
+
+// jumpToStateMachine opens the state machine a jump is headed for.
+func (m sfnModel) jumpToStateMachine() (sfnModel, tea.Cmd) {
+	if err := jumpSelect(&m.sfnList, "state machine", m.jumpTo[0]); err != nil {
+		m.jumpTo = nil
+		m.err = err
+		m.status = fmt.Sprintf("Error: %v", err)
+		m.notes.failure(err)
+		return m, nil
+	}
+	m.jumpTo = m.jumpTo[1:]
+	return m.openStateMachine(m.sfnList.SelectedItem().(sfnStateMachineItem))
+}
+
+// resume reloads the current list if leaving another screen cut its load
+// short.
+func (m sfnModel) resume() (sfnModel, tea.Cmd) {
+	if m.state == sfnStateList && m.stateMachineLoader.interrupted || m.state == sfnStateExecutions && m.executionLoader.interrupted {
+		return m.autoRefresh()
+	}
+	return m, nil
+}
+
+// autoRefresh reloads the current list in the background, unless the user
+// is busy with it.
+func (m sfnModel) autoRefresh() (sfnModel, tea.Cmd) {
+	switch {
+	case m.state == sfnStateList && !m.stateMachineLoader.loading():
+		return m, m.stateMachineLoader.reload(commands.FetchSFNStateMachinesCmd(m.scope.context(), m.pool.Regions(m.regions)))
+	case m.state == sfnStateExecutions && !m.executionLoader.loading():
+		return m, m.executionLoader.reload(commands.FetchSFNExecutionsCmd(m.scope.context(), m.regionClients.SFN, m.selectedStateMachine.StateMachineArn))
+	case m.state == sfnStateExecutionDetails:
+		return m, commands.FetchSFNExecutionHistoryCmd(m.scope.context(), m.regionClients.SFN, m.selectedExecution.ExecutionArn)
+	}
+	return m, nil
+}
+
 func (m sfnModel) View() string {
 	var s string
 	switch m.state {
 	case sfnStateList:
 		if len(m.sfnList.Items()) == 0 && m.status == "Ready" {
-			s = styles.StatusStyle.Render("No Step Functions state machines found in this region.\n")
+			s = styles.StatusStyle.Render("No Step Functions state machines found in the selected regions.\n")
 		} else {
 			s = m.sfnList.View()
 		}
@@ -233,10 +360,15 @@ func (m sfnModel) View() string {
 
 type sfnExecutionHistoryItem struct {
 	event *sfnHistoryState
+	// link leads to the job or task the event reports on, if any.
+	link *link
 }
 
 func (i sfnExecutionHistoryItem) FilterValue() string {
-	return aws.StringValue(i.event.Step) + " " + aws.StringValue(i.event.Type)
+	return fields(
+		field{"step", aws.StringValue(i.event.Step)},
+		field{"type", aws.StringValue(i.event.Type)},
+	)
 }
 
 func (i sfnExecutionHistoryItem) Title() string {
@@ -244,7 +376,11 @@ func (i sfnExecutionHistoryItem) Title() string {
 }
 
 func (i sfnExecutionHistoryItem) Description() string {
-	return fmt.Sprintf("ID: %d | Type: %s | Timestamp %s", aws.Int64Value(i.event.ID), aws.StringValue(i.event.Type), i.event.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	s := fmt.Sprintf("ID: %d | Type: %s | Timestamp %s", aws.Int64Value(i.event.ID), aws.StringValue(i.event.Type), i.event.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	if i.link != nil {
+		s += " | Go to: " + i.link.String()
+	}
+	return s
 }
 
 type sfnHistoryState struct {
@@ -0,0 +1,321 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/netip"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/keys"
+	"github.com/theoreticallyjosh/awstui/internal/styles"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// column is a column of a table view. Rows are sorted by compare, or by
+// their cells if it is not set.
+type column struct {
+	title   string
+	width   int
+	value   func(list.Item) string
+	compare func(a, b list.Item) int
+}
+
+// cell adapts a function of an item of type T to the items of a list.
+func cell[T list.Item](f func(T) string) func(list.Item) string {
+	return func(i list.Item) string { return f(i.(T)) }
+}
+
+// by compares the items of a list, all of type T, by f.
+func by[T list.Item, V cmp.Ordered](f func(T) V) func(a, b list.Item) int {
+	return func(a, b list.Item) int { return cmp.Compare(f(a.(T)), f(b.(T))) }
+}
+
+// byIP compares the items of a list, all of type T, by the IP address f
+// returns. Items without one come first.
+func byIP[T list.Item](f func(T) string) func(a, b list.Item) int {
+	return func(a, b list.Item) int {
+		x, _ := netip.ParseAddr(f(a.(T)))
+		y, _ := netip.ParseAddr(f(b.(T)))
+		return x.Compare(y)
+	}
+}
+
+// formatTime formats the time of a cell, leaving it empty if t is not set.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// millis converts the epoch milliseconds Batch reports times in.
+func millis(ms *int64) time.Time {
+	if ms == nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(*ms)
+}
+
+// jobDuration is how long a job ran, or has been running so far.
+func jobDuration(i batchJobItem) time.Duration {
+	started, stopped := millis(i.job.StartedAt), millis(i.job.StoppedAt)
+	switch {
+	case started.IsZero():
+		return 0
+	case stopped.IsZero():
+		return time.Since(started).Round(time.Second)
+	}
+	return stopped.Sub(started).Round(time.Second)
+}
+
+// tableColumns holds the columns of each view, by the names config.yml lists
+// them by; see config.TableColumns.
+var tableColumns = map[string]map[string]column{
+	"ec2": {
+		"name": {title: "NAME", width: 28, value: cell(func(i ec2InstanceItem) string { return getInstanceName(i.instance) })},
+		"id":   {title: "ID", width: 19, value: cell(func(i ec2InstanceItem) string { return aws.StringValue(i.instance.InstanceId) })},
+		"state": {title: "STATE", width: 13, value: cell(func(i ec2InstanceItem) string {
+			if i.instance.State == nil {
+				return ""
+			}
+			return aws.StringValue(i.instance.State.Name)
+		})},
+		"type": {title: "TYPE", width: 12, value: cell(func(i ec2InstanceItem) string { return aws.StringValue(i.instance.InstanceType) })},
+		"az": {title: "AZ", width: 15, value: cell(func(i ec2InstanceItem) string {
+			if i.instance.Placement == nil {
+				return ""
+			}
+			return aws.StringValue(i.instance.Placement.AvailabilityZone)
+		})},
+		"private_ip": {title: "PRIVATE IP", width: 15,
+			value:   cell(func(i ec2InstanceItem) string { return aws.StringValue(i.instance.PrivateIpAddress) }),
+			compare: byIP(func(i ec2InstanceItem) string { return aws.StringValue(i.instance.PrivateIpAddress) }),
+		},
+		"launch_time": {title: "LAUNCHED", width: 16,
+			value:   cell(func(i ec2InstanceItem) string { return formatTime(aws.TimeValue(i.instance.LaunchTime)) }),
+			compare: by(func(i ec2InstanceItem) int64 { return aws.TimeValue(i.instance.LaunchTime).Unix() }),
+		},
+		"public_ip": {title: "PUBLIC IP", width: 15,
+			value:   cell(func(i ec2InstanceItem) string { return aws.StringValue(i.instance.PublicIpAddress) }),
+			compare: byIP(func(i ec2InstanceItem) string { return aws.StringValue(i.instance.PublicIpAddress) }),
+		},
+		"region": {title: "REGION", width: 14, value: cell(func(i ec2InstanceItem) string { return i.region })},
+	},
+	"ecs": {
+		"name": {title: "NAME", width: 32, value: cell(func(i ecsServiceItem) string { return aws.StringValue(i.service.ServiceName) })},
+		"desired": {title: "DESIRED", width: 9,
+			value:   cell(func(i ecsServiceItem) string { return fmt.Sprint(aws.Int64Value(i.service.DesiredCount)) }),
+			compare: by(func(i ecsServiceItem) int64 { return aws.Int64Value(i.service.DesiredCount) }),
+		},
+		"running": {title: "RUNNING", width: 9,
+			value:   cell(func(i ecsServiceItem) string { return fmt.Sprint(aws.Int64Value(i.service.RunningCount)) }),
+			compare: by(func(i ecsServiceItem) int64 { return aws.Int64Value(i.service.RunningCount) }),
+		},
+		"pending": {title: "PENDING", width: 9,
+			value:   cell(func(i ecsServiceItem) string { return fmt.Sprint(aws.Int64Value(i.service.PendingCount)) }),
+			compare: by(func(i ecsServiceItem) int64 { return aws.Int64Value(i.service.PendingCount) }),
+		},
+		"task_definition": {title: "TASK DEFINITION", width: 28, value: cell(func(i ecsServiceItem) string {
+			if i.service.TaskDefinition == nil {
+				return ""
+			}
+			return path.Base(aws.StringValue(i.service.TaskDefinition))
+		})},
+		"status":      {title: "STATUS", width: 8, value: cell(func(i ecsServiceItem) string { return aws.StringValue(i.service.Status) })},
+		"launch_type": {title: "LAUNCH TYPE", width: 11, value: cell(func(i ecsServiceItem) string { return aws.StringValue(i.service.LaunchType) })},
+		"created": {title: "CREATED", width: 16,
+			value:   cell(func(i ecsServiceItem) string { return formatTime(aws.TimeValue(i.service.CreatedAt)) }),
+			compare: by(func(i ecsServiceItem) int64 { return aws.TimeValue(i.service.CreatedAt).Unix() }),
+		},
+	},
+	"batch": {
+		"name":   {title: "NAME", width: 32, value: cell(func(i batchJobItem) string { return aws.StringValue(i.job.JobName) })},
+		"status": {title: "STATUS", width: 9, value: cell(func(i batchJobItem) string { return aws.StringValue(i.job.Status) })},
+		"created": {title: "CREATED", width: 16,
+			value:   cell(func(i batchJobItem) string { return formatTime(millis(i.job.CreatedAt)) }),
+			compare: by(func(i batchJobItem) int64 { return aws.Int64Value(i.job.CreatedAt) }),
+		},
+		"duration": {title: "DURATION", width: 10,
+			value: cell(func(i batchJobItem) string {
+				if d := jobDuration(i); d > 0 {
+					return d.String()
+				}
+				return ""
+			}),
+			compare: by(func(i batchJobItem) time.Duration { return jobDuration(i) }),
+		},
+		"id": {title: "ID", width: 36, value: cell(func(i batchJobItem) string { return aws.StringValue(i.job.JobId) })},
+		"started": {title: "STARTED", width: 16,
+			value:   cell(func(i batchJobItem) string { return formatTime(millis(i.job.StartedAt)) }),
+			compare: by(func(i batchJobItem) int64 { return aws.Int64Value(i.job.StartedAt) }),
+		},
+		"stopped": {title: "STOPPED", width: 16,
+			value:   cell(func(i batchJobItem) string { return formatTime(millis(i.job.StoppedAt)) }),
+			compare: by(func(i batchJobItem) int64 { return aws.Int64Value(i.job.StoppedAt) }),
+		},
+	},
+}
+
+// table is the table view of a list: the columns configured for it, whether
+// it is shown instead of the usual two-line items, and the column the rows
+// are sorted by. Like the selection it is shared with the list's delegate.
+type table struct {
+	columns []column
+	marks   *selection
+	stars   *stars
+	shown   bool
+	sortBy  int
+	desc    bool
+}
+
+// newTable returns the table view of view as configured in conf. marks are
+// the marks of the list and stars its favorites.
+func newTable(view string, conf *config.Config, marks *selection, stars *stars) *table {
+	if conf == nil {
+		conf = &config.Config{}
+	}
+	t := &table{marks: marks, stars: stars, shown: conf.Tables[view].Show, sortBy: -1}
+	for _, name := range conf.Columns(view) {
+		t.columns = append(t.columns, tableColumns[view][name])
+	}
+	return t
+}
+
+// apply renders l as the table, if it is shown, or as the usual list.
+func (t *table) apply(l *list.Model) {
+	if !t.shown {
+		l.SetDelegate(ItemDelegate{marks: t.marks, stars: t.stars})
+		l.SetShowTitle(false)
+		return
+	}
+	l.SetDelegate(tableDelegate{table: t})
+	// Line the titles up with the cells, which follow the left edge of the
+	// item style and the mark.
+	l.Title = " " + t.header()
+	l.SetShowTitle(true)
+}
+
+// update handles the table keys: table toggles the table view of l and sort
+// sorts its rows by the column of the key pressed, in reverse if they are
+// sorted by it already. It reports whether msg was one of those keys.
+func (t *table) update(msg tea.KeyMsg, k *keys.ListKeyMap, l *list.Model) bool {
+	switch {
+	case key.Matches(msg, k.Table):
+		t.shown = !t.shown
+		t.apply(l)
+	case key.Matches(msg, k.Sort) && t.shown:
+		i := slices.Index(k.Sort.Keys(), msg.String())
+		if i < 0 || i >= len(t.columns) {
+			return true
+		}
+		t.desc = t.sortBy == i && !t.desc
+		t.sortBy = i
+		t.sort(l)
+		t.apply(l)
+	default:
+		return false
+	}
+	return true
+}
+
+// sort orders the items of l by the sort column while the table is shown.
+// Equal rows keep their order.
+func (t *table) sort(l *list.Model) {
+	if !t.shown || t.sortBy < 0 {
+		return
+	}
+	c := t.columns[t.sortBy]
+	compare := c.compare
+	if compare == nil {
+		compare = func(a, b list.Item) int {
+			return strings.Compare(strings.ToLower(c.value(a)), strings.ToLower(c.value(b)))
+		}
+	}
+	items := slices.Clone(l.Items())
+	slices.SortStableFunc(items, func(a, b list.Item) int {
+		if t.desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	setItems(l, items)
+}
+
+// header is the row of column titles, the sort column marked with its
+// direction.
+func (t *table) header() string {
+	titles := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		title := c.title
+		if i == t.sortBy {
+			if r := []rune(title); len(r) > c.width-2 {
+				title = string(r[:c.width-2])
+			}
+			if t.desc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		titles[i] = title
+	}
+	return t.row(titles)
+}
+
+// row lays out cells in the widths of the columns.
+func (t *table) row(cells []string) string {
+	var b strings.Builder
+	for i, c := range t.columns {
+		s := cells[i]
+		if r := []rune(s); len(r) > c.width {
+			s = string(r[:c.width-1]) + "…"
+		}
+		if i < len(t.columns)-1 {
+			s = fmt.Sprintf("%-*s  ", c.width, s)
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// tableDelegate renders an item as a row of its table, with a mark in front
+// of it if it is marked, or else a star if it is a favorite.
+type tableDelegate struct {
+	table *table
+}
+
+func (d tableDelegate) Height() int                               { return 1 }
+func (d tableDelegate) Spacing() int                              { return 0 }
+func (d tableDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+func (d tableDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	cells := make([]string, len(d.table.columns))
+	for i, c := range d.table.columns {
+		cells[i] = strings.Join(strings.Fields(c.value(listItem)), " ")
+	}
+	row := d.table.row(cells)
+	// Leave room for the item style's left edge and the mark.
+	if r := []rune(row); m.Width() > 4 && len(r) > m.Width()-3 {
+		row = string(r[:m.Width()-4]) + "…"
+	}
+
+	mark := "  "
+	if d.table.marks.has(listItem) {
+		mark = styles.MarkedStyle.Render("● ")
+	} else if d.table.stars.has(listItem) {
+		mark = styles.MarkedStyle.Render("★ ")
+	}
+	if index == m.Index() {
+		fmt.Fprint(w, styles.SelectedItemStyle.Render(mark+row))
+	} else {
+		fmt.Fprint(w, styles.UnselectedItemStyle.Render(mark+row))
+	}
+}
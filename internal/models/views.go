@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/theoreticallyjosh/awstui/internal/clients"
+	"github.com/theoreticallyjosh/awstui/internal/config"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Saved view item of the main menu
+type viewItem struct {
+	view config.View
+}
+
+func (i viewItem) Title() string {
+	return i.view.Name
+}
+
+func (i viewItem) Description() string {
+	target := i.view.Service
+	if i.view.Parent != "" {
+		target += " " + i.view.Parent
+	}
+	parts := []string{"View: " + target}
+	if i.view.Filter != "" {
+		parts = append(parts, "Filter: "+i.view.Filter)
+	}
+	if i.view.Profile != "" {
+		parts = append(parts, "Profile: "+i.view.Profile)
+	}
+	if i.view.Region != "" {
+		parts = append(parts, "Region: "+i.view.Region)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (i viewItem) FilterValue() string {
+	return i.view.Name
+}
+
+// openView opens a saved view. It switches to the view's profile and region
+// if they differ from the current ones, heads for its service and parent
+// like the command palette does, and filters the list it lands on, which
+// keeps the filter as the list loads.
+func (m Model) openView(v config.View) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	m.prevState = stateMenu
+	if v.Profile != "" && v.Profile != clients.DisplayProfile(m.pool.Profile) {
+		var cmd tea.Cmd
+		m, cmd = m.switchProfile(v.Profile)
+		if m.err != nil {
+			return m, cmd
+		}
+		cmds = append(cmds, cmd)
+	}
+	if v.Region != "" && !m.querying(v.Region) {
+		m, _ = m.switchRegion(v.Region)
+	}
+
+	line := v.Service
+	if v.Parent != "" {
+		line += " " + v.Parent
+	}
+	m, cmd := m.runCommand(line)
+	if l := m.viewList(v); l != nil && v.Filter != "" {
+		l.SetFilterText(v.Filter)
+	}
+	return m, tea.Batch(append(cmds, cmd)...)
+}
+
+// viewList returns the list a saved view lands on.
+func (m *Model) viewList(v config.View) *list.Model {
+	parent := v.Parent != ""
+	switch v.Service {
+	case "ec2":
+		return &m.ec2Model.instanceList
+	case "ecs":
+		if parent {
+			return &m.ecsModel.serviceList
+		}
+		return &m.ecsModel.clusterList
+	case "ecr":
+		if parent {
+			return &m.ecrModel.imageList
+		}
+		return &m.ecrModel.repositoryList
+	case "sfn":
+		if parent {
+			return &m.sfnModel.executionList
+		}
+		return &m.sfnModel.sfnList
+	case "batch":
+		if parent {
+			return &m.batchModel.jobList
+		}
+		return &m.batchModel.jobQueueList
+	}
+	return nil
+}
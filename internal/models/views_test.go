@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/theoreticallyjosh/awstui/internal/config"
+)
+
+func TestQuerying(t *testing.T) {
+	tm := newTestModel(t, nil)
+	if !tm.m.querying("us-east-1") || tm.m.querying("eu-west-1") || tm.m.querying(allRegions) {
+		t.Fatal("default region not taken for the one queried")
+	}
+	tm.command("region all")
+	if !tm.m.querying(allRegions) || tm.m.querying("us-east-1") {
+		t.Fatalf("querying %q, want all regions", tm.m.regions)
+	}
+}
+
+func TestOpenViewSwitchesRegion(t *testing.T) {
+	tm := newTestModel(t, nil)
+	open := func(v config.View) {
+		m, cmd := tm.m.openView(v)
+		tm.m = m
+		tm.run(cmd)
+	}
+
+	open(config.View{Name: "eu", Service: "ec2", Region: "eu-west-1"})
+	tm.wantView("i-eu0000000000001")
+	tm.wantNoView("i-us0000000000001")
+
+	open(config.View{Name: "everywhere", Service: "ec2", Region: allRegions})
+	if !tm.m.querying(allRegions) {
+		t.Fatalf("querying %q, want all regions", tm.m.regions)
+	}
+	tm.wantView("i-eu0000000000001", "i-us0000000000001")
+}
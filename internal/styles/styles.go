@@ -13,8 +13,13 @@ var (
 	HeaderBarStyle,
 	HeaderStyle,
 	SubHeaderStyle,
+	ProfileStyle,
 	ErrorStyle,
+	SuccessStyle,
+	FailureStyle,
+	MarkedStyle,
 	ConfirmStyle,
+	DialogStyle,
 	DetailStyle,
 	TitleStyle,
 	DescriptionStyle,
@@ -46,16 +51,38 @@ func LoadStyle() {
 		Background(Theme.Bg()).
 		Bold(true)
 
+	ProfileStyle = lipgloss.NewStyle().
+		Foreground(Theme.Yellow()).
+		Background(Theme.BrightBlack()).
+		Bold(true)
+
 	ErrorStyle = lipgloss.NewStyle().
 		Foreground(Theme.Red()).
 		Bold(true).
 		PaddingTop(1)
 
+	SuccessStyle = lipgloss.NewStyle().
+		Foreground(Theme.Green()).
+		Bold(true)
+
+	FailureStyle = lipgloss.NewStyle().
+		Foreground(Theme.Red()).
+		Bold(true)
+
+	MarkedStyle = lipgloss.NewStyle().
+		Foreground(Theme.Yellow()).
+		Bold(true)
+
 	ConfirmStyle = lipgloss.NewStyle().
 		Foreground(Theme.Green()).
 		Bold(true).
 		PaddingTop(1)
 
+	DialogStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder(), true).
+		BorderForeground(Theme.Yellow()).
+		Padding(1, 3)
+
 	DetailStyle = lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), true).
 		BorderForeground(Theme.Blue()).
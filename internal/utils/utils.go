@@ -29,6 +29,15 @@ func GetSecurityGroupNames(sgs []*ec2.SecurityGroupIdentifier) string {
 	return strings.Join(names, ", ")
 }
 
+// ImageName names an image of a repository for docker, by tag or, for a
+// digest such as sha256:…, by digest.
+func ImageName(repositoryUri, ref string) string {
+	if strings.Contains(ref, ":") {
+		return repositoryUri + "@" + ref
+	}
+	return repositoryUri + ":" + ref
+}
+
 func ArrayToCSV(array []*string) string {
 	ret := ""
 	for i, v := range array {
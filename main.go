@@ -1,9 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
 
+	"github.com/theoreticallyjosh/awstui/internal/apilog"
+	"github.com/theoreticallyjosh/awstui/internal/audit"
+	"github.com/theoreticallyjosh/awstui/internal/cli"
+	"github.com/theoreticallyjosh/awstui/internal/clients"
 	"github.com/theoreticallyjosh/awstui/internal/config"
+	"github.com/theoreticallyjosh/awstui/internal/fake"
+	"github.com/theoreticallyjosh/awstui/internal/favorites"
 	"github.com/theoreticallyjosh/awstui/internal/models"
 	"github.com/theoreticallyjosh/awstui/internal/styles"
 
@@ -12,16 +26,92 @@ import (
 )
 
 func main() {
+	demo := flag.Bool("demo", false, "run against an in-memory fake AWS account")
+	readOnly := flag.Bool("read-only", false, "disable every action that changes AWS resources")
+	debug := flag.Bool("debug", false, "write a structured debug log to "+config.DebugLogPath())
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), cli.Usage+"\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *debug {
+		f, err := openDebugLog(config.DebugLogPath())
+		if err != nil {
+			log.Fatalf("Failed to open debug log: %v", err)
+		}
+		defer f.Close()
+	}
 
 	conf := config.LoadConfig()
+	if *readOnly {
+		conf.ReadOnly = true
+	}
 	tint.NewDefaultRegistry()
 	styles.Theme, _ = tint.GetTint(conf.Theme)
 	styles.LoadStyle()
+
+	var pool *clients.Pool
+	auditLog := audit.Open(conf.AuditLog)
+	calls := apilog.NewRecorder(apilog.DefaultLimit)
+	favs := favorites.Open(config.FavoritesPath())
+	if *demo {
+		pool = fake.NewDemoBackend().Pool("demo", "us-east-1")
+		// Keep the demo's actions out of the real audit log and its
+		// resources out of the real favorites.
+		auditLog = audit.Open("")
+		favs = favorites.Open("")
+	} else {
+		var err error
+		pool, err = clients.NewPool(clients.Options{
+			Profile:            conf.Profile,
+			Region:             conf.Region,
+			Endpoint:           conf.Endpoint,
+			Endpoints:          conf.Endpoints,
+			InsecureSkipVerify: conf.InsecureSkipVerify,
+			CABundle:           conf.CABundle,
+			Recorder:           calls,
+		})
+		if err != nil {
+			log.Fatalf("Failed to create AWS session: %v", err)
+		}
+	}
+
+	if flag.NArg() > 0 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := cli.Run(ctx, pool, conf, auditLog, flag.Args(), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "awstui: %v\n", err)
+			if errors.Is(err, cli.ErrUsage) {
+				os.Exit(2)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	tea.ClearScreen()
-	m := models.NewModel()
+	m := models.NewModel(pool, conf, auditLog, calls, favs)
 	// Start the Bubble Tea program
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("Alas, there's been an error: %v", err)
 	}
 }
+
+// openDebugLog sends the structured log to the file at path, at debug level.
+// The log package keeps writing to standard error.
+func openDebugLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+	slog.Info("starting awstui", "args", os.Args[1:])
+	return f, nil
+}